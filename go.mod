@@ -9,7 +9,7 @@ require (
 	github.com/julienschmidt/httprouter v1.1.1-0.20150708215400-6aacfd5ab513
 	github.com/mattn/go-colorable v0.1.4 // indirect
 	github.com/mattn/go-isatty v0.0.11 // indirect
-	github.com/mattn/go-runewidth v0.0.5-0.20181218000649-703b5e6b11ae // indirect
+	github.com/mattn/go-runewidth v0.0.5-0.20181218000649-703b5e6b11ae
 	github.com/mitchellh/go-wordwrap v1.0.0 // indirect
 	github.com/nsf/termbox-go v0.0.0-20190104133558-0938b5187e61
 	github.com/skratchdot/open-golang v0.0.0-20200116055534-eef842397966
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -13,26 +14,65 @@ const (
 )
 
 func main() {
-	if len(os.Args) <= 1 {
-		os.Args = append(os.Args, "l")
+	//rc.key=value args override the config
+	args, overrides := todolist.SplitOverrides(os.Args[1:])
+	//--repo=<name or dir> picks the repo, as TODO_REPO does
+	args, todolist.RepoOption = todolist.SplitRepoOption(args)
+	if len(args) == 0 {
+		args = append(args, "l")
 	}
 
-	input := strings.Join(os.Args[1:], " ")
-	app := todolist.NewApp()
+	input := strings.Join(args, " ")
+	app, err := todolist.NewApp(overrides...)
+	//A bad .todorc stops every command but config, which can check and fix it
+	if err != nil && (app == nil || args[0] != "config") {
+		exit(err)
+	}
 	command := app.ProcessCmdLine(input)
 
 	//Protect against mass edit or delete
-	cmd := command.GetCmd()
-	if (cmd == "edit" || cmd == "archive" || cmd == "delete" || cmd == "complete") && len(command.GetFilters()) == 0 {
-		fmt.Println("Destructive operations require a filter. None specified. Aborting.")
-		return
+	if todolist.IsMissingRequiredFilter(command) {
+		exit(todolist.ErrMissingFilter)
 	}
 	//Apply the view (set of filters applied by default)
-	if len(app.Cfg.CurrentView) > 0 {
-		viewFilters := app.Cfg.Views[app.Cfg.CurrentView]
-		command.SetFilters(append(viewFilters, command.GetFilters()...))
+	app.ApplyView(command)
+
+	err = command.Exec(app)
+	if hookErr := app.RunExitHooks(); err == nil {
+		err = hookErr
 	}
+	exit(err)
+}
 
-	command.Exec(app)
+//Print the error, if any, and exit with the code for its kind. A plugin's exit code is passed on as is.
+func exit(err error) {
+	var pluginErr *todolist.PluginExitError
+	if errors.As(err, &pluginErr) {
+		os.Exit(pluginErr.Code)
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	os.Exit(exitCode(err))
+}
 
+//Exit codes, so scripts can tell a usage error from a missing repo or a wrong passphrase
+func exitCode(err error) int {
+	switch {
+	case err == nil, errors.Is(err, todolist.ErrRepoExists):
+		return 0
+	case errors.Is(err, todolist.ErrBadInput), errors.Is(err, todolist.ErrBadDate), errors.Is(err, todolist.ErrMissingFilter):
+		return 2
+	case errors.Is(err, todolist.ErrRepoNotFound):
+		return 3
+	case errors.Is(err, todolist.ErrBadConfig):
+		return 4
+	case errors.Is(err, todolist.ErrWrongPassphrase):
+		return 5
+	case errors.Is(err, todolist.ErrHookRejected):
+		return 6
+	case errors.Is(err, todolist.ErrNotFound):
+		return 7
+	}
+	return 1
 }
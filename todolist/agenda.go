@@ -0,0 +1,97 @@
+package todolist
+
+import (
+	"sort"
+	"time"
+)
+
+//Events that put a todo on the agenda for a day, in the order they are listed
+const (
+	AgendaDue       = "due"
+	AgendaScheduled = "scheduled"
+	AgendaWaitEnds  = "wait ends"
+	AgendaExpires   = "expires"
+	AgendaDone      = "done"
+)
+
+var agendaEvents = []string{AgendaDue, AgendaScheduled, AgendaWaitEnds, AgendaExpires, AgendaDone}
+
+func agendaEventRank(event string) int {
+	for i, e := range agendaEvents {
+		if e == event {
+			return i
+		}
+	}
+	return len(agendaEvents)
+}
+
+//A todo on the agenda and why it is there
+type AgendaItem struct {
+	Todo  *Todo
+	Event string
+}
+
+//Day by day plan of the todos due, scheduled, waiting until, expiring and completed each day in a range of days, with
+//overdue todos carried forward to the top
+type Agenda struct {
+	Begin   time.Time               //First day
+	End     time.Time               //Last day
+	Overdue []*Todo                 //Pending todos due before the first day (or today, if earlier)
+	Items   map[string][]AgendaItem //Items for each day, keyed by yyyy-mm-dd
+	Now     time.Time               //Today is marked
+}
+
+func NewAgenda(todos []*Todo, begin, end time.Time, clock Clock) *Agenda {
+	begin, end = bod(begin), bod(end)
+	agenda := &Agenda{Begin: begin, End: end, Overdue: []*Todo{}, Items: map[string][]AgendaItem{}}
+	df := NewDateFilter(todos, clock)
+	agenda.Now = df.Now
+	pivot := begin
+	if bod(df.Now).Before(pivot) {
+		pivot = bod(df.Now)
+	}
+	for _, todo := range df.filterOverdue(pivot) {
+		if !todo.Completed {
+			agenda.Overdue = append(agenda.Overdue, todo)
+		}
+	}
+	sort.Slice(agenda.Overdue, func(i, j int) bool { return filterOnDue(agenda.Overdue[i]).Before(filterOnDue(agenda.Overdue[j])) })
+
+	last := end.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	agenda.add(df.filterBetweenDatesInclusive(begin, last, filterOnDue), AgendaDue, filterOnDue, false)
+	agenda.add(df.filterBetweenDatesInclusive(begin, last, filterOnScheduled), AgendaScheduled, filterOnScheduled, false)
+	agenda.add(df.filterBetweenDatesInclusive(begin, last, filterOnWait), AgendaWaitEnds, filterOnWait, false)
+	agenda.add(df.filterBetweenDatesInclusive(begin, last, filterOnUntil), AgendaExpires, filterOnUntil, false)
+	agenda.add(df.filterBetweenDatesInclusive(begin, last, filterOnCompletedDate), AgendaDone, filterOnCompletedDate, true)
+	for _, items := range agenda.Items {
+		sort.Slice(items, func(i, j int) bool {
+			ri, rj := agendaEventRank(items[i].Event), agendaEventRank(items[j].Event)
+			return ri < rj || (ri == rj && items[i].Todo.Id < items[j].Todo.Id)
+		})
+	}
+	return agenda
+}
+
+//Add the todos to the day of their event. Only completed todos are done, and only pending todos have other events.
+func (a *Agenda) add(todos []*Todo, event string, dateOf func(*Todo) time.Time, completed bool) {
+	for _, todo := range todos {
+		if todo.Completed != completed {
+			continue
+		}
+		key := calendarDayKey(dateOf(todo))
+		a.Items[key] = append(a.Items[key], AgendaItem{Todo: todo, Event: event})
+	}
+}
+
+//Each day from the first to the last
+func (a *Agenda) Days() []time.Time {
+	days := []time.Time{}
+	for day := a.Begin; !day.After(a.End); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+	return days
+}
+
+func (a *Agenda) ItemsOn(day time.Time) []AgendaItem {
+	return a.Items[calendarDayKey(day)]
+}
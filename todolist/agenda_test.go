@@ -0,0 +1,31 @@
+package todolist
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAgenda(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+	clock := FixedClock{Time: time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)}
+	now := clock.Now()
+	todos := []*Todo{
+		&Todo{Id: 1, Subject: "Late", Due: "2026-10-12T00:00:00Z", Status: "Pending"},
+		&Todo{Id: 2, Subject: "Today", Due: "2026-10-14T00:00:00Z", Status: "Pending"},
+		&Todo{Id: 3, Subject: "Waiting", Wait: "2026-10-15T00:00:00Z", Status: "Pending"},
+		&Todo{Id: 4, Subject: "Expiring", Until: "2026-10-15T00:00:00Z", Due: "2026-10-15T00:00:00Z", Status: "Pending"},
+		&Todo{Id: 5, Subject: "Finished", Due: "2026-10-10T00:00:00Z", Completed: true, CompletedDate: "2026-10-14T09:00:00Z", Status: "Pending"},
+	}
+	agenda := NewAgenda(todos, now, now.AddDate(0, 0, 1), clock)
+	assert.Equal(2, len(agenda.Days()))
+	assert.Equal([]*Todo{todos[0]}, agenda.Overdue)
+	assert.Equal([]AgendaItem{{todos[1], AgendaDue}, {todos[4], AgendaDone}}, agenda.ItemsOn(now))
+	assert.Equal([]AgendaItem{{todos[3], AgendaDue}, {todos[2], AgendaWaitEnds}, {todos[3], AgendaExpires}}, agenda.ItemsOn(now.AddDate(0, 0, 1)))
+
+	//Starting tomorrow, todos due today are not yet overdue
+	agenda = NewAgenda(todos, now.AddDate(0, 0, 1), now.AddDate(0, 0, 1), clock)
+	assert.Equal([]*Todo{todos[0]}, agenda.Overdue)
+}
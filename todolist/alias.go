@@ -0,0 +1,99 @@
+package todolist
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+//An alias (alias.<name>=<command line> in .todorc). Cmd is the command line it expands to.
+type AliasCmd struct {
+	CommandImpl
+	Name string
+}
+
+func (c *AliasCmd) Exec(a *App) error {
+	return a.ExecAlias(c)
+}
+
+//$1..$n, ${n}, ${n:-default}, $@ and ${@} in an alias definition
+var aliasParamRegex = regexp.MustCompile(`\$(?:(\d+)|@|\{(?:(\d+)(?::-([^}]*))?|@)\})`)
+
+//Substitute the params (the words after the alias) for the placeholders in the alias definition. Returns the params
+//not used, which are added to the command as before. A missing param with no default is ErrBadInput, or left as
+//is if keep.
+func substituteAliasParams(name string, def string, params []string, keep bool) (string, []string, error) {
+	used := 0
+	var err error
+	expanded := aliasParamRegex.ReplaceAllStringFunc(def, func(match string) string {
+		groups := aliasParamRegex.FindStringSubmatch(match)
+		if groups[1] == "" && groups[2] == "" { //$@
+			if keep && len(params) == 0 {
+				return match
+			}
+			used = len(params)
+			return strings.Join(params, " ")
+		}
+		n, _ := strconv.Atoi(groups[1] + groups[2])
+		if n > used && n <= len(params) {
+			used = n
+		}
+		if n >= 1 && n <= len(params) {
+			return params[n-1]
+		}
+		if strings.Contains(match, ":-") {
+			return groups[3]
+		}
+		if !keep && err == nil {
+			err = newError(ErrBadInput, "Alias %s needs argument %s", name, match)
+		}
+		return match
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return expanded, params[used:], nil
+}
+
+//The command line an alias expands to, with the aliases it uses expanded in turn and the params it is given.
+//Missing params are shown as placeholders. Returns ErrBadConfig if the aliases loop.
+func (a *App) AliasExpansion(name string, params []string) (string, error) {
+	return a.aliasExpansion(name, params, []string{})
+}
+
+func (a *App) aliasExpansion(name string, params []string, stack []string) (string, error) {
+	stack = append(stack, name)
+	if err := aliasLoop(stack); err != nil {
+		return "", err
+	}
+	alias, ok := a.CommandMap[name].(*AliasCmd)
+	if !ok {
+		return name, nil
+	}
+	expanded, rest, _ := substituteAliasParams(name, alias.Cmd, params, true)
+	parts := strings.Split(expanded, " ")
+	for i, part := range parts {
+		if _, isAlias := a.CommandMap[part].(*AliasCmd); isAlias {
+			inner, err := a.aliasExpansion(part, parts[i+1:], stack)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts[:i], inner)
+			break
+		} else if a.CommandMap[part] != nil {
+			break
+		}
+	}
+	return strings.Join(append(parts, rest...), " "), nil
+}
+
+//ErrBadConfig if the last alias in the stack is already being expanded
+func aliasLoop(stack []string) error {
+	name := stack[len(stack)-1]
+	for _, outer := range stack[:len(stack)-1] {
+		if outer == name {
+			return newError(ErrBadConfig, "Alias loop: %s", strings.Join(stack, " -> "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package todolist
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAliasParams(t *testing.T) {
+	assert := assert.New(t)
+	expanded, rest, err := substituteAliasParams("mine", "+$1 @me due:${2:-eow} list", []string{"Work"}, false)
+	assert.Nil(err)
+	assert.Equal("+Work @me due:eow list", expanded)
+	assert.Empty(rest)
+
+	expanded, rest, err = substituteAliasParams("mine", "+$1 @me due:${2:-eow} list", []string{"Work", "fri", "+urgent"}, false)
+	assert.Nil(err)
+	assert.Equal("+Work @me due:fri list", expanded)
+	assert.Equal([]string{"+urgent"}, rest)
+
+	expanded, rest, err = substituteAliasParams("todo", "a $@ +${1}", []string{"Call", "Bob"}, false)
+	assert.Nil(err)
+	assert.Equal("a Call Bob +Call", expanded)
+	assert.Empty(rest)
+
+	//No placeholders adds the words to the command, as before
+	expanded, rest, _ = substituteAliasParams("top", "list top:2", []string{"+Work"}, false)
+	assert.Equal("list top:2", expanded)
+	assert.Equal([]string{"+Work"}, rest)
+
+	_, _, err = substituteAliasParams("mine", "+$1 list", []string{}, false)
+	assert.True(errors.Is(err, ErrBadInput))
+	expanded, _, err = substituteAliasParams("mine", "+$1 list", []string{}, true)
+	assert.Nil(err)
+	assert.Equal("+$1 list", expanded)
+}
+
+func TestAliases(t *testing.T) {
+	assert := assert.New(t)
+	app := &App{Cfg: &Config{}, CommandMap: map[string]Command{}}
+	var ran *CommandImpl
+	app.CommandMap["rec"] = NewCommand("rec", true, false, func(c *CommandImpl) error {
+		ran = c
+		return nil
+	})
+	app.AddAliasCommand("mine", "+$1 @me rec due:${2:-eow}")
+	app.AddAliasCommand("wk", "mine Work")
+	app.AddAliasCommand("loop", "again")
+	app.AddAliasCommand("again", "+x loop")
+
+	expansion, err := app.AliasExpansion("wk", nil)
+	assert.Nil(err)
+	assert.Equal("+Work @me rec due:eow", expansion)
+	expansion, _ = app.AliasExpansion("mine", nil)
+	assert.Equal("+$1 @me rec due:eow", expansion)
+	_, err = app.AliasExpansion("loop", nil)
+	assert.True(errors.Is(err, ErrBadConfig))
+	assert.Equal("Alias loop: loop -> again -> loop", err.Error())
+
+	command := app.ProcessCmdLine("@home wk fri")
+	assert.Nil(command.Exec(app))
+	assert.Equal([]string{"+Work", "@me", "@home"}, ran.Filters)
+	assert.Equal([]string{"due:fri"}, ran.Mods)
+
+	assert.True(errors.Is(app.ProcessCmdLine("loop").Exec(app), ErrBadConfig))
+	assert.Empty(app.aliases)
+}
@@ -1,52 +1,61 @@
 package todolist
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
-	"log"
+	"io/ioutil"
 	"net/url"
 	"os"
 	"os/exec"
 	"regexp"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	//"os/user"
+	"github.com/buger/goterm"
 	"github.com/skratchdot/open-golang/open"
 )
 
-//Current time reference used in execution of any single todo invocation
-//for parsing, date filtering, sorting, etc.
-var Now time.Time
-
 type App struct {
 	TodoStore  Store
 	Cfg        *Config
 	Printer    Printer
 	TodoList   *TodoList
 	CommandMap map[string]Command
+	Clock      Clock    //Time reference for parsing, date filtering, sorting, etc. Fixed by TODO_NOW.
+	changed    []*Todo  //Todos saved by the command, for the on-exit hooks
+	aliases    []string //Aliases being run, innermost last, to catch loops
 }
 
-func NewApp() *App {
+//Returns ErrBadDate if TODO_NOW is not a date. Returns ErrBadConfig if a report is invalid or a config file can't be
+//read (e.g. a missing include), along with the App, so the config command can still check and fix the config. The
+//overrides (key=value) take precedence over the config files and environment.
+func NewApp(overrides ...string) (*App, error) {
+	clock, err := NewClock()
+	if err != nil {
+		return nil, err
+	}
 	app := &App{
-		TodoList:   &TodoList{},
-		Printer:    NewScreenPrinter(),
+		TodoList:   NewTodoList(clock),
 		TodoStore:  NewFileStore(),
 		CommandMap: map[string]Command{},
+		Clock:      clock,
 	}
-	app.loadConfig()
+	cfgErr := app.loadConfig(overrides)
+	app.Printer = NewScreenPrinter(clock, app.Cfg.Colors)
+	app.TodoList.Hooks = NewHooks(app.Cfg.HooksDir)
 	app.mapCommands()
-	Now = time.Now()
-	return app
+	return app, cfgErr
 }
 
-func (a *App) loadConfig() {
+//Returns the error for an invalid report or unreadable file, with as much of the config as could be loaded
+func (a *App) loadConfig(overrides []string) error {
 	cfgStore := NewConfigStore()
-	config, err := cfgStore.Load()
-	if err != nil {
-		fmt.Println("Error reading .todorc config file: ", err)
-	}
+	config, err := cfgStore.Load(overrides...)
 	a.Cfg = config
 	//Iterate over alias and create commands
 	for key, val := range config.Aliases {
@@ -54,11 +63,16 @@ func (a *App) loadConfig() {
 	}
 	//Iterate over reports and create commands
 	for key, _ := range config.Reports {
+		//report.next.* settings are merged over the built-in next report, in mapCommands
+		if key == "next" {
+			continue
+		}
 		r, ok := a.Cfg.GetReport(key)
 		if ok {
 			a.AddReportCommand(key, r)
 		}
 	}
+	return err
 }
 
 func (a *App) LoadPending() error {
@@ -79,8 +93,23 @@ func (a *App) LoadArchived() error {
 	return nil
 }
 
-func (a *App) Save() {
-	a.TodoStore.Save(a.TodoList.Data)
+func (a *App) Save() error {
+	if err := a.TodoStore.Save(a.TodoList.Data); err != nil {
+		return err
+	}
+	//Reset so long running commands (pomo, tui) that save more than once don't repeat todos in the backlog
+	for _, todo := range a.TodoList.Data {
+		if todo.IsModified {
+			a.changed = AddTodoIfNotThere(a.changed, todo)
+		}
+		todo.IsModified = false
+	}
+	return nil
+}
+
+//Run the on-exit hooks with the todos the command changed. Call once the command has run.
+func (a *App) RunExitHooks() error {
+	return a.TodoList.Hooks.OnExit(a.changed)
 }
 
 func (a *App) ProcessCmdLine(input string) Command {
@@ -94,6 +123,7 @@ func (a *App) ProcessCmdLine(input string) Command {
 		- If command supports modifications (cannot also support args), treat anything to right of command as modification
 		- If command supports args (cannot also support modifications), treat anything to the right as an arg
 		- Add command should be treated as if it has "modifications" since subject, due, project, etc. are all handled same if add or modify.
+		- A word naming no command or alias runs the plugin todo-<word>, if there is one, with anything to the right as args
 	*/
 	cmdMap := a.CommandMap
 
@@ -107,6 +137,11 @@ func (a *App) ProcessCmdLine(input string) Command {
 		//try to find command
 		if command == nil {
 			command = cmdMap[part]
+			if command == nil { //Not a command or alias, so a plugin (todo-<part>) if there is one
+				if path := FindPlugin(a.Cfg.PluginsDir, part); path != "" {
+					command = a.newPluginCommand(part, path, len(filters) > 0)
+				}
+			}
 			if command == nil { //Filters to left of command
 				filters = append(filters, part)
 			}
@@ -137,8 +172,79 @@ func (a *App) ProcessCmdLine(input string) Command {
 	return command
 }
 
+//Edit, archive, delete and complete require a filter, to protect against changing every todo by mistake
+func IsMissingRequiredFilter(command Command) bool {
+	cmd := command.GetCmd()
+	return (cmd == "edit" || cmd == "archive" || cmd == "delete" || cmd == "complete") && len(command.GetFilters()) == 0
+}
+
+//Prepend the filters of the current view (set of filters applied by default)
+func (a *App) ApplyView(command Command) {
+	if len(a.Cfg.CurrentView) > 0 {
+		viewFilters := a.Cfg.Views[a.Cfg.CurrentView]
+		command.SetFilters(append(append([]string{}, viewFilters...), command.GetFilters()...))
+	}
+}
+
+//Remove the output:json|csv|tsv|md|html and file:<name> args and return the printer they select, or the App's printer
+//if there is none. Call done after printing to close the file.
+func (a *App) selectPrinter(vals []string) (printer Printer, rest []string, done func() error, err error) {
+	var output, filename string
+	rest = []string{}
+	for _, val := range vals {
+		if strings.HasPrefix(val, "output:") {
+			output = val[7:]
+		} else if strings.HasPrefix(val, "file:") {
+			filename = val[5:]
+		} else {
+			rest = append(rest, val)
+		}
+	}
+	done = func() error { return nil }
+	if output == "" && filename == "" {
+		return a.Printer, rest, done, nil
+	}
+	if filename != "" && (output == "" || output == "screen") {
+		return nil, nil, nil, newError(ErrBadInput, "Error: file: requires output:json, csv, tsv, md or html")
+	}
+	printer, err = NewPrinter(output, os.Stdout, a.Clock, a.Cfg.Colors)
+	if err != nil {
+		return nil, nil, nil, newError(ErrBadInput, "Error: %s", err)
+	}
+	if filename != "" {
+		file, err := os.Create(filename)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("Error creating output file: %w", err)
+		}
+		printer, _ = NewPrinter(output, file, a.Clock, a.Cfg.Colors)
+		done = func() error {
+			if err := file.Close(); err != nil {
+				return fmt.Errorf("Error writing output file: %w", err)
+			}
+			fmt.Printf("Output written to %s.\n", filename)
+			return nil
+		}
+	}
+	return printer, rest, done, nil
+}
+
+//Close the output of selectPrinter and return the first of the printing and closing errors
+func finishPrint(err error, done func() error) error {
+	if closeErr := done(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
 func (a *App) AddAliasCommand(alias string, command string) {
-	aliasCmd := NewCommand(command, true, false, a.ExecAlias)
+	aliasCmd := &AliasCmd{
+		CommandImpl{
+			Cmd:          command,
+			IsAcceptMods: true,
+			IsAcceptArgs: false,
+		},
+		alias,
+	}
 	a.CommandMap[alias] = aliasCmd
 }
 
@@ -158,145 +264,535 @@ func (a *App) AddReportCommand(key string, val *Report) *ReportCmd {
 
 //Functions that implement command logic
 
-func (a *App) GarbageCollect(c *CommandImpl) {
-	a.LoadPending()
-	a.TodoList.GarbageCollect()
-	a.Save()
+func (a *App) GarbageCollect(c *CommandImpl) error {
+	if err := a.LoadPending(); err != nil {
+		return err
+	}
+	if err := a.TodoList.GarbageCollect(); err != nil {
+		return err
+	}
+	if err := a.Save(); err != nil {
+		return err
+	}
 	fmt.Println("Garbage collection complete.")
+	return nil
 }
 
-func (a *App) InitializeRepo(c *CommandImpl) {
-	CreateDefaultConfig()
-	a.TodoStore.Initialize()
+func (a *App) InitializeRepo(c *CommandImpl) error {
+	if err := CreateDefaultConfig(); err != nil {
+		return err
+	}
+	if err := a.TodoStore.Initialize(); err != nil {
+		return err
+	}
+	fmt.Println("Todo repo initialized.")
+	return nil
 }
 
-func (a *App) SetView(c *CommandImpl) {
+func (a *App) SetView(c *CommandImpl) error {
 	cfgStore := NewConfigStore()
 	if len(c.Mods) > 0 {
 		err := cfgStore.SetConfigValue("view.current", c.Mods[0])
 		if err != nil {
-			fmt.Println("Error setting view: ", err)
-		} else {
-			fmt.Println("View set to: ", c.Mods[0])
+			return fmt.Errorf("Error setting view: %w", err)
 		}
+		fmt.Println("View set to: ", c.Mods[0])
 	}
+	return nil
 }
 
-func (a *App) AddTodo(c *CommandImpl) {
-	a.LoadPending()
-	parser := &Parser{}
-	todo := parser.ParseNewTodo(c.Mods, a.TodoList)
+func (a *App) ManageConfig(c *CommandImpl) error {
+	/*
+		td config get <key> | set <key> <value> | unset <key> | list [prefix] | check
+		Read and change .todorc settings, and check them for mistakes.
+	*/
+	usage := newError(ErrBadInput, "Expected config get <key>, set <key> <value>, unset <key>, list [prefix] or check")
+	if len(c.Args) == 0 {
+		return usage
+	}
+	cfgStore := NewConfigStore()
+	defaults := DefaultConfigValues()
+	switch {
+	case c.Args[0] == "get" && len(c.Args) == 2:
+		key := c.Args[1]
+		value, ok := a.Cfg.Values[key]
+		if !ok {
+			if value, ok = defaults[key]; !ok {
+				return newError(ErrBadInput, "%s is not set", key)
+			}
+		}
+		fmt.Println(value)
+	case c.Args[0] == "set" && len(c.Args) > 2:
+		key, value := c.Args[1], strings.Join(c.Args[2:], " ")
+		//Let the check see a new alias
+		if keys := strings.Split(key, "."); keys[0] == "alias" && len(keys) == 2 {
+			if cmd, found := a.CommandMap[keys[1]]; !found {
+				a.AddAliasCommand(keys[1], value)
+			} else if alias, isAlias := cmd.(*AliasCmd); isAlias {
+				alias.Cmd = value
+			}
+		}
+		if err := a.checkConfigValue(key, value); err != nil {
+			return newError(ErrBadInput, "Error: %s: %s (see help config)", key, err)
+		}
+		if err := cfgStore.SetConfigValue(key, value); err != nil {
+			return err
+		}
+		fmt.Printf("%s set to %s in %s\n", key, value, cfgStore.FileLocation)
+	case c.Args[0] == "unset" && len(c.Args) == 2:
+		if err := cfgStore.UnsetConfigValue(c.Args[1]); err != nil {
+			return err
+		}
+		fmt.Printf("%s unset in %s\n", c.Args[1], cfgStore.FileLocation)
+	case c.Args[0] == "list" && len(c.Args) <= 2:
+		prefix := ""
+		if len(c.Args) == 2 {
+			prefix = c.Args[1]
+		}
+		keys := []string{}
+		for key := range a.Cfg.Values {
+			keys = append(keys, key)
+		}
+		for key := range defaults {
+			if _, ok := a.Cfg.Values[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		//Key, value, where it was set and the default it overrides
+		rows := [][]string{}
+		for _, key := range keys {
+			if !strings.HasPrefix(key, prefix) {
+				continue
+			}
+			value, set := a.Cfg.Values[key]
+			if !set {
+				rows = append(rows, []string{key, defaults[key], "default", ""})
+			} else {
+				rows = append(rows, []string{key, value, a.Cfg.Sources[key].String(), defaults[key]})
+			}
+		}
+		NewScreenPrinter(a.Clock, a.Cfg.Colors).PrintConfigList(rows)
+	case c.Args[0] == "check" && len(c.Args) == 1:
+		problems := a.CheckConfig()
+		for _, problem := range problems {
+			fmt.Println(problem)
+		}
+		if len(problems) > 0 {
+			return newError(ErrBadConfig, "%d %s found", len(problems), pluralize(len(problems), "problem", "problems"))
+		}
+		fmt.Println("No problems found.")
+	default:
+		return usage
+	}
+	return nil
+}
+
+func (a *App) AddTodo(c *CommandImpl) error {
+	if err := a.LoadPending(); err != nil {
+		return err
+	}
+	parser := &Parser{Clock: a.Clock}
+	todo, err := parser.ParseNewTodo(c.Mods, a.TodoList)
+	if err != nil {
+		return err
+	}
 	if todo == nil {
-		fmt.Println("I need more information. Try something like 'todo a chat with bob @Bob due:tom'")
-		return
+		return newError(ErrBadInput, "I need more information. Try something like 'todo a chat with bob @Bob due:tom'")
 	}
 
-	id := a.TodoList.Add(todo)
-	a.Save()
+	id, err := a.TodoList.Add(todo)
+	if err != nil {
+		return err
+	}
+	if err := a.Save(); err != nil {
+		return err
+	}
 	fmt.Printf("Todo %d added.\n", id)
+	return nil
 }
 
 // AddDoneTodo Adds a todo and immediately completed it.
-func (a *App) AddDoneTodo(c *CommandImpl) {
-	a.LoadPending()
-	parser := &Parser{}
-	todo := parser.ParseNewTodo(c.Mods, a.TodoList)
+func (a *App) AddDoneTodo(c *CommandImpl) error {
+	if err := a.LoadPending(); err != nil {
+		return err
+	}
+	parser := &Parser{Clock: a.Clock}
+	todo, err := parser.ParseNewTodo(c.Mods, a.TodoList)
+	if err != nil {
+		return err
+	}
 	if todo == nil {
-		fmt.Println("I need more information. Try something like 'todo done chating with bob'")
-		return
+		return newError(ErrBadInput, "I need more information. Try something like 'todo done chating with bob'")
 	}
 
-	id := a.TodoList.Add(todo)
-	a.TodoList.Complete(todo)
-	a.Save()
+	id, err := a.TodoList.Add(todo)
+	if err != nil {
+		return err
+	}
+	if err := a.TodoList.Complete(todo); err != nil {
+		return err
+	}
+	if a.Cfg.TimeTrackAutoStop {
+		for _, active := range a.TodoList.Active() {
+			if _, err := a.TodoList.Stop(active); err != nil {
+				return err
+			}
+			fmt.Printf("Stopped Todo %d.\n", active.Id)
+		}
+	}
+	if err := a.Save(); err != nil {
+		return err
+	}
 	fmt.Printf("Completed Todo %d added.\n", id)
+	return nil
+}
+
+func (a *App) StartTodo(c *CommandImpl) error {
+	if err := a.LoadPending(); err != nil {
+		return err
+	}
+	if len(c.Filters) == 0 {
+		return newError(ErrBadInput, "I need the id of the todo to start. Try something like 'todo 3 start'")
+	}
+	filtered, err := NewToDoFilter(a.TodoList.Todos(), a.Clock).Filter(c.Filters)
+	if err != nil {
+		return err
+	}
+	if len(filtered) == 0 {
+		return nil
+	}
+	if !a.Cfg.TimeTrackMultiple {
+		if len(filtered) > 1 {
+			return newError(ErrBadInput, "Only one todo can be started at a time. Set timetrack.multiple=true in .todorc to allow more.")
+		}
+		//Only one active todo per repo. Stop the todo currently started before starting another.
+		for _, active := range a.TodoList.Active() {
+			if active != filtered[0] {
+				if _, err := a.TodoList.Stop(active); err != nil {
+					return err
+				}
+				fmt.Printf("Stopped Todo %d.\n", active.Id)
+			}
+		}
+	}
+	started, err := a.TodoList.Start(filtered...)
+	if err != nil {
+		return err
+	}
+	if started {
+		if err := a.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("%s started.\n", pluralize(len(filtered), "Todo", "Todos"))
+	} else {
+		fmt.Println("Already started.")
+	}
+	return nil
+}
+
+func (a *App) StopTodo(c *CommandImpl) error {
+	if err := a.LoadPending(); err != nil {
+		return err
+	}
+	//Without filters, stop whatever is started
+	filtered := a.TodoList.Active()
+	if len(c.Filters) > 0 {
+		var err error
+		if filtered, err = NewToDoFilter(a.TodoList.Todos(), a.Clock).Filter(c.Filters); err != nil {
+			return err
+		}
+	}
+	stopped := 0
+	for _, todo := range filtered {
+		isStopped, err := a.TodoList.Stop(todo)
+		if err != nil {
+			return err
+		}
+		if isStopped {
+			stopped++
+			fmt.Printf("Todo %d stopped. Time spent: %s\n", todo.Id, durationToString(todo.TimeSpent(a.Clock.Now())))
+		}
+	}
+	if stopped > 0 {
+		if err := a.Save(); err != nil {
+			return err
+		}
+	} else {
+		fmt.Println("No started todos to stop.")
+	}
+	return nil
 }
 
-func (a *App) DeleteTodo(c *CommandImpl) {
-	a.LoadPending()
-	filtered := NewToDoFilter(a.TodoList.Todos()).Filter(c.Filters)
+func (a *App) DeleteTodo(c *CommandImpl) error {
+	if err := a.LoadPending(); err != nil {
+		return err
+	}
+	filtered, err := NewToDoFilter(a.TodoList.Todos(), a.Clock).Filter(c.Filters)
+	if err != nil {
+		return err
+	}
 	if len(filtered) == 0 {
-		return
+		return nil
 	}
 
-	a.TodoList.Delete(filtered...)
-	a.Save()
+	if err := a.TodoList.Delete(filtered...); err != nil {
+		return err
+	}
+	if err := a.Save(); err != nil {
+		return err
+	}
 	fmt.Printf("%s deleted.\n", pluralize(len(filtered), "Todo", "Todos"))
+	return nil
 }
 
-func (a *App) CompleteTodo(c *CommandImpl) {
-	a.LoadPending()
-	filtered := NewToDoFilter(a.TodoList.Todos()).Filter(c.Filters)
+func (a *App) CompleteTodo(c *CommandImpl) error {
+	if err := a.LoadPending(); err != nil {
+		return err
+	}
+	filtered, err := NewToDoFilter(a.TodoList.Todos(), a.Clock).Filter(c.Filters)
+	if err != nil {
+		return err
+	}
 	if len(filtered) == 0 {
-		return
+		return nil
+	}
+	if err := a.TodoList.Complete(filtered...); err != nil {
+		return err
+	}
+	if err := a.Save(); err != nil {
+		return err
 	}
-	a.TodoList.Complete(filtered...)
-	a.Save()
 	fmt.Printf("%s completed.\n", pluralize(len(filtered), "Todo", "Todos"))
+	return nil
 }
 
-func (a *App) UncompleteTodo(c *CommandImpl) {
-	a.LoadPending()
-	filtered := NewToDoFilter(a.TodoList.Todos()).Filter(c.Filters)
+func (a *App) UncompleteTodo(c *CommandImpl) error {
+	if err := a.LoadPending(); err != nil {
+		return err
+	}
+	filtered, err := NewToDoFilter(a.TodoList.Todos(), a.Clock).Filter(c.Filters)
+	if err != nil {
+		return err
+	}
 	if len(filtered) == 0 {
-		return
+		return nil
+	}
+	if err := a.TodoList.Uncomplete(filtered...); err != nil {
+		return err
+	}
+	if err := a.Save(); err != nil {
+		return err
 	}
-	a.TodoList.Uncomplete(filtered...)
-	a.Save()
 	fmt.Printf("%s uncompleted.\n", pluralize(len(filtered), "Todo", "Todos"))
+	return nil
 }
 
-func (a *App) ArchiveTodo(c *CommandImpl) {
-	a.LoadPending()
-	filtered := NewToDoFilter(a.TodoList.Todos()).Filter(c.Filters)
+func (a *App) ArchiveTodo(c *CommandImpl) error {
+	if err := a.LoadPending(); err != nil {
+		return err
+	}
+	filtered, err := NewToDoFilter(a.TodoList.Todos(), a.Clock).Filter(c.Filters)
+	if err != nil {
+		return err
+	}
 	if len(filtered) == 0 {
-		return
+		return nil
+	}
+	if err := a.TodoList.Archive(filtered...); err != nil {
+		return err
 	}
-	a.TodoList.Archive(filtered...)
 	//load the archived todos from file so a.Save() call will save them all to the same file
-	a.LoadArchived() //only do this when operating on archived
-	a.Save()
+	if err := a.LoadArchived(); err != nil { //only do this when operating on archived
+		return err
+	}
+	if err := a.Save(); err != nil {
+		return err
+	}
 	fmt.Printf("%s archived.\n", pluralize(len(filtered), "Todo", "Todos"))
+	return nil
 }
 
-func (a *App) UnarchiveTodo(c *CommandImpl) {
-	a.LoadArchived() //only do this when operating on archived
-	filtered := NewToDoFilter(a.TodoList.Todos()).Filter(c.Filters)
+func (a *App) UnarchiveTodo(c *CommandImpl) error {
+	if err := a.LoadArchived(); err != nil { //only do this when operating on archived
+		return err
+	}
+	filtered, err := NewToDoFilter(a.TodoList.Todos(), a.Clock).Filter(c.Filters)
+	if err != nil {
+		return err
+	}
 	if len(filtered) == 0 {
-		println("UnarchiveTodo: filtered list is 0 for filter: ", c.Filters[0])
-		return
+		return newError(ErrNotFound, "No archived todos matching filter criteria: %s", strings.Join(c.Filters, " "))
+	}
+	if err := a.TodoList.Unarchive(filtered...); err != nil {
+		return err
+	}
+	if err := a.LoadPending(); err != nil { //load in complete set of unarchived so that they get saved together with newly unarchived
+		return err
+	}
+	if err := a.Save(); err != nil {
+		return err
 	}
-	a.TodoList.Unarchive(filtered...)
-	a.LoadPending() //load in complete set of unarchived so that they get saved together with newly unarchived
-	a.Save()
 	fmt.Printf("%s unarchived.\n", pluralize(len(filtered), "Todo", "Todos"))
+	return nil
 }
 
-func (a *App) EditTodo(c *CommandImpl) {
-	a.LoadPending()
-	filtered := NewToDoFilter(a.TodoList.Todos()).Filter(c.Filters)
-	isEdited := a.TodoList.Edit(c.Mods, filtered...)
+func (a *App) EditTodo(c *CommandImpl) error {
+	if err := a.LoadPending(); err != nil {
+		return err
+	}
+	filtered, err := NewToDoFilter(a.TodoList.Todos(), a.Clock).Filter(c.Filters)
+	if err != nil {
+		return err
+	}
+	isEdited, err := a.TodoList.Edit(c.Mods, filtered...)
+	if err != nil {
+		return err
+	}
 	if isEdited {
-		a.Save()
+		if err := a.Save(); err != nil {
+			return err
+		}
 		fmt.Printf("%s edited.\n", pluralize(len(filtered), "Todo", "Todos"))
 	}
+	return nil
 }
 
-func (a *App) TouchTodo(c *CommandImpl) {
-	a.LoadPending()
-	filtered := NewToDoFilter(a.TodoList.Todos()).Filter(c.Filters)
-	isTouched := a.TodoList.Touch(filtered...)
+//Edit the filtered todos as a document in $EDITOR, then apply the changes
+func (a *App) EditFull(c *CommandImpl) error {
+	if err := a.LoadPending(); err != nil {
+		return err
+	}
+	filtered, err := NewToDoFilter(a.TodoList.Todos(), a.Clock).Filter(c.Filters)
+	if err != nil {
+		return err
+	}
+	if len(filtered) == 0 {
+		return newError(ErrNotFound, "No todos matching filter criteria.")
+	}
+	file, err := ioutil.TempFile("", "todo-edit-*.txt")
+	if err != nil {
+		return fmt.Errorf("Error creating file to edit: %w", err)
+	}
+	filename := file.Name()
+	_, err = file.WriteString(FormatTodosForEdit(filtered))
+	file.Close()
+	if err != nil {
+		return fmt.Errorf("Error writing file to edit: %w", err)
+	}
+	if err := runEditor(filename); err != nil {
+		return fmt.Errorf("Error running editor: %w", err)
+	}
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("Error reading edited file: %w", err)
+	}
+	blocks, err := ParseEditedTodos(string(data))
+	if err != nil {
+		return newError(ErrBadInput, "Error parsing edited todos: %s\nNo changes applied. Your edits are in %s", err, filename)
+	}
+	os.Remove(filename)
+
+	originals := map[int]*Todo{}
+	for _, todo := range filtered {
+		originals[todo.Id] = todo
+	}
+	parser := &Parser{Clock: a.Clock}
+	edited := 0
+	for _, b := range blocks {
+		todo, ok := originals[b.Id]
+		if !ok {
+			fmt.Printf("Todo %d was not in the list being edited. Ignored.\n", b.Id)
+			continue
+		}
+		if b.Subject == "" {
+			fmt.Printf("Todo %d cannot have a blank subject. Ignored.\n", b.Id)
+			continue
+		}
+		mods := b.Mods(NewTodoEditBlock(todo))
+		isEdited, err := a.TodoList.Modify(todo, func() (bool, error) {
+			isEdited := false
+			if len(mods) > 0 {
+				ok, err := parser.ParseEditTodo(todo, mods, a.TodoList)
+				if err != nil {
+					return false, err
+				}
+				isEdited = ok
+			}
+			if b.Subject != todo.Subject || !equalNotes(b.Notes, todo.Notes) {
+				todo.Subject = b.Subject
+				todo.Notes = b.Notes
+				isEdited = true
+			}
+			return isEdited, nil
+		})
+		if errors.Is(err, ErrHookRejected) {
+			fmt.Printf("Todo %d not edited: %s\n", b.Id, err)
+			continue
+		} else if err != nil {
+			return err
+		}
+		if isEdited {
+			edited++
+		}
+	}
+	if edited > 0 {
+		if err := a.Save(); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("%s edited.\n", pluralize(edited, "Todo", "Todos"))
+	return nil
+}
+
+//Open a file in $VISUAL or $EDITOR (default vi) and wait for the editor to exit
+func runEditor(filename string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	args := strings.Fields(editor) //e.g. EDITOR="code --wait"
+	cmd := exec.Command(args[0], append(args[1:], filename)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func (a *App) TouchTodo(c *CommandImpl) error {
+	if err := a.LoadPending(); err != nil {
+		return err
+	}
+	filtered, err := NewToDoFilter(a.TodoList.Todos(), a.Clock).Filter(c.Filters)
+	if err != nil {
+		return err
+	}
+	isTouched, err := a.TodoList.Touch(filtered...)
+	if err != nil {
+		return err
+	}
 	if isTouched {
-		a.Save()
+		if err := a.Save(); err != nil {
+			return err
+		}
 		fmt.Printf("%s touched.\n", pluralize(len(filtered), "Todo", "Todos"))
 	}
+	return nil
 }
 
-func (a *App) ExportTodo(c *CommandImpl) {
-	a.LoadPending()
-	filtered := NewToDoFilter(a.TodoList.Todos()).Filter(c.Filters)
+func (a *App) ExportTodo(c *CommandImpl) error {
+	if err := a.LoadPending(); err != nil {
+		return err
+	}
+	filtered, err := NewToDoFilter(a.TodoList.Todos(), a.Clock).Filter(c.Filters)
+	if err != nil {
+		return err
+	}
 	//Create filename
-	now := time.Now()
+	now := a.Clock.Now()
 	today := now.Format("20060102")
 	filename := "./todo_export_" + today + ".json" //default value
 	if len(c.Args) > 0 {
@@ -311,14 +807,17 @@ func (a *App) ExportTodo(c *CommandImpl) {
 		}
 	}
 	//Store to file. Q: Do we need a boolean confirm that is exported?
-	a.TodoStore.Export(filename, filtered)
+	if err := a.TodoStore.Export(filename, filtered); err != nil {
+		return err
+	}
 	fmt.Printf("%s exported.\n", pluralize(len(filtered), "Todo", "Todos"))
+	return nil
 }
 
-func (a *App) ImportTodo(c *CommandImpl) {
+func (a *App) ImportTodo(c *CommandImpl) error {
 
 	//Create filename automatically or read from args
-	now := time.Now()
+	now := a.Clock.Now()
 	today := now.Format("20060102")
 	filename := "./todo_export_" + today + ".json" //default value
 	if len(c.Args) > 0 {
@@ -335,264 +834,764 @@ func (a *App) ImportTodo(c *CommandImpl) {
 	// fileExists checks if a file exists and is not a directory
 	info, err := os.Stat(filename)
 	if os.IsNotExist(err) || info.IsDir() {
-		fmt.Printf("File not found '%s'.\n", filename)
-		return
+		return newError(ErrNotFound, "File not found '%s'.", filename)
 	}
 
 	//Load todos from file.
 	todos, err := a.TodoStore.Import(filename)
 	if err != nil {
-		fmt.Printf("Failed to import todos from %s.\n", filename)
-		return
+		return fmt.Errorf("Failed to import todos from %s: %w", filename, err)
+	}
+	if err := a.LoadPending(); err != nil {
+		return err
 	}
-	a.LoadPending()
 	//Loop over imported todos and call TodoList.Add(todo) for each one
 	//This will add the todo, renumber it, update modified date
 	for _, td := range todos {
-		a.TodoList.Add(td)
+		if _, err := a.TodoList.Add(td); err != nil {
+			return err
+		}
 	}
 	//Save updated pending todos
-	a.Save()
+	if err := a.Save(); err != nil {
+		return err
+	}
 	//Report success
 	fmt.Printf("%s imported.\n", pluralize(len(todos), "Todo", "Todos"))
+	return nil
 }
 
-func (a *App) AddNote(c *CommandImpl) {
-	a.LoadPending()
-	filtered := NewToDoFilter(a.TodoList.Todos()).Filter(c.Filters)
+func (a *App) AddNote(c *CommandImpl) error {
+	if err := a.LoadPending(); err != nil {
+		return err
+	}
+	filtered, err := NewToDoFilter(a.TodoList.Todos(), a.Clock).Filter(c.Filters)
+	if err != nil {
+		return err
+	}
 	if len(filtered) < 1 {
-		fmt.Println("Not found (filter): ", c.Filters)
-		fmt.Println("NOTE: Filters may include a view filter added from .todorc")
-		return
+		return newError(ErrNotFound, "Not found (filter): %s\nNOTE: Filters may include a view filter added from .todorc", strings.Join(c.Filters, " "))
 	}
 
-	parser := &Parser{}
+	parser := &Parser{Clock: a.Clock}
 	for _, todo := range filtered {
 		if parser.ParseAddNote(todo, c.Mods) {
-			todo.ModifiedDate = timeToString(Now)
+			todo.ModifiedDate = timeToString(a.Clock.Now())
 			todo.IsModified = true
 			fmt.Println("Note added to Todo ", todo.Id)
 		}
 	}
-	a.Save()
+	if err := a.Save(); err != nil {
+		return err
+	}
+	return nil
 }
 
-func (a *App) EditNote(c *CommandImpl) {
-	a.LoadPending()
-	filtered := NewToDoFilter(a.TodoList.Todos()).Filter(c.Filters)
+func (a *App) EditNote(c *CommandImpl) error {
+	if err := a.LoadPending(); err != nil {
+		return err
+	}
+	filtered, err := NewToDoFilter(a.TodoList.Todos(), a.Clock).Filter(c.Filters)
+	if err != nil {
+		return err
+	}
 	if len(filtered) < 1 {
-		fmt.Println("Not found (filter): ", c.Filters)
-		fmt.Println("NOTE: Filters may include a view filter added from .todorc")
-		return
+		return newError(ErrNotFound, "Not found (filter): %s\nNOTE: Filters may include a view filter added from .todorc", strings.Join(c.Filters, " "))
+	}
+	if len(filtered) > 1 {
+		matched := []string{}
+		for _, td := range filtered {
+			matched = append(matched, fmt.Sprintf("%d: %s", td.Id, td.Subject))
+		}
+		return newError(ErrBadInput, "Matched too many todos:\n%s\nEditNote operates on one Todo at a time (filter): %s\nNOTE: Filters may include a view filter added from .todorc",
+			strings.Join(matched, "\n"), strings.Join(c.Filters, " "))
+	}
+	parser := &Parser{Clock: a.Clock}
+	todo := filtered[0]
+	if parser.ParseEditNote(todo, c.Mods) {
+		todo.ModifiedDate = timeToString(a.Clock.Now())
+		todo.IsModified = true
+		fmt.Println("Note edited.")
+	}
+	if err := a.Save(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (a *App) DeleteNote(c *CommandImpl) error {
+	if err := a.LoadPending(); err != nil {
+		return err
+	}
+	filtered, err := NewToDoFilter(a.TodoList.Todos(), a.Clock).Filter(c.Filters)
+	if err != nil {
+		return err
+	}
+	if len(filtered) < 1 {
+		return newError(ErrNotFound, "Not found (filter): %s\nNOTE: Filters may include a view filter added from .todorc", strings.Join(c.Filters, " "))
+	}
+	if len(filtered) > 1 {
+		matched := []string{}
+		for _, td := range filtered {
+			matched = append(matched, fmt.Sprintf("%d: %s", td.Id, td.Subject))
+		}
+		return newError(ErrBadInput, "Matched too many todos:\n%s\nDeleteNote operates on one Todo at a time (filter): %s\nNOTE: Filters may include a view filter added from .todorc",
+			strings.Join(matched, "\n"), strings.Join(c.Filters, " "))
+	}
+	parser := &Parser{Clock: a.Clock}
+	todo := filtered[0]
+	if parser.ParseDeleteNote(todo, c.Mods) {
+		todo.ModifiedDate = timeToString(a.Clock.Now())
+		todo.IsModified = true
+		fmt.Println("Note deleted.")
+	}
+	if err := a.Save(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (a *App) ArchiveCompleted(c *CommandImpl) error {
+	if err := a.LoadPending(); err != nil {
+		return err
+	}
+	filtered, err := NewToDoFilter(a.TodoList.Todos(), a.Clock).Filter([]string{"Completed"})
+	if err != nil {
+		return err
+	}
+	if err := a.TodoList.Archive(filtered...); err != nil {
+		return err
+	}
+	//load the archived todos from file so a.Save() call will save them all to the same file
+	if err := a.LoadArchived(); err != nil { //only do this when operating on archived
+		return err
+	}
+	if err := a.Save(); err != nil {
+		return err
+	}
+	fmt.Println("All completed todos have been archived.")
+	return nil
+}
+
+func (a *App) OrderTodos(c *CommandImpl) error {
+	if err := a.LoadPending(); err != nil {
+		return err
+	}
+	//td ord all:3,4,5,6,7 OR td ord +BigProject:3,2,5,6 OR td ord @home:5,3,1
+	if len(c.Mods) < 1 || !strings.Contains(c.Mods[0], ":") {
+		return newError(ErrBadInput, "Invalid input. Expected ord(er) <set>:<comma-separated ids>")
+	}
+	tmp := strings.Split(c.Mods[0], ":")
+	set := tmp[0]
+	tmp2 := strings.Split(tmp[1], ",")
+	ids := []int{}
+	for _, val := range tmp2 {
+		id, err := strconv.Atoi(val)
+		if err != nil {
+			return newError(ErrBadInput, "Invalid input. Unable to parse id: %s", val)
+		}
+		ids = append(ids, id)
+	}
+	if err := a.TodoList.UpdateOrdinals(set, ids); err != nil {
+		return err
+	}
+	if err := a.Save(); err != nil {
+		return err
+	}
+	fmt.Println("Ordered Todos.")
+	return nil
+}
+
+func (a *App) ListProjects(c *CommandImpl) error {
+	if err := a.LoadPending(); err != nil {
+		return err
+	}
+	todos := a.TodoList.Data
+	m := map[string]int{}
+	var set []string
+	for _, todo := range todos {
+		set = todo.Projects
+		for _, name := range set {
+			//if map[name] == nil {
+			//	map[name] = 0
+			//}
+			m[name]++
+		}
+	}
+	p, _, done, err := a.selectPrinter(c.Filters)
+	if err != nil {
+		return err
+	}
+	return finishPrint(p.PrintSetCounts("Projects", m), done)
+}
+
+func (a *App) ListContexts(c *CommandImpl) error {
+	if err := a.LoadPending(); err != nil {
+		return err
+	}
+	todos := a.TodoList.Data
+	m := map[string]int{}
+	var set []string
+	for _, todo := range todos {
+		set = todo.Contexts
+		for _, name := range set {
+			//if map[name] == nil {
+			//	map[name] = 0
+			//}
+			m[name]++
+		}
+	}
+	p, _, done, err := a.selectPrinter(c.Filters)
+	if err != nil {
+		return err
+	}
+	return finishPrint(p.PrintSetCounts("Contexts", m), done)
+}
+
+func (a *App) ListTags(c *CommandImpl) error {
+	if err := a.LoadPending(); err != nil {
+		return err
+	}
+	todos := a.TodoList.Data
+	m := map[string]int{}
+	for _, todo := range todos {
+		for _, name := range todo.Tags {
+			m[name]++
+		}
+	}
+	p, _, done, err := a.selectPrinter(c.Filters)
+	if err != nil {
+		return err
+	}
+	return finishPrint(p.PrintSetCounts("Tags", m), done)
+}
+
+func (a *App) Stats(c *CommandImpl) error {
+	/*
+		pending, added, modified, completed, archived, deleted
+		per all, project or context
+		filtered by everything we allow to filter
+
+		td <filters> stats [by:all|pro|ctx] [cols:p,a,m,c,ar,d] [sum:all|daily|weekly|monthly]
+		output is tabular.
+		If sum and by:pro/ctx then group stats and row per day/week/month
+		If no sum (or sum:all) then no grouping and row per pro/ctx
+	*/
+
+	if err := a.LoadPending(); err != nil {
+		return err
+	}
+	if err := a.LoadArchived(); err != nil {
+		return err
+	}
+	cols := []string{"p", "a", "m", "c", "ar"}
+	var groupBy string
+	var sumBy string
+	var chart bool
+	var rangeTimes []time.Time
+	p, mods, done, err := a.selectPrinter(c.Mods)
+	if err != nil {
+		return err
+	}
+	for _, m := range mods {
+		if strings.HasPrefix(m, "cols:") {
+			cols = strings.Split(m[5:], ",")
+		} else if strings.HasPrefix(m, "by:") {
+			groupBy = m[3:]
+		} else if strings.HasPrefix(m, "sum:") {
+			sumBy = m[4:]
+		} else if strings.HasPrefix(m, "chart:") {
+			chart = (m[6:] == "true")
+		} else if strings.HasPrefix(m, "range:") {
+			tmp := m[6:]
+			vals := strings.Split(tmp, ":")
+			if rangeTimes, err = translateToDates(a.Clock.Now(), vals...); err != nil {
+				done()
+				return err
+			}
+		}
+	}
+	filtered, err := NewToDoFilter(a.TodoList.Todos(), a.Clock).Filter(c.Filters)
+	if err != nil {
+		done()
+		return err
+	}
+	if len(filtered) == 0 {
+		return done()
+	}
+	return finishPrint(p.PrintStats(filtered, groupBy, sumBy, cols, chart, rangeTimes), done)
+}
+
+func (a *App) Tui(c *CommandImpl) error {
+	/*
+		td [filters] tui [report] [sort:<replace sorting>]
+	*/
+	if err := a.LoadPending(); err != nil {
+		return err
+	}
+	report := a.CommandMap["list"].(*ReportCmd).SavedReport
+	var sorter *TodoSorter
+	for _, arg := range c.Args {
+		if strings.HasPrefix(arg, "sort:") {
+			sorter = NewTodoSorter(strings.Split(arg[5:], ",")...)
+		} else if cmd, isReport := a.CommandMap[arg].(*ReportCmd); isReport {
+			report = cmd.SavedReport
+		} else {
+			return newError(ErrBadInput, "Unknown report: %s", arg)
+		}
+	}
+	//A copy, so the sort: arg and reordering don't change the report other commands use
+	copied := *report
+	if sorter != nil {
+		copied.Sorter = sorter
+	}
+	copied.Sorter = copied.Sorter.ranked(a.Cfg.Priority, a.Cfg.UrgencyCoefficients)
+	tui := NewTodoTui(a, &copied, c.Filters)
+	if err := tui.Run(); err != nil {
+		return fmt.Errorf("Error starting tui: %w", err)
+	}
+	return nil
+}
+
+func (a *App) Pomodoro(c *CommandImpl) error {
+	if err := a.LoadPending(); err != nil {
+		return err
+	}
+	if len(c.Filters) == 0 {
+		return newError(ErrBadInput, "I need the id of the todo to work on. Try something like 'todo pomo 3'")
+	}
+	filtered, err := NewToDoFilter(a.TodoList.Todos(), a.Clock).Filter(c.Filters)
+	if err != nil {
+		return err
+	}
+	if len(filtered) != 1 {
+		return newError(ErrBadInput, "Pomodoro requires exactly one todo. Matched: %d", len(filtered))
+	}
+	todo := filtered[0]
+	//Pomodoros record their own intervals. Stop started todos so time is not counted twice.
+	stopped := a.TodoList.Active()
+	isStopped, err := a.TodoList.Stop(stopped...)
+	if err != nil {
+		return err
 	}
-	if len(filtered) > 1 {
-		fmt.Println("Matched too many Todos: ")
-		for _, td := range filtered {
-			fmt.Println(td.Id, ": ", td.Subject)
+	if isStopped {
+		if err := a.Save(); err != nil {
+			return err
 		}
-		fmt.Println("EditNote operates on one Todo at a time (filter): ", c.Filters)
-		fmt.Println("NOTE: Filters may include a view filter added from .todorc")
-		return
 	}
-	parser := &Parser{}
-	todo := filtered[0]
-	if parser.ParseEditNote(todo, c.Mods) {
-		todo.ModifiedDate = timeToString(Now)
+	count := 0
+	var saveErr error //The first pomodoro that could not be saved. The timer runs on, so the rest can be.
+	timer := NewPomodoroTimer(todo.Subject, a.Cfg.PomoWork, a.Cfg.PomoBreak)
+	err = timer.Run(func(start time.Time, end time.Time) {
+		todo.AddPomodoro(start, end)
+		todo.ModifiedDate = timeToString(a.Clock.Now())
 		todo.IsModified = true
-		fmt.Println("Note edited.")
+		if err := a.Save(); err != nil && saveErr == nil {
+			saveErr = err
+		}
+		count++
+	})
+	for _, t := range stopped {
+		fmt.Printf("Stopped Todo %d.\n", t.Id)
+	}
+	if err != nil {
+		return fmt.Errorf("Error starting pomodoro timer: %w", err)
+	}
+	if saveErr != nil {
+		return fmt.Errorf("Error saving pomodoro for Todo %d: %w", todo.Id, saveErr)
 	}
-	a.Save()
+	fmt.Printf("%s completed for Todo %d. Total: %d\n", pluralize(count, "Pomodoro", "Pomodoros"), todo.Id, todo.Pomodoros())
+	return nil
 }
 
-func (a *App) DeleteNote(c *CommandImpl) {
-	a.LoadPending()
-	filtered := NewToDoFilter(a.TodoList.Todos()).Filter(c.Filters)
-	if len(filtered) < 1 {
-		fmt.Println("Not found (filter): ", c.Filters)
-		fmt.Println("NOTE: Filters may include a view filter added from .todorc")
-		return
+func (a *App) Calendar(c *CommandImpl) error {
+	/*
+		td calendar | cal [month|year|this_month|next_month|last_month|this_year|next_year|last_year|month:<date>|year:<yyyy>] [agenda] [filters]
+		Month grid of due dates. Days are colored by the most overdue or urgent todo due that day.
+	*/
+	if err := a.LoadPending(); err != nil {
+		return err
 	}
-	if len(filtered) > 1 {
-		fmt.Println("Matched too many todos: ")
-		for _, td := range filtered {
-			fmt.Println(td.Id, ": ", td.Subject)
+	now := a.Clock.Now()
+	begin, months := now, 1
+	agenda := false
+	filters := []string{}
+	var dates []time.Time
+	var err error
+	for _, f := range c.Filters {
+		switch {
+		case f == "agenda":
+			agenda = true
+		case f == "month":
+		case f == "year":
+			begin, months = boy(now), 12
+		case f == "this_month" || f == "next_month" || f == "last_month":
+			dates, err = translateToDates(now, f)
+		case f == "this_year" || f == "next_year" || f == "last_year":
+			dates, err = translateToDates(now, f)
+			months = 12
+		case strings.HasPrefix(f, "month:"):
+			dates, err = translateToDates(now, f[6:])
+		case strings.HasPrefix(f, "year:"):
+			if year, atoiErr := strconv.Atoi(f[5:]); atoiErr == nil {
+				begin = time.Date(year, time.January, 1, 0, 0, 0, 0, now.Location())
+			} else if dates, err = translateToDates(now, f[5:]); err == nil {
+				dates[0] = boy(dates[0])
+			}
+			months = 12
+		default:
+			filters = append(filters, f)
+		}
+		if err != nil {
+			return err
+		}
+		if dates != nil {
+			begin, dates = dates[0], nil
 		}
-		fmt.Println("DeleteNote operates on one Todo at a time (filter): ", c.Filters)
-		fmt.Println("NOTE: Filters may include a view filter added from .todorc")
-		return
 	}
-	parser := &Parser{}
-	todo := filtered[0]
-	if parser.ParseDeleteNote(todo, c.Mods) {
-		todo.ModifiedDate = timeToString(Now)
-		todo.IsModified = true
-		fmt.Println("Note deleted.")
+	calcAllUrgency(a.TodoList.Todos(), now, a.Cfg.Priority, a.Cfg.UrgencyCoefficients)
+	filtered, err := NewToDoFilter(a.TodoList.Todos(), a.Clock).Filter(filters)
+	if err != nil {
+		return err
 	}
-	a.Save()
-}
-
-func (a *App) ArchiveCompleted(c *CommandImpl) {
-	a.LoadPending()
-	filtered := NewToDoFilter(a.TodoList.Todos()).Filter([]string{"Completed"})
-	a.TodoList.Archive(filtered...)
-	//load the archived todos from file so a.Save() call will save them all to the same file
-	a.LoadArchived() //only do this when operating on archived
-	a.Save()
-	fmt.Println("All completed todos have been archived.")
+	NewScreenPrinter(a.Clock, a.Cfg.Colors).PrintCalendar(NewCalendar(filtered, begin, months, a.Clock), agenda)
+	return nil
 }
 
-func (a *App) OrderTodos(c *CommandImpl) {
-	a.LoadPending()
-	//td ord all:3,4,5,6,7 OR td ord +BigProject:3,2,5,6 OR td ord @home:5,3,1
-	if len(c.Mods) < 1 || !strings.Contains(c.Mods[0], ":") {
-		println("Invalid input. Expected ord(er) <set>:<comma-separated ids>")
-		return
+func (a *App) Agenda(c *CommandImpl) error {
+	/*
+		td agenda [today|tomorrow|week|this_week|next_week|this_month|next_month|range:start date[:end date]] [filters]
+		Day by day list of the todos due, scheduled, waiting until, expiring and completed each day, with overdue todos at the top.
+	*/
+	if err := a.LoadPending(); err != nil {
+		return err
 	}
-	tmp := strings.Split(c.Mods[0], ":")
-	set := tmp[0]
-	tmp2 := strings.Split(tmp[1], ",")
-	ids := []int{}
-	for _, val := range tmp2 {
-		id, err := strconv.Atoi(val)
+	if err := a.LoadArchived(); err != nil {
+		return err
+	}
+	now := a.Clock.Now()
+	begin, end := bod(now), bod(now)
+	filters := []string{}
+	for _, f := range c.Filters {
+		var times []time.Time
+		var err error
+		switch {
+		case f == "today":
+		case f == "tomorrow":
+			begin = bod(now).AddDate(0, 0, 1)
+			end = begin
+		case f == "week":
+			if times, err = translateToDates(now, "this_week"); err == nil {
+				begin, end = times[0], times[1].AddDate(0, 0, -1)
+			}
+		case f == "this_week" || f == "next_week" || f == "last_week" || f == "this_month" || f == "next_month" || f == "last_month":
+			if times, err = translateToDates(now, f); err == nil {
+				begin, end = times[0], times[1].AddDate(0, 0, -1)
+			}
+		case strings.HasPrefix(f, "range:"):
+			vals := strings.Split(f[6:], ":")
+			if times, err = translateToDates(now, vals...); err == nil {
+				begin, end = times[0], times[len(times)-1]
+				if len(vals) == 1 && len(times) == 2 {
+					end = end.AddDate(0, 0, -1) //a period, like this_week, ends where the next begins
+				}
+			}
+		default:
+			filters = append(filters, f)
+		}
 		if err != nil {
-			println("Invalid input. Unable to parse id: ", val)
-			return
+			return err
 		}
-		ids = append(ids, id)
 	}
-	a.TodoList.UpdateOrdinals(set, ids)
-	a.Save()
-	println("Ordered Todos.")
+	if end.Before(begin) {
+		return newError(ErrBadInput, "The end of the agenda range is before the beginning.")
+	}
+	//Waiting todos belong on the agenda the day they stop waiting
+	todos, err := a.filterWithWaiting(filters)
+	if err != nil {
+		return err
+	}
+	NewScreenPrinter(a.Clock, a.Cfg.Colors).PrintAgenda(NewAgenda(todos, begin, end, a.Clock))
+	return nil
 }
 
-func (a *App) ListProjects(c *CommandImpl) {
-	a.LoadPending()
-	todos := a.TodoList.Data
-	m := map[string]int{}
-	var set []string
+//Filter the todos, including the waiting todos the default filter hides
+func (a *App) filterWithWaiting(filters []string) ([]*Todo, error) {
+	todos, err := NewToDoFilter(a.TodoList.Todos(), a.Clock).Filter(append([]string{}, filters...))
+	if err != nil {
+		return nil, err
+	}
+	waiting, err := NewToDoFilter(a.TodoList.Todos(), a.Clock).Filter(append([]string{"#WAITING"}, filters...))
+	if err != nil {
+		return nil, err
+	}
+	seen := map[*Todo]bool{}
 	for _, todo := range todos {
-		set = todo.Projects
-		for _, name := range set {
-			//if map[name] == nil {
-			//	map[name] = 0
-			//}
-			m[name]++
+		seen[todo] = true
+	}
+	for _, todo := range waiting {
+		if !seen[todo] {
+			todos = append(todos, todo)
 		}
 	}
-	p := NewScreenPrinter()
-	p.PrintSetCounts("Projects", m)
+	return todos, nil
 }
 
-func (a *App) ListContexts(c *CommandImpl) {
-	a.LoadPending()
-	todos := a.TodoList.Data
-	m := map[string]int{}
-	var set []string
-	for _, todo := range todos {
-		set = todo.Contexts
-		for _, name := range set {
-			//if map[name] == nil {
-			//	map[name] = 0
-			//}
-			m[name]++
+func (a *App) Remind(c *CommandImpl) error {
+	/*
+		td <filters> remind [once]
+		Run in the foreground, reminding of todos due, scheduled or waiting until soon, at the configured lead times.
+	*/
+	once := false
+	for _, arg := range c.Args {
+		if arg == "once" {
+			once = true
+		} else {
+			return newError(ErrBadInput, "Unknown remind argument: %s", arg)
 		}
 	}
-	p := NewScreenPrinter()
-	p.PrintSetCounts("Contexts", m)
+	//Check the filters once, so a bad one is reported rather than repeated on every check
+	if err := a.LoadPending(); err != nil {
+		return err
+	}
+	if _, err := a.filterWithWaiting(c.Filters); err != nil {
+		return err
+	}
+	reminders := NewReminders(a.Cfg.RemindLeads, a.Cfg.RemindEvents, a.Cfg.RemindInterval)
+	firedFile := getRemindersLocation()
+	if err := reminders.LoadFired(firedFile); err != nil {
+		return fmt.Errorf("Error loading fired reminders: %w", err)
+	}
+	daemon := &ReminderDaemon{
+		Reminders: reminders,
+		Cmd:       a.Cfg.RemindCmd,
+		FiredFile: firedFile,
+		Load: func() []*Todo {
+			//Reload, to see todos added or changed since the last check. Remind is not run in a batch, which loads once.
+			a.TodoList.Data = []*Todo{}
+			if err := a.LoadPending(); err != nil {
+				fmt.Println(err)
+				return nil
+			}
+			todos, _ := a.filterWithWaiting(c.Filters)
+			return todos
+		},
+		Clock: a.Clock,
+		Sleep: time.Sleep,
+		Out:   os.Stdout,
+	}
+	if once {
+		daemon.Check()
+		return nil
+	}
+	fmt.Printf("Reminding %s before todos are due. Press Ctrl-C to stop.\n", remindLeadsString(a.Cfg.RemindLeads))
+	daemon.Run()
+	return nil
 }
 
-func (a *App) Stats(c *CommandImpl) {
+func (a *App) Timesheet(c *CommandImpl) error {
 	/*
-		pending, added, modified, completed, archived, deleted
-		per all, project or context
-		filtered by everything we allow to filter
-
-		td <filters> stats [by:all|pro|ctx] [cols:p,a,m,c,ar,d] [sum:all|daily|weekly|monthly]
-		output is tabular.
-		If sum and by:pro/ctx then group stats and row per day/week/month
-		If no sum (or sum:all) then no grouping and row per pro/ctx
+		td <filters> timesheet [by:pro|ctx|all] [sum:daily|weekly|monthly] [range:start date[:end date]] [output:csv] [file:<name>.csv]
+		Time spent (recorded with start and stop) as a matrix of project or context by period, with totals.
 	*/
-
-	a.LoadPending()
-	a.LoadArchived()
-	cols := []string{"p", "a", "m", "c", "ar"}
-	var groupBy string
+	if err := a.LoadPending(); err != nil {
+		return err
+	}
+	if err := a.LoadArchived(); err != nil {
+		return err
+	}
+	now := a.Clock.Now()
+	groupBy := "pro"
 	var sumBy string
-	var chart bool
+	var filename, output string
 	var rangeTimes []time.Time
 	for _, m := range c.Mods {
-		if strings.HasPrefix(m, "cols:") {
-			cols = strings.Split(m[5:], ",")
-		} else if strings.HasPrefix(m, "by:") {
+		if strings.HasPrefix(m, "by:") {
 			groupBy = m[3:]
 		} else if strings.HasPrefix(m, "sum:") {
 			sumBy = m[4:]
-		} else if strings.HasPrefix(m, "chart:") {
-			chart = (m[6:] == "true")
 		} else if strings.HasPrefix(m, "range:") {
-			tmp := m[6:]
-			vals := strings.Split(tmp, ":")
-			rangeTimes = translateToDates(Now, vals...)
+			vals := strings.Split(m[6:], ":")
+			var err error
+			if rangeTimes, err = timesheetRange(now, vals); err != nil {
+				return err
+			}
+		} else if strings.HasPrefix(m, "file:") {
+			filename = m[5:]
+		} else if strings.HasPrefix(m, "output:") {
+			output = strings.ToLower(m[7:])
 		}
 	}
-	filtered := NewToDoFilter(a.TodoList.Todos()).Filter(c.Filters)
-	if len(filtered) == 0 {
-		return
+	if output != "" && output != "screen" && output != "csv" {
+		return newError(ErrBadInput, "Error: the timesheet can be output to the screen or as csv")
+	}
+	filtered, err := NewToDoFilter(a.TodoList.Todos(), a.Clock).Filter(c.Filters)
+	if err != nil {
+		return err
+	}
+	sum, _ := parseSumBy(sumBy)
+	ts := NewTimesheet(filtered, groupBy, sum, rangeTimes, a.Clock)
+	if filename == "" && output != "csv" {
+		NewScreenPrinter(a.Clock, a.Cfg.Colors).PrintTimesheet(ts, groupBy)
+		return nil
+	}
+	if filename == "" {
+		return ts.WriteCSV(os.Stdout)
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("Error creating timesheet file: %w", err)
+	}
+	defer file.Close()
+	if err := ts.WriteCSV(file); err != nil {
+		return fmt.Errorf("Error writing timesheet file: %w", err)
 	}
-	p := NewScreenPrinter()
-	p.PrintStats(filtered, groupBy, sumBy, cols, chart, rangeTimes)
+	fmt.Printf("Timesheet exported to %s.\n", filename)
+	return nil
 }
 
-func (a *App) PrintTodoDetail(c *CommandImpl) {
-	a.LoadPending()
-	filtered := NewToDoFilter(a.TodoList.Todos()).Filter(c.Filters)
-	if len(filtered) == 0 {
-		return
+//Commands that can't run in a batch, because they are interactive, run other commands or replace the store
+var batchExcludedCmds = []string{"batch", "tui", "pomo", "web", "edit-full", "sync", "init", "open", "remind"}
+
+func (a *App) Batch(c *CommandImpl) error {
+	/*
+		td batch [file|-]
+		Run one command line per line of the file (or stdin). Todos are loaded once and saved once at the end.
+		If any command fails, nothing is saved.
+	*/
+	in := os.Stdin
+	if len(c.Args) > 0 && c.Args[0] != "-" {
+		file, err := os.Open(c.Args[0])
+		if err != nil {
+			return fmt.Errorf("Error opening batch file: %w", err)
+		}
+		defer file.Close()
+		in = file
+	}
+	lines := []string{}
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("Error reading batch: %w", err)
+	}
+
+	origStore := a.TodoStore
+	store := NewBatchStore(origStore)
+	a.TodoStore = store
+	defer func() { a.TodoStore = origStore }()
+	count := 0
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		//Accept lines copied from the shell, e.g. todo add Call Bob
+		if fields := strings.Fields(line); len(fields) > 1 && fields[0] == "todo" {
+			line = strings.Join(fields[1:], " ")
+		}
+		//Each command loads the todos as the commands before it left them, as it would run on its own
+		a.TodoList.Data = nil
+		store.Next()
+		if err := a.execBatchLine(line); err != nil {
+			a.changed = nil
+			return fmt.Errorf("Line %d: %w\nBatch aborted. No changes saved.", i+1, err)
+		}
+		count++
+	}
+	if err := store.Commit(); err != nil {
+		return err
+	}
+	modified := store.Modified()
+	fmt.Printf("Batch complete. %d %s run. %d %s added or changed.\n", count, pluralize(count, "command", "commands"), modified, pluralize(modified, "todo", "todos"))
+	return nil
+}
+
+func (a *App) execBatchLine(line string) error {
+	command := a.ProcessCmdLine(line)
+	cmd := command.GetCmd()
+	for _, excluded := range batchExcludedCmds {
+		if command == a.CommandMap[excluded] {
+			return fmt.Errorf("%s can't be run in a batch", excluded)
+		}
+	}
+	if IsMissingRequiredFilter(command) {
+		return newError(ErrMissingFilter, "%s requires a filter. None specified", cmd)
+	}
+	a.ApplyView(command)
+	if err := command.Exec(a); err != nil {
+		return fmt.Errorf("%s failed: %w", cmd, err)
+	}
+	return nil
+}
+
+func (a *App) PrintTodoDetail(c *CommandImpl) error {
+	if err := a.LoadPending(); err != nil {
+		return err
+	}
+	p, filters, done, err := a.selectPrinter(c.Filters)
+	if err != nil {
+		return err
+	}
+	filtered, err := NewToDoFilter(a.TodoList.Todos(), a.Clock).Filter(filters)
+	if err != nil {
+		done()
+		return err
 	}
-	p := NewScreenPrinter()
-	p.PrintTodoDetail(filtered)
+	return finishPrint(p.PrintTodoDetail(filtered), done)
 }
 
-func (a *App) Sync(c *CommandImpl) {
-	s := NewTodoSync(a.Cfg, a.TodoStore)
+func (a *App) Sync(c *CommandImpl) error {
+	s := NewTodoSync(a.Cfg, a.TodoStore, a.Clock)
 	verbose := false
 	if len(c.Mods) > 0 {
 		if c.Mods[0] == "verbose" {
 			verbose = true
 		}
 	}
-	err := s.Sync(verbose)
-	if err != nil {
-		fmt.Println("Error: ", err.Error())
-		os.Exit(1)
+	if err := s.Sync(verbose); err != nil {
+		return fmt.Errorf("Error: %w", err)
 	}
+	return nil
 }
 
-func (a *App) CompleteAndArchive(c *CommandImpl) {
-	a.LoadPending()
-	filtered := NewToDoFilter(a.TodoList.Todos()).Filter(c.Filters)
+func (a *App) CompleteAndArchive(c *CommandImpl) error {
+	if err := a.LoadPending(); err != nil {
+		return err
+	}
+	filtered, err := NewToDoFilter(a.TodoList.Todos(), a.Clock).Filter(c.Filters)
+	if err != nil {
+		return err
+	}
 	if len(filtered) == 0 {
-		return
+		return nil
+	}
+	if err := a.TodoList.CompleteAndArchive(filtered...); err != nil {
+		return err
+	}
+	if err := a.LoadArchived(); err != nil { //only do this when operating on archived
+		return err
+	}
+	if err := a.Save(); err != nil {
+		return err
 	}
-	a.TodoList.CompleteAndArchive(filtered...)
-	a.LoadArchived() //only do this when operating on archived
-	a.Save()
 	var ids []string
 	for _, todo := range filtered {
 		ids = append(ids, strconv.Itoa(todo.Id))
 	}
-	println("Completed and archived Todos:", strings.Join(ids, ","))
+	fmt.Println("Completed and archived Todos:", strings.Join(ids, ","))
+	return nil
 }
 
-func (a *App) NewWebApp(c *CommandImpl) {
+func (a *App) NewWebApp(c *CommandImpl) error {
 	if err := a.LoadPending(); err != nil {
-		os.Exit(1)
-	} else {
-		web := NewWebapp()
-		fmt.Println("Now serving todolist web.\nHead to http://localhost:7890 to see your todo list!")
-		open.Start("http://localhost:7890")
-		web.Run()
+		return err
 	}
+	web := NewWebapp()
+	fmt.Println("Now serving todolist web.\nHead to http://localhost:7890 to see your todo list!")
+	open.Start("http://localhost:7890")
+	return web.Run()
 }
 
 type OpenTask struct {
@@ -614,26 +1613,27 @@ func NewOpenTask(todo *Todo, idx int, uriType string, uri string, cmd string) *O
 	return openTask
 }
 
-func (a *App) Open(c *CommandImpl) {
+func (a *App) Open(c *CommandImpl) error {
 	if err := a.LoadPending(); err != nil {
-		os.Exit(1)
+		return err
 	} else {
-		todos := NewToDoFilter(a.TodoList.Data).Filter(c.Filters)
+		todos, err := NewToDoFilter(a.TodoList.Data, a.Clock).Filter(c.Filters)
+		if err != nil {
+			return err
+		}
 		if len(todos) == 0 {
 			fmt.Println("No todos matching filter criteria.")
-			return
+			return nil
 		}
 		openNotesRegex, err := regexp.Compile(a.Cfg.OpenNotesRegex)
 		if err != nil {
-			fmt.Println("Error compiling notes regex: " + a.Cfg.OpenNotesRegex)
-			os.Exit(1)
+			return newError(ErrBadConfig, "Error compiling notes regex: %s", a.Cfg.OpenNotesRegex)
 		}
 		openCustomRegex := map[string]*regexp.Regexp{}
 		for k, v := range a.Cfg.OpenCustomRegex {
 			re, err := regexp.Compile(v)
 			if err != nil {
-				fmt.Println("Error compiling regex. ", k, "=", v)
-				os.Exit(1)
+				return newError(ErrBadConfig, "Error compiling regex. %s = %s", k, v)
 			}
 			openCustomRegex[k] = re
 		}
@@ -668,17 +1668,17 @@ func (a *App) Open(c *CommandImpl) {
 					continue
 				}
 				if verbose {
-					println("Todo Uuid: " + todo.Uuid)
-					println("Todo Note: '" + note + "'")
-					println("Open file type: notes")
-					println("Open regex: '" + a.Cfg.OpenNotesRegex + "'")
+					fmt.Println("Todo Uuid: " + todo.Uuid)
+					fmt.Println("Todo Note: '" + note + "'")
+					fmt.Println("Open file type: notes")
+					fmt.Println("Open regex: '" + a.Cfg.OpenNotesRegex + "'")
 				}
 				if openNotesRegex.MatchString(strings.TrimSpace(note)) {
 					if verbose {
 						if a.Cfg.OpenNotesCmd != "" {
-							println("Matched notes regex. Opening notes with command '" + a.Cfg.OpenNotesCmd + "'")
+							fmt.Println("Matched notes regex. Opening notes with command '" + a.Cfg.OpenNotesCmd + "'")
 						} else {
-							println("Matched notes regex. Opening notes with default command")
+							fmt.Println("Matched notes regex. Opening notes with default command")
 						}
 					}
 					//a.openNotes(todo.Uuid, a.Cfg.OpenNotesCmd)
@@ -702,21 +1702,21 @@ func (a *App) Open(c *CommandImpl) {
 
 					for k, v := range openCustomRegex {
 						if verbose {
-							println("Open regex not matched.")
-							println("Open file type: " + k)
-							println("Open regex: '" + a.Cfg.OpenCustomRegex[k] + "'")
+							fmt.Println("Open regex not matched.")
+							fmt.Println("Open file type: " + k)
+							fmt.Println("Open regex: '" + a.Cfg.OpenCustomRegex[k] + "'")
 						}
 						if matches := v.FindStringSubmatch(note); len(matches) > 0 {
 							if cmd, ok := openCustomCmd[k]; ok {
 								if verbose {
-									println("Opening file " + matches[1] + " with command '" + openCustomCmd[k] + "'")
+									fmt.Println("Opening file " + matches[1] + " with command '" + openCustomCmd[k] + "'")
 								}
 								//a.openUriWithCmd(matches[1], cmd)
 								//openTasks = append(openTasks, NewOpenTask(todo, noteIndex, k, matches[1], cmd))
 								task = NewOpenTask(todo, noteIndex, k, matches[1], cmd)
 							} else {
 								if verbose {
-									println("Opening file: " + matches[1] + " with default command")
+									fmt.Println("Opening file: " + matches[1] + " with default command")
 								}
 								//a.openUri(matches[1])
 								//openTasks = append(openTasks, NewOpenTask(todo, noteIndex, k, matches[1], ""))
@@ -729,7 +1729,7 @@ func (a *App) Open(c *CommandImpl) {
 							}
 						} else {
 							if verbose {
-								println("Open regex not matched.")
+								fmt.Println("Open regex not matched.")
 							}
 						}
 					}
@@ -745,7 +1745,7 @@ func (a *App) Open(c *CommandImpl) {
 		if len(openTasks) > 1 {
 			task = selectOpenTaskInput(openTasks)
 			if task == nil {
-				return
+				return nil
 			}
 			//Else just open it
 		} else if len(openTasks) > 0 {
@@ -754,26 +1754,29 @@ func (a *App) Open(c *CommandImpl) {
 
 		//Below is a workaround because browser not opening on Windows 10 with openUri function.
 		if isBrowserTask {
-			task.Uri = a.EncodeUri(task.Uri)
+			if task.Uri, err = a.EncodeUri(task.Uri); err != nil {
+				return err
+			}
 			if verbose {
-				println("Opening URL: " + task.Uri + " with preferred browser command")
+				fmt.Println("Opening URL: " + task.Uri + " with preferred browser command")
+			}
+			if err := a.openbrowser(task.Uri); err != nil {
+				return err
 			}
-			a.openbrowser(task.Uri)
 		}
 
 		//Open the note
 		if task != nil {
 			if task.Uri == "notes" {
-				a.openNotes(task.Todo.Uuid, task.Cmd)
+				return a.openNotes(task.Todo.Uuid, task.Cmd)
+			} else if task.Cmd == "" {
+				return a.openUri(task.Uri)
 			} else {
-				if task.Cmd == "" {
-					a.openUri(task.Uri)
-				} else {
-					a.openUriWithCmd(task.Uri, task.Cmd)
-				}
+				return a.openUriWithCmd(task.Uri, task.Cmd)
 			}
 		}
 	}
+	return nil
 }
 
 func selectOpenTaskInput(openTasks []*OpenTask) *OpenTask {
@@ -799,65 +1802,59 @@ func selectOpenTaskInput(openTasks []*OpenTask) *OpenTask {
 	//return strings.TrimSpace(password)
 }
 
-func (a *App) openUri(uri string) {
-	err := open.Start(uri)
-	if err != nil {
-		println("Error opening uri " + uri + ": " + err.Error())
+func (a *App) openUri(uri string) error {
+	if err := open.Start(uri); err != nil {
+		return fmt.Errorf("Error opening uri %s: %w", uri, err)
 	}
+	return nil
 }
 
-func (a *App) openUriWithCmd(uri string, cmd string) {
-	err := open.StartWith(uri, cmd)
-	if err != nil {
-		println("Error opening uri " + uri + ": " + err.Error())
+func (a *App) openUriWithCmd(uri string, cmd string) error {
+	if err := open.StartWith(uri, cmd); err != nil {
+		return fmt.Errorf("Error opening uri %s: %w", uri, err)
 	}
+	return nil
 }
 
 //Workaround because skratchdot open-golang open package isn't working on windows for
 //opening the browser.
-func (a *App) openbrowser(url string) {
-	var err error
+func (a *App) openbrowser(url string) error {
 	if runtime.GOOS == "windows" {
-		err = exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
-	}
-	if err != nil {
-		log.Fatal(err)
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
 	}
+	return nil
 }
 
-func (a *App) openNotes(uuid string, cmd string) {
+func (a *App) openNotes(uuid string, cmd string) error {
 	notesDir := a.Cfg.OpenNotesFolder
 	notes := fmt.Sprintf("%s/%s_notes"+a.Cfg.OpenNotesExt, notesDir, uuid)
 	if _, err := os.Stat(notesDir); os.IsNotExist(err) {
 		fmt.Println("Notes directory does not exist. Creating.")
-		err2 := os.MkdirAll(notesDir, 0755)
-		if err2 != nil {
-			fmt.Println("Error creating notes directory: ", err2.Error())
+		if err := os.MkdirAll(notesDir, 0755); err != nil {
+			return fmt.Errorf("Error creating notes directory: %w", err)
 		}
 	}
 	if _, err := os.Stat(notes); os.IsNotExist(err) {
 		fmt.Println("File does not exist. Creating.")
-		fd, err2 := os.OpenFile(notes, os.O_CREATE, 0644)
-		if err2 != nil {
-			fmt.Println("Error creating notes file: ", err2.Error())
+		fd, err := os.OpenFile(notes, os.O_CREATE, 0644)
+		if err != nil {
+			return fmt.Errorf("Error creating notes file: %w", err)
 		}
 		fd.Close()
 	}
 	if cmd != "" {
-		a.openUriWithCmd(notes, cmd)
-	} else {
-		a.openUri(notes)
+		return a.openUriWithCmd(notes, cmd)
 	}
+	return a.openUri(notes)
 }
 
 //This only works for web urls
 //Still have a challenge with Windows files and paths with spaces
 //Not using in openUri function above.
-func (a *App) EncodeUri(uri string) string {
+func (a *App) EncodeUri(uri string) (string, error) {
 	eUri, err := url.Parse(uri)
 	if err != nil {
-		fmt.Printf("Error parsing uri: '%s'. Error: %s\n", uri, err.Error())
-		os.Exit(1)
+		return "", newError(ErrBadInput, "Error parsing uri: '%s'. Error: %s", uri, err.Error())
 	}
 
 	encoded := ""
@@ -883,7 +1880,7 @@ func (a *App) EncodeUri(uri string) string {
 		encoded += "?" + eUri.Query().Encode()
 	}
 
-	return encoded
+	return encoded, nil
 }
 
 /*
@@ -921,10 +1918,17 @@ func (a *App) UnspaceUri(uri string) string {
 }
 */
 
-func (a *App) PrintHelp(c *CommandImpl) {
-	p := NewScreenPrinter()
+func (a *App) PrintHelp(c *CommandImpl) error {
+	p := NewScreenPrinter(a.Clock, a.Cfg.Colors)
 	if len(c.Args) == 0 {
-		p.PrintOverallHelp()
+		//Commands and aliases hide plugins of the same name
+		plugins := ListPlugins(a.Cfg.PluginsDir)
+		for name := range plugins {
+			if _, found := a.CommandMap[name]; found {
+				delete(plugins, name)
+			}
+		}
+		p.PrintOverallHelp(plugins)
 	} else {
 		for _, arg := range c.Args {
 			switch arg {
@@ -976,6 +1980,28 @@ func (a *App) PrintHelp(c *CommandImpl) {
 				p.PrintProjectsHelp()
 			case "contexts":
 				p.PrintContextsHelp()
+			case "tags":
+				p.PrintTagsHelp()
+			case "next":
+				p.PrintNextHelp()
+			case "start", "stop":
+				p.PrintStartHelp()
+			case "edit-full", "e!":
+				p.PrintEditFullHelp()
+			case "tui":
+				p.PrintTuiHelp()
+			case "pomo":
+				p.PrintPomodoroHelp()
+			case "timesheet", "ts":
+				p.PrintTimesheetHelp()
+			case "batch":
+				p.PrintBatchHelp()
+			case "calendar", "cal":
+				p.PrintCalendarHelp()
+			case "agenda":
+				p.PrintAgendaHelp()
+			case "remind":
+				p.PrintRemindHelp()
 			case "print":
 				p.PrintPrintTodoDetailHelp()
 			case "view":
@@ -984,6 +2010,14 @@ func (a *App) PrintHelp(c *CommandImpl) {
 				p.PrintInitHelp()
 			case "config":
 				p.PrintConfigHelp()
+			case "hooks":
+				p.PrintHooksHelp()
+			case "plugins":
+				p.PrintPluginsHelp()
+			case "repos", "repo":
+				p.PrintReposHelp()
+			case "aliases", "alias":
+				p.PrintAliasesHelp(a.aliasHelp())
 			case "open":
 				p.PrintOpenHelp()
 			case "stats":
@@ -995,9 +2029,30 @@ func (a *App) PrintHelp(c *CommandImpl) {
 			}
 		}
 	}
+	return nil
+}
+
+//Each alias by name, with its definition and what it expands to (or why it can't be expanded)
+func (a *App) aliasHelp() [][]string {
+	names := []string{}
+	for name, cmd := range a.CommandMap {
+		if _, isAlias := cmd.(*AliasCmd); isAlias {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	rows := [][]string{}
+	for _, name := range names {
+		expansion, err := a.AliasExpansion(name, nil)
+		if err != nil {
+			expansion = err.Error()
+		}
+		rows = append(rows, []string{name, a.CommandMap[name].GetCmd(), expansion})
+	}
+	return rows
 }
 
-func (a *App) ExecAlias(c *CommandImpl) {
+func (a *App) ExecAlias(c *AliasCmd) error {
 	//Parse the command line into command plus mods
 	//TODO - Pull command line parsing into function in todolist package. Call from todo.go and from here to process
 	//the alias with the full logic of parsing a command line. Then combine original contents from command line and
@@ -1008,7 +2063,19 @@ func (a *App) ExecAlias(c *CommandImpl) {
 	//Process the config value (c.Cmd) as a new command line
 	//Then add the filters, mods and args from this AliasCmd back to it before calling Exec()
 
-	origCmd := a.ProcessCmdLine(c.Cmd)
+	//Aliases may use other aliases. Track those being run, as a loop would never end.
+	a.aliases = append(a.aliases, c.Name)
+	defer func() { a.aliases = a.aliases[:len(a.aliases)-1] }()
+	if err := aliasLoop(a.aliases); err != nil {
+		return err
+	}
+	//The words after the alias fill in $1..$n and $@. Those not used are added to the command.
+	cmdLine, rest, err := substituteAliasParams(c.Name, c.Cmd, c.Mods, false)
+	if err != nil {
+		return err
+	}
+	c.Mods = rest
+	origCmd := a.ProcessCmdLine(cmdLine)
 
 	//Parsing of Alias will assume command accepts mods. Now that we know the original command, if it doesn't accept mods,
 	//those mods should be treated as filters.
@@ -1045,17 +2112,15 @@ func (a *App) ExecAlias(c *CommandImpl) {
 		fmt.Println("Mods: ", origCmd.GetMods())
 		fmt.Println("Args: ", origCmd.GetArgs())
 	*/
-	cmd := origCmd.GetCmd()
-	if (cmd == "edit" || cmd == "archive" || cmd == "delete" || cmd == "complete") && len(origCmd.GetFilters()) == 0 {
-		fmt.Println("Destructive operations require a filter. None specified. Aborting.")
-		return
+	if IsMissingRequiredFilter(origCmd) {
+		return ErrMissingFilter
 	}
 	//Execute the original command. Only one command executed at a time, so no worries about over-writing origCmd mods, filters, etc.
-	origCmd.Exec(a)
+	return origCmd.Exec(a)
 }
 
 type Command interface {
-	Exec(a *App)
+	Exec(a *App) error
 	GetCmd() string
 	SetCmd(cmd string)
 	GetFilters() []string
@@ -1070,7 +2135,7 @@ type Command interface {
 	SetAcceptsArgs(acceptsArgs bool)
 }
 
-func NewCommand(cmd string, iam bool, iaa bool, ef func(c *CommandImpl)) *CommandImpl {
+func NewCommand(cmd string, iam bool, iaa bool, ef func(c *CommandImpl) error) *CommandImpl {
 	c := CommandImpl{
 		Cmd:          cmd,
 		IsAcceptMods: iam,
@@ -1089,11 +2154,15 @@ type CommandImpl struct {
 	Args         []string
 	IsAcceptMods bool
 	IsAcceptArgs bool
-	ExecFunc     func(c *CommandImpl)
+	ExecFunc     func(c *CommandImpl) error
 }
 
-func (c *CommandImpl) Exec(a *App) {
-	c.ExecFunc(c)
+func (c *CommandImpl) Exec(a *App) error {
+	//Only reports run across repos (see ReportCmd.Exec). Don't let another command act on this repo instead.
+	if value, _ := splitRepoFilter(c.Filters); value != "" {
+		return newError(ErrBadInput, "Error: repo:%s only works with list and other reports. Use --repo=%s to run %s in another repo", value, value, c.Cmd)
+	}
+	return c.ExecFunc(c)
 }
 
 func (c *CommandImpl) GetCmd() string {
@@ -1142,23 +2211,45 @@ type ReportCmd struct {
 	SavedReport *Report
 }
 
-func (c *ReportCmd) Exec(a *App) {
-	c.SavedReport.Filters = append(c.SavedReport.Filters, c.Filters...)
+func (c *ReportCmd) Exec(a *App) error {
+	//repo:<name>,<name> or repo:all runs the report in each of those repos
+	if value, filters := splitRepoFilter(c.Filters); value != "" {
+		repos, err := a.selectRepos(value)
+		if err != nil {
+			return err
+		}
+		c.Filters = filters
+		return a.execReportInRepos(c, repos)
+	}
+	//Run a copy, so the command's filters and args don't stay on the saved report for the next command in a batch
+	report := *c.SavedReport
+	report.Filters = append(append([]string{}, c.SavedReport.Filters...), c.Filters...)
 	filterArchived := false
-	for _, f := range c.SavedReport.Filters {
+	for _, f := range report.Filters {
 		if f == "archived" {
 			filterArchived = true
 		}
 	}
 	if filterArchived {
-		a.LoadArchived()
+		if err := a.LoadArchived(); err != nil {
+			return err
+		}
 	} else {
-		a.LoadPending()
-		if a.TodoList.ExpireTodos() {
-			a.LoadArchived()
-			a.Save()
-			a.TodoList.Data = []*Todo{}
-			a.LoadPending()
+		if err := a.LoadPending(); err != nil {
+			return err
+		}
+		//The archived todos loaded to save the expired ones are left out of the report below
+		expired, err := a.TodoList.ExpireTodos()
+		if err != nil {
+			return err
+		}
+		if expired {
+			if err := a.LoadArchived(); err != nil {
+				return err
+			}
+			if err := a.Save(); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -1167,33 +2258,53 @@ func (c *ReportCmd) Exec(a *App) {
 	// sort:<replace sorting> - Modify sorting
 	// filter:<replace filters>
 	// group:<replace group>
+	// output:<json|csv|tsv|md|html> - Print data for other programs or a document
+	// file:<name> - Write the output to a file
+	// checklist - Document (md or html) lists todos as checklists rather than tables
+	// wrap:<bool> - Wrap rather than truncate columns too wide for the terminal
 	groupBy := ""
-	for _, arg := range c.Args {
+	printer, args, done, err := a.selectPrinter(c.Args)
+	if err != nil {
+		return err
+	}
+	for _, arg := range args {
 		if strings.HasPrefix(arg, "notes:") {
-			c.SavedReport.PrintNotes, _ = strconv.ParseBool(arg[6:])
+			report.PrintNotes, _ = strconv.ParseBool(arg[6:])
 		} else if strings.HasPrefix(arg, "sort:") {
 			sorts := strings.Split(arg[5:], ",")
-			c.SavedReport.Sorter = NewTodoSorter(sorts...)
+			report.Sorter = NewTodoSorter(sorts...)
 		} else if strings.HasPrefix(arg, "filter:") {
-			c.SavedReport.Filters = strings.Split(arg[7:], ",")
+			report.Filters = strings.Split(arg[7:], ",")
 		} else if strings.HasPrefix(arg, "group:") {
 			groupBy = strings.TrimSpace(arg[6:])
+		} else if arg == "explain" {
+			report.Explain = true
+		} else if arg == "checklist" {
+			report.Checklist = true
+		} else if strings.HasPrefix(arg, "wrap:") {
+			report.Wrap, _ = strconv.ParseBool(arg[5:])
 		}
 	}
 
 	//Ensure do grouping last, since it modifies the sort that may have been modified above
 	if groupBy != "" {
-		c.SavedReport.Group = groupBy
-		if c.SavedReport.Group != "" {
-			sorts := c.SavedReport.Sorter.SortColumns
-			if !strings.Contains(sorts[0], c.SavedReport.Group) {
-				sorts = append([]string{c.SavedReport.Group}, sorts...)
-				c.SavedReport.Sorter = NewTodoSorter(sorts...)
+		report.Group = groupBy
+		if report.Group != "" {
+			sorts := report.Sorter.SortColumns
+			if !strings.Contains(sorts[0], report.Group) {
+				sorts = append([]string{report.Group}, sorts...)
+				report.Sorter = NewTodoSorter(sorts...)
 			}
 		}
 	}
+	//Rank by the configured priorities and urgency coefficients
+	report.Sorter = report.Sorter.ranked(a.Cfg.Priority, a.Cfg.UrgencyCoefficients)
 	//pass report and slice of todos to printer to print the columns and headers
-	a.Printer.PrintReport(c.SavedReport, a.TodoList.Todos())
+	todos := a.TodoList.Todos()
+	if !filterArchived {
+		todos = NewToDoFilter(todos, a.Clock).getUnarchived()
+	}
+	return finishPrint(printer.PrintReport(&report, todos), done)
 }
 
 //Create command instances, map command text to required app function
@@ -1217,6 +2328,11 @@ func (a *App) mapCommands() {
 	a.CommandMap["l"] = listCmd
 	a.CommandMap["list"] = listCmd
 
+	//Built-in 'next' report, with any report.next.* settings, unless the user configured an alias by that name
+	if _, exists := a.CommandMap["next"]; !exists {
+		a.AddReportCommand("next", NewNextReport(goterm.Width(), a.Cfg.Reports["next"]))
+	}
+
 	addCmd := NewCommand("add", true, false, a.AddTodo)
 	a.CommandMap["a"] = addCmd
 	a.CommandMap["add"] = addCmd
@@ -1225,6 +2341,12 @@ func (a *App) mapCommands() {
 	a.CommandMap["done"] = doneCmd
 
 	//Added isAcceptsArgs = true to avoid values to right being interpeted as filters.
+	startCmd := NewCommand("start", false, false, a.StartTodo)
+	a.CommandMap["start"] = startCmd
+
+	stopCmd := NewCommand("stop", false, false, a.StopTodo)
+	a.CommandMap["stop"] = stopCmd
+
 	deleteCmd := NewCommand("delete", false, true, a.DeleteTodo)
 	a.CommandMap["d"] = deleteCmd
 
@@ -1256,6 +2378,10 @@ func (a *App) mapCommands() {
 	a.CommandMap["e"] = editCmd
 	a.CommandMap["edit"] = editCmd
 
+	editFullCmd := NewCommand("edit-full", false, false, a.EditFull)
+	a.CommandMap["edit-full"] = editFullCmd
+	a.CommandMap["e!"] = editFullCmd
+
 	touchCmd := NewCommand("touch", false, false, a.TouchTodo)
 	a.CommandMap["t"] = touchCmd
 	a.CommandMap["touch"] = touchCmd
@@ -1269,6 +2395,12 @@ func (a *App) mapCommands() {
 	viewCmd := NewCommand("view", true, false, a.SetView)
 	a.CommandMap["view"] = viewCmd
 
+	configCmd := NewCommand("config", false, true, a.ManageConfig)
+	a.CommandMap["config"] = configCmd
+
+	reposCmd := NewCommand("repos", false, false, a.ListRepos)
+	a.CommandMap["repos"] = reposCmd
+
 	webCmd := NewCommand("web", false, false, a.NewWebApp)
 	a.CommandMap["web"] = webCmd
 
@@ -1293,6 +2425,9 @@ func (a *App) mapCommands() {
 	contextsCmd := NewCommand("contexts", false, false, a.ListContexts)
 	a.CommandMap["contexts"] = contextsCmd
 
+	tagsCmd := NewCommand("tags", false, false, a.ListTags)
+	a.CommandMap["tags"] = tagsCmd
+
 	printCmd := NewCommand("print", false, false, a.PrintTodoDetail)
 	a.CommandMap["print"] = printCmd
 
@@ -1308,10 +2443,33 @@ func (a *App) mapCommands() {
 	statsCmd := NewCommand("stats", true, false, a.Stats)
 	a.CommandMap["stats"] = statsCmd
 
+	tuiCmd := NewCommand("tui", false, true, a.Tui)
+	a.CommandMap["tui"] = tuiCmd
+
+	pomoCmd := NewCommand("pomo", false, false, a.Pomodoro)
+	a.CommandMap["pomo"] = pomoCmd
+
+	timesheetCmd := NewCommand("timesheet", true, false, a.Timesheet)
+	a.CommandMap["timesheet"] = timesheetCmd
+	a.CommandMap["ts"] = timesheetCmd
+
+	calendarCmd := NewCommand("calendar", false, false, a.Calendar)
+	a.CommandMap["calendar"] = calendarCmd
+	a.CommandMap["cal"] = calendarCmd
+
+	agendaCmd := NewCommand("agenda", false, false, a.Agenda)
+	a.CommandMap["agenda"] = agendaCmd
+
+	remindCmd := NewCommand("remind", false, true, a.Remind)
+	a.CommandMap["remind"] = remindCmd
+
 	importCmd := NewCommand("import", false, true, a.ImportTodo)
 	a.CommandMap["imp"] = importCmd
 	a.CommandMap["import"] = importCmd
 
+	batchCmd := NewCommand("batch", false, true, a.Batch)
+	a.CommandMap["batch"] = batchCmd
+
 	exportCmd := NewCommand("export", false, true, a.ExportTodo)
 	a.CommandMap["exp"] = exportCmd
 	a.CommandMap["export"] = exportCmd
@@ -1,136 +1,124 @@
 package todolist
 
 import (
-	"fmt"
-	"strconv"
+	"bytes"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
+func newTestApp() *App {
+	return newBatchApp(&recordingStore{}, &bytes.Buffer{})
+}
+
 func TestAddTodo(t *testing.T) {
 	assert := assert.New(t)
-	app := &App{TodoList: &TodoList{}, TodoStore: &MemoryStore{}}
-	year := strconv.Itoa(Now.Year())
+	app := newTestApp()
 
-	app.AddTodo("a do some stuff due may 23")
+	assert.Nil(app.AddTodo(&CommandImpl{Mods: []string{"do", "some", "stuff", "due:2026-05-23"}}))
 
 	todo := app.TodoList.FindById(1)
 	assert.Equal("do some stuff", todo.Subject)
-	assert.Equal(fmt.Sprintf("%s-05-23", year), todo.Due)
+	assert.Equal(timeToString(time.Date(2026, 5, 23, 0, 0, 0, 0, time.UTC)), todo.Due)
 	assert.Equal(false, todo.Completed)
-	assert.Equal(false, todo.Archived)
-	assert.Equal(false, todo.IsPriority)
+	assert.Equal("Pending", todo.Status)
+	assert.Equal("", todo.Priority)
 	assert.Equal("", todo.CompletedDate)
-	assert.Equal([]string{}, todo.Projects)
-	assert.Equal([]string{}, todo.Contexts)
+	assert.Empty(todo.Projects)
+	assert.Empty(todo.Contexts)
 }
 
 func TestAddDoneTodo(t *testing.T) {
 	assert := assert.New(t)
-	app := &App{TodoList: &TodoList{}, TodoStore: &MemoryStore{}}
+	app := newTestApp()
 
-	app.AddDoneTodo("Groked how to do done todos @pop")
+	assert.Nil(app.AddDoneTodo(&CommandImpl{Mods: []string{"Groked", "how", "to", "do", "done", "todos", "@pop"}}))
 
 	todo := app.TodoList.FindById(1)
-	assert.Equal("Groked how to do done todos @pop", todo.Subject)
+	assert.Equal("Groked how to do done todos", todo.Subject)
 	assert.Equal(true, todo.Completed)
-	assert.Equal(false, todo.Archived)
-	assert.Equal(false, todo.IsPriority)
-	assert.Equal([]string{}, todo.Projects)
-	assert.Equal(1, len(todo.Contexts))
-	assert.Equal("pop", todo.Contexts[0])
-}
-
-func TestAddTodoWithEuropeanDates(t *testing.T) {
-	assert := assert.New(t)
-	app := &App{TodoList: &TodoList{}, TodoStore: &MemoryStore{}}
-	year := strconv.Itoa(Now.Year())
-
-	app.AddTodo("a do some stuff due 23 may")
-
-	todo := app.TodoList.FindById(1)
-	assert.Equal("do some stuff", todo.Subject)
-	assert.Equal(fmt.Sprintf("%s-05-23", year), todo.Due)
-	assert.Equal(false, todo.Completed)
-	assert.Equal(false, todo.Archived)
-	assert.Equal(false, todo.IsPriority)
-	assert.Equal("", todo.CompletedDate)
-	assert.Equal([]string{}, todo.Projects)
-	assert.Equal([]string{}, todo.Contexts)
+	assert.Equal("Pending", todo.Status)
+	assert.Equal("", todo.Priority)
+	assert.Empty(todo.Projects)
+	assert.Equal([]string{"pop"}, todo.Contexts)
 }
 
 func TestAddEmptyTodo(t *testing.T) {
 	assert := assert.New(t)
-	app := &App{TodoList: &TodoList{}, TodoStore: &MemoryStore{}}
+	app := newTestApp()
 
-	app.AddTodo("a")
-	app.AddTodo("a      ")
-	app.AddTodo("a\t\t\t\t")
-	app.AddTodo("a\t \t  \t   \t")
+	err := app.AddTodo(&CommandImpl{Mods: []string{}})
 
-	assert.Equal(len(app.TodoList.Data), 0)
+	assert.True(errors.Is(err, ErrBadInput))
+	assert.Equal(0, len(app.TodoList.Data))
 }
 
 func TestListbyProject(t *testing.T) {
 	assert := assert.New(t)
-	app := &App{TodoList: &TodoList{}, TodoStore: &MemoryStore{}}
-	app.Load()
+	app := newTestApp()
 
 	// create three todos w/wo a project
-	app.AddTodo("this is a test +testme")
-	app.AddTodo("this is a test +testmetoo @work")
-	app.AddTodo("this is a test with no projects")
-	app.CompleteTodo("c 1")
-
-	// simulate listTodos
-	input := "l by p"
-	filtered := NewFilter(app.TodoList.Todos()).Filter(input)
-	grouped := app.getGroups(input, filtered)
-
-	assert.Equal(3, len(grouped.Groups))
+	app.AddTodo(&CommandImpl{Mods: []string{"this", "is", "a", "test", "+testme"}})
+	app.AddTodo(&CommandImpl{Mods: []string{"this", "is", "a", "test", "+testmetoo", "@work"}})
+	app.AddTodo(&CommandImpl{Mods: []string{"this", "is", "a", "test", "with", "no", "projects"}})
+	app.CompleteTodo(&CommandImpl{Filters: []string{"1"}})
+
+	report := &Report{Sorter: NewTodoSorter("project"), Group: "project"}
+	groups, _, err := reportGroups(report, app.TodoList.Todos(), app.Clock)
+	assert.Nil(err)
+
+	grouped := map[string][]*Todo{}
+	for _, group := range groups {
+		grouped[group.Name] = group.Todos
+	}
+	assert.Equal(3, len(grouped))
 
 	// testme project has 1 todo and its completed
-	assert.Equal(1, len(grouped.Groups["testme"]))
-	assert.Equal(true, grouped.Groups["testme"][0].Completed)
+	assert.Equal(1, len(grouped["testme"]))
+	assert.Equal(true, grouped["testme"][0].Completed)
 
 	// testmetoo project has 1 todo and it has a context
-	assert.Equal(1, len(grouped.Groups["testmetoo"]))
-	assert.Equal(1, len(grouped.Groups["testmetoo"][0].Contexts))
-	assert.Equal("work", grouped.Groups["testmetoo"][0].Contexts[0])
+	assert.Equal(1, len(grouped["testmetoo"]))
+	assert.Equal(1, len(grouped["testmetoo"][0].Contexts))
+	assert.Equal("work", grouped["testmetoo"][0].Contexts[0])
+
+	assert.Equal(1, len(grouped["No project"]))
 }
 
 func TestListbyContext(t *testing.T) {
 	assert := assert.New(t)
-	app := &App{TodoList: &TodoList{}, TodoStore: &MemoryStore{}}
-	app.Load()
+	app := newTestApp()
 
 	// create three todos w/wo a context
-	app.AddTodo("this is a test +testme")
-	app.AddTodo("this is a test +testmetoo @work")
-	app.AddTodo("this is a test with no projects")
-	app.CompleteTodo("c 1")
-
-	// simulate listTodos
-	input := "l by c"
-	filtered := NewFilter(app.TodoList.Todos()).Filter(input)
-	grouped := app.getGroups(input, filtered)
-
-	assert.Equal(2, len(grouped.Groups))
+	app.AddTodo(&CommandImpl{Mods: []string{"this", "is", "a", "test", "+testme"}})
+	app.AddTodo(&CommandImpl{Mods: []string{"this", "is", "a", "test", "+testmetoo", "@work"}})
+	app.AddTodo(&CommandImpl{Mods: []string{"this", "is", "a", "test", "with", "no", "projects"}})
+	app.CompleteTodo(&CommandImpl{Filters: []string{"1"}})
+
+	report := &Report{Sorter: NewTodoSorter("context"), Group: "context"}
+	groups, _, err := reportGroups(report, app.TodoList.Todos(), app.Clock)
+	assert.Nil(err)
+
+	grouped := map[string][]*Todo{}
+	for _, group := range groups {
+		grouped[group.Name] = group.Todos
+	}
+	assert.Equal(2, len(grouped))
 
 	// work context has 1 todo and it has a project of testmetoo
-	assert.Equal(1, len(grouped.Groups["work"]))
-	assert.Equal(1, len(grouped.Groups["work"][0].Projects))
-	assert.Equal("testmetoo", grouped.Groups["work"][0].Projects[0])
+	assert.Equal(1, len(grouped["work"]))
+	assert.Equal(1, len(grouped["work"][0].Projects))
+	assert.Equal("testmetoo", grouped["work"][0].Projects[0])
 
 	// There are two todos with no context
-	assert.Equal(2, len(grouped.Groups["No contexts"]))
+	assert.Equal(2, len(grouped["No context"]))
 
 	// check to see if the a todos with no context contain a
 	// completed todo
 	var hasACompletedTodo bool
-	for _, todo := range grouped.Groups["No contexts"] {
+	for _, todo := range grouped["No context"] {
 		if todo.Completed {
 			hasACompletedTodo = true
 		}
@@ -140,29 +128,29 @@ func TestListbyContext(t *testing.T) {
 
 func TestGetId(t *testing.T) {
 	assert := assert.New(t)
-	app := &App{TodoList: &TodoList{}, TodoStore: &MemoryStore{}}
+	filter := NewToDoFilter([]*Todo{}, FixedClock{})
 	// not a valid id
-	assert.Equal(-1, app.getId("p"))
+	assert.Equal(-1, filter.getId("p"))
 	// a single digit id
-	assert.Equal(6, app.getId("6"))
+	assert.Equal(6, filter.getId("6"))
 	// a double digit id
-	assert.Equal(66, app.getId("66"))
+	assert.Equal(66, filter.getId("66"))
 }
 
 func TestGetIds(t *testing.T) {
 	assert := assert.New(t)
-	app := &App{TodoList: &TodoList{}, TodoStore: &MemoryStore{}}
+	filter := NewToDoFilter([]*Todo{}, FixedClock{})
 	// no valid id here
-	assert.Equal(0, len(app.getIds("p")))
+	assert.Equal(0, len(filter.getIds("p")))
 	// one valid value here
-	assert.Equal([]int{6}, app.getIds("6"))
+	assert.Equal([]int{6}, filter.getIds("6"))
 	// lots of single post numbers
-	assert.Equal([]int{6, 10, 8, 4}, app.getIds("6,10,8,4"))
+	assert.Equal([]int{6, 10, 8, 4}, filter.getIds("6,10,8,4"))
 	// a correct range
-	assert.Equal([]int{6, 7, 8}, app.getIds("6-8"))
+	assert.Equal([]int{6, 7, 8}, filter.getIds("6-8"))
 	// some incorrect ranges
-	assert.Equal(0, len(app.getIds("6-6")))
-	assert.Equal(0, len(app.getIds("8-6")))
+	assert.Equal(0, len(filter.getIds("6-6")))
+	assert.Equal(0, len(filter.getIds("8-6")))
 	// some compsite ranges
-	assert.Equal([]int{5, 6, 7, 8, 10, 11, 9}, app.getIds("5,6-8,10-11,9"))
+	assert.Equal([]int{5, 6, 7, 8, 10, 11, 9}, filter.getIds("5,6-8,10-11,9"))
 }
@@ -0,0 +1,113 @@
+package todolist
+
+//Store used by the batch command. Loads from the underlying store once and keeps all changes
+//in memory until Commit, so a batch of commands is saved all at once or not at all.
+type BatchStore struct {
+	Store
+	pendingLoaded  bool
+	archivedLoaded bool
+	todos          []*Todo        //Every todo loaded or saved by the batch
+	returned       map[*Todo]bool //The todos loaded by the current command
+	modified       map[*Todo]bool
+	isSaved        bool
+}
+
+func NewBatchStore(store Store) *BatchStore {
+	return &BatchStore{Store: store, returned: map[*Todo]bool{}, modified: map[*Todo]bool{}}
+}
+
+//Start the next command of the batch. It loads the todos again, as they are after the commands before it.
+func (b *BatchStore) Next() {
+	b.returned = map[*Todo]bool{}
+}
+
+//The pending todos, loaded from the underlying store on the first call. Later calls by the same command return
+//nothing, so they are not loaded twice. Later commands get them as the batch left them.
+func (b *BatchStore) LoadPending() ([]*Todo, error) {
+	if !b.pendingLoaded {
+		todos, err := b.Store.LoadPending()
+		if err != nil {
+			return nil, err
+		}
+		b.pendingLoaded = true
+		b.add(todos)
+	}
+	return b.load("Pending"), nil
+}
+
+func (b *BatchStore) LoadArchived() ([]*Todo, error) {
+	if !b.archivedLoaded {
+		todos, err := b.Store.LoadArchived()
+		if err != nil {
+			return nil, err
+		}
+		b.archivedLoaded = true
+		b.add(todos)
+	}
+	return b.load("Archived"), nil
+}
+
+//The todos with the status not yet loaded by the current command
+func (b *BatchStore) load(status string) []*Todo {
+	todos := []*Todo{}
+	for _, todo := range b.todos {
+		if todo.Status == status && !b.returned[todo] {
+			b.returned[todo] = true
+			todos = append(todos, todo)
+		}
+	}
+	return todos
+}
+
+//Add the todos not in the batch already
+func (b *BatchStore) add(todos []*Todo) {
+	known := map[*Todo]bool{}
+	for _, todo := range b.todos {
+		known[todo] = true
+	}
+	for _, todo := range todos {
+		if !known[todo] {
+			b.todos = append(b.todos, todo)
+		}
+	}
+}
+
+//Hold the todos until Commit. Remember which were modified, since App.Save resets IsModified after each save.
+//The todos saved come first, in their order, then those the command didn't load.
+func (b *BatchStore) Save(todos []*Todo) error {
+	saved := map[*Todo]bool{}
+	for _, todo := range todos {
+		saved[todo] = true
+	}
+	all := append([]*Todo{}, todos...)
+	for _, todo := range b.todos {
+		if !saved[todo] {
+			all = append(all, todo)
+		}
+	}
+	b.todos = all
+	b.isSaved = true
+	for _, todo := range todos {
+		b.returned[todo] = true
+		if todo.IsModified {
+			b.modified[todo] = true
+		}
+	}
+	return nil
+}
+
+//Number of todos added or changed by the batch
+func (b *BatchStore) Modified() int {
+	return len(b.modified)
+}
+
+//Write the todos changed by the batch to the underlying store
+func (b *BatchStore) Commit() error {
+	if !b.isSaved {
+		return nil
+	}
+	for todo := range b.modified {
+		todo.IsModified = true
+	}
+	return b.Store.Save(b.todos)
+}
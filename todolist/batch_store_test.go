@@ -0,0 +1,160 @@
+package todolist
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//Store that counts loads and saves. Methods not used by the batch are left to the nil embedded Store.
+type recordingStore struct {
+	Store
+	pending  []*Todo
+	archived []*Todo
+	loads    int
+	saves    int
+	saved    []*Todo
+	modified []*Todo
+}
+
+func (s *recordingStore) LoadArchived() ([]*Todo, error) { return s.archived, nil }
+func (s *recordingStore) LoadPending() ([]*Todo, error) {
+	s.loads++
+	return s.pending, nil
+}
+func (s *recordingStore) Save(todos []*Todo) error {
+	s.saves++
+	s.saved = todos
+	for _, todo := range todos {
+		if todo.IsModified {
+			s.modified = append(s.modified, todo)
+		}
+	}
+	return nil
+}
+
+func TestBatchStore(t *testing.T) {
+	assert := assert.New(t)
+	store := &recordingStore{pending: []*Todo{&Todo{Id: 1, Subject: "one", Status: "Pending"}}}
+	app := &App{TodoList: &TodoList{}, TodoStore: NewBatchStore(store)}
+	batch := app.TodoStore.(*BatchStore)
+
+	//Each command loads and saves. The todos are loaded once and nothing is written until Commit.
+	app.LoadPending()
+	app.TodoList.Add(&Todo{Subject: "two", Status: "Pending"})
+	app.Save()
+	app.LoadPending()
+	app.TodoList.Edit([]string{"due:tom"}, app.TodoList.FindById(1))
+	app.Save()
+
+	assert.Equal(1, store.loads)
+	assert.Equal(2, len(app.TodoList.Data))
+	assert.Equal(0, store.saves)
+	assert.Equal(2, batch.Modified())
+
+	batch.Commit()
+	assert.Equal(1, store.saves)
+	assert.Equal(2, len(store.modified))
+}
+
+func TestBatchReportThenAdd(t *testing.T) {
+	assert := assert.New(t)
+	clock := FixedClock{Time: time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)}
+	out := &bytes.Buffer{}
+	store := &recordingStore{
+		pending: []*Todo{
+			&Todo{Id: 1, Subject: "one", Status: "Pending"},
+			&Todo{Id: 2, Subject: "expired", Status: "Pending", Until: timeToString(clock.Now().AddDate(0, 0, -1))},
+		},
+		archived: []*Todo{&Todo{Id: 3, Subject: "old", Status: "Archived"}},
+	}
+	app := &App{Cfg: &Config{}, TodoList: NewTodoList(clock), TodoStore: NewBatchStore(store), Clock: clock,
+		Printer: NewJSONPrinter(out, clock), CommandMap: map[string]Command{}}
+	list := app.AddReportCommand("list", &Report{Filters: []string{}, Columns: []string{"id", "subject"}, Headers: []string{"Id", "Subject"}, Sorter: NewTodoSorter("id")})
+
+	//A report archives the expired todo. The todos added after it are saved along with all the others.
+	assert.Nil(list.Exec(app))
+	assert.Contains(out.String(), `"one"`)
+	assert.NotContains(out.String(), `"expired"`)
+	assert.NotContains(out.String(), `"old"`)
+	app.LoadPending()
+	app.TodoList.Add(&Todo{Subject: "brand new", Status: "Pending"})
+	app.Save()
+	assert.Nil(app.TodoStore.(*BatchStore).Commit())
+
+	subjects := map[string]string{}
+	for _, todo := range store.saved {
+		subjects[todo.Subject] = todo.Status
+	}
+	assert.Equal(map[string]string{"one": "Pending", "expired": "Archived", "old": "Archived", "brand new": "Pending"}, subjects)
+}
+
+//A batch app with the built-in commands, printing reports as JSON to out
+func newBatchApp(store Store, out *bytes.Buffer) *App {
+	clock := FixedClock{Time: time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)}
+	app := &App{Cfg: &Config{}, TodoList: NewTodoList(clock), TodoStore: store, Clock: clock,
+		Printer: NewJSONPrinter(out, clock), CommandMap: map[string]Command{}}
+	app.mapCommands()
+	return app
+}
+
+func runBatch(app *App, lines ...string) error {
+	file, _ := ioutil.TempFile("", "batch")
+	defer os.Remove(file.Name())
+	file.WriteString(strings.Join(lines, "\n"))
+	file.Close()
+	return app.Batch(&CommandImpl{Args: []string{file.Name()}})
+}
+
+func TestBatchSharedIds(t *testing.T) {
+	assert := assert.New(t)
+	clock := FixedClock{Time: time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)}
+	pending := &Todo{Id: 1, Subject: "pending", Status: "Pending", Ordinals: map[string]int{}}
+	expired := &Todo{Id: 2, Subject: "expired", Status: "Pending", Until: timeToString(clock.Now().AddDate(0, 0, -1)),
+		Ordinals: map[string]int{}}
+	archived := &Todo{Id: 1, Subject: "archived", Status: "Archived", Ordinals: map[string]int{}}
+	archived2 := &Todo{Id: 2, Subject: "archived2", Status: "Archived", Ordinals: map[string]int{}}
+	store := &recordingStore{pending: []*Todo{pending, expired}, archived: []*Todo{archived, archived2}}
+	app := newBatchApp(store, &bytes.Buffer{})
+
+	//The report loads the archived todos, which share ids 1 and 2 with the pending ones, to archive the expired one.
+	//The edits after it only see the pending todo 1.
+	assert.Nil(runBatch(app, "list", "1 edit due:tom", "1 edit +Work"))
+	saved := map[string]*Todo{}
+	for _, todo := range store.saved {
+		saved[todo.Subject] = todo
+	}
+	assert.Equal(4, len(store.saved))
+	assert.Equal("Pending", saved["pending"].Status)
+	assert.NotEmpty(saved["pending"].Due)
+	assert.Equal([]string{"Work"}, saved["pending"].Projects)
+	assert.Equal("Archived", saved["expired"].Status)
+	assert.Equal("Archived", saved["archived"].Status)
+	assert.Empty(saved["archived"].Due)
+	assert.Empty(saved["archived"].Projects)
+	assert.Equal("Archived", saved["archived2"].Status)
+}
+
+func TestBatchTwoReports(t *testing.T) {
+	assert := assert.New(t)
+	store := &recordingStore{pending: []*Todo{
+		&Todo{Id: 1, Subject: "work", Status: "Pending", Projects: []string{"Work"}},
+		&Todo{Id: 2, Subject: "home", Status: "Pending", Projects: []string{"Home"}},
+	}}
+	out := &bytes.Buffer{}
+	app := newBatchApp(store, out)
+
+	//Each report line gets its own filters, not those of the lines before it
+	assert.Nil(runBatch(app, "+Work list", "+Home list"))
+	reports := strings.Split(strings.TrimSpace(out.String()), "]\n[")
+	assert.Equal(2, len(reports))
+	assert.Contains(reports[0], `"work"`)
+	assert.NotContains(reports[0], `"home"`)
+	assert.Contains(reports[1], `"home"`)
+	assert.Equal([]string{}, app.CommandMap["list"].(*ReportCmd).SavedReport.Filters)
+}
@@ -0,0 +1,78 @@
+package todolist
+
+import (
+	"sort"
+	"time"
+)
+
+//Urgency at which a day on the calendar is marked urgent. With the default coefficients, a todo due tomorrow scores about 8.5.
+const calendarUrgency = 8.0
+
+//How a day on the calendar is highlighted: the worst state of the pending todos due that day
+const (
+	DayFree = iota
+	DayDue
+	DayUrgent
+	DayOverdue
+)
+
+//Todos due in a span of months, by day, for the calendar command
+type Calendar struct {
+	Begin  time.Time          //First day of the first month
+	Months int                //Number of months shown
+	Days   map[string][]*Todo //Todos due each day, keyed by yyyy-mm-dd
+	Now    time.Time          //Today is highlighted, and todos due before today are overdue
+}
+
+func NewCalendar(todos []*Todo, begin time.Time, months int, clock Clock) *Calendar {
+	begin = bom(begin)
+	end := begin.AddDate(0, months, 0).Add(-time.Nanosecond)
+	df := NewDateFilter(todos, clock)
+	cal := &Calendar{Begin: begin, Months: months, Days: map[string][]*Todo{}, Now: df.Now}
+	for _, todo := range df.filterBetweenDatesInclusive(begin, end, filterOnDue) {
+		key := calendarDayKey(filterOnDue(todo))
+		cal.Days[key] = append(cal.Days[key], todo)
+	}
+	for _, dayTodos := range cal.Days {
+		sort.Slice(dayTodos, func(i, j int) bool { return dayTodos[i].Id < dayTodos[j].Id })
+	}
+	return cal
+}
+
+func calendarDayKey(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+//Todos due on the day
+func (c *Calendar) TodosOn(day time.Time) []*Todo {
+	return c.Days[calendarDayKey(day)]
+}
+
+//Days with todos due, in order
+func (c *Calendar) DaysDue() []time.Time {
+	days := []time.Time{}
+	for key := range c.Days {
+		day, _ := time.ParseInLocation("2006-01-02", key, c.Begin.Location())
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return days
+}
+
+//Worst state of the pending todos due on the day. Completed todos don't count.
+func (c *Calendar) DayState(day time.Time) int {
+	state := DayFree
+	for _, todo := range c.TodosOn(day) {
+		if todo.Completed {
+			continue
+		}
+		if todo.IsOverdue(c.Now) {
+			return DayOverdue
+		} else if todo.Urgency >= calendarUrgency {
+			state = DayUrgent
+		} else if state == DayFree {
+			state = DayDue
+		}
+	}
+	return state
+}
@@ -0,0 +1,36 @@
+package todolist
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalendar(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+	clock := FixedClock{Time: time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)}
+	now := clock.Now()
+	todos := []*Todo{
+		&Todo{Id: 1, Subject: "Late", Due: "2026-10-12T00:00:00Z", Status: "Pending"},
+		&Todo{Id: 2, Subject: "Urgent", Due: "2026-10-15T00:00:00Z", Status: "Pending", Urgency: 9},
+		&Todo{Id: 3, Subject: "Later", Due: "2026-10-30T00:00:00Z", Status: "Pending", Urgency: 2},
+		&Todo{Id: 4, Subject: "Done", Due: "2026-10-30T00:00:00Z", Status: "Pending", Completed: true},
+		&Todo{Id: 5, Subject: "Next month", Due: "2026-11-01T00:00:00Z", Status: "Pending"},
+	}
+	cal := NewCalendar(todos, now, 1, clock)
+	assert.Equal(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), cal.Begin)
+	assert.Equal(3, len(cal.DaysDue()))
+	assert.Equal(DayOverdue, cal.DayState(time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(DayUrgent, cal.DayState(time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(DayDue, cal.DayState(time.Date(2026, 10, 30, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(DayFree, cal.DayState(time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC)))
+	assert.Equal([]*Todo{todos[2], todos[3]}, cal.TodosOn(time.Date(2026, 10, 30, 0, 0, 0, 0, time.UTC)))
+
+	//Navigation reuses the relative dates
+	assert.Equal(time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC), mustTranslateToDates(now, "next_month")[0])
+	assert.Equal(time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), mustTranslateToDates(now, "last_month")[0])
+	assert.Equal(time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), mustTranslateToDates(now, "next_year")[0])
+	assert.Equal(1, len(NewCalendar(todos, mustTranslateToDates(now, "next_month")[0], 1, clock).DaysDue()))
+}
@@ -0,0 +1,45 @@
+package todolist
+
+import (
+	"os"
+	"time"
+)
+
+//Source of the current time. The App's clock is passed to everything that compares dates with now (parsing,
+//filtering, sorting, stats, sync), so tests and TODO_NOW can fix the time without a global.
+type Clock interface {
+	Now() time.Time
+}
+
+//The time of the system
+type SystemClock struct{}
+
+func (c SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+//Always the same time. For tests, and for seeing the todos as they were (or will be) on a date.
+type FixedClock struct {
+	Time time.Time
+}
+
+func (c FixedClock) Now() time.Time {
+	return c.Time
+}
+
+//Formats accepted by TODO_NOW, in local time unless the zone is given
+var clockFormats = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"}
+
+//The system clock, or a clock fixed at TODO_NOW (e.g. TODO_NOW=2020-03-01 or TODO_NOW=2020-03-01T09:30) if set
+func NewClock() (Clock, error) {
+	value := os.Getenv("TODO_NOW")
+	if value == "" {
+		return SystemClock{}, nil
+	}
+	for _, format := range clockFormats {
+		if t, err := time.ParseInLocation(format, value, time.Local); err == nil {
+			return FixedClock{Time: t}, nil
+		}
+	}
+	return nil, newError(ErrBadDate, "Expected TODO_NOW like 2020-03-01, 2020-03-01T09:30 or 2020-03-01T09:30:00-05:00: %s", value)
+}
@@ -0,0 +1,32 @@
+package todolist
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewClock(t *testing.T) {
+	assert := assert.New(t)
+	defer os.Unsetenv("TODO_NOW")
+
+	os.Unsetenv("TODO_NOW")
+	clock, err := NewClock()
+	assert.Nil(err)
+	assert.Equal(SystemClock{}, clock)
+
+	os.Setenv("TODO_NOW", "2020-03-01T09:30")
+	clock, err = NewClock()
+	assert.Nil(err)
+	assert.Equal(time.Date(2020, 3, 1, 9, 30, 0, 0, time.Local), clock.Now())
+
+	os.Setenv("TODO_NOW", "2020-03-01T09:30:00Z")
+	clock, _ = NewClock()
+	assert.True(clock.Now().Equal(time.Date(2020, 3, 1, 9, 30, 0, 0, time.UTC)))
+
+	os.Setenv("TODO_NOW", "next tuesday")
+	_, err = NewClock()
+	assert.NotNil(err)
+}
@@ -0,0 +1,216 @@
+package todolist
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//The built-in value of each setting that has one, as config list shows it
+func DefaultConfigValues() map[string]string {
+	usr, _ := user.Current()
+	slash := string(os.PathSeparator)
+	values := map[string]string{
+		"priority":           "H,M,L",
+		"color.precedence":   strings.Join(DefaultColorPrecedence, ","),
+		"open.notes.folder":  fmt.Sprintf("%s/.todo_notes", usr.HomeDir),
+		"open.notes.ext":     ".txt",
+		"open.notes.regex":   "notes",
+		"open.browser.regex": "((((https?://)?(www.))|(https?://))\\S+)",
+		"open.file.regex":    "((\\/|\\.\\/|~\\/|\\w:\\" + slash + ").+)",
+		"timetrack.multiple": "false",
+		"timetrack.autostop": "false",
+		"pomo.work":          "25",
+		"pomo.break":         "5",
+		"remind.lead":        "1h",
+		"remind.events":      "due,scheduled,wait",
+		"remind.interval":    "1",
+		"hooks.dir":          getHooksLocation(),
+		"plugins.dir":        getPluginsLocation(),
+	}
+	for key, coefficient := range DefaultUrgencyCoefficients() {
+		values["urgency."+key+".coefficient"] = strconv.FormatFloat(coefficient, 'f', -1, 64)
+	}
+	return values
+}
+
+//The keys set in .todorc, in the order of their lines
+func (c *Config) sortedKeys() []string {
+	keys := []string{}
+	for key := range c.Values {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return c.Sources[keys[i]].Line < c.Sources[keys[j]].Line
+	})
+	return keys
+}
+
+//Problems with the values set in .todorc, each as file:line: key: problem, after the error reading it, if any
+func (a *App) CheckConfig() []string {
+	problems := []string{}
+	if a.Cfg.ReadErr != nil {
+		problems = append(problems, a.Cfg.ReadErr.Error())
+	}
+	for _, key := range a.Cfg.sortedKeys() {
+		if err := a.checkConfigValue(key, a.Cfg.Values[key]); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s: %s", a.Cfg.Sources[key], key, err))
+		}
+	}
+	return problems
+}
+
+//The settings that take any name in their key are matched by isConfigKey
+var configKeys = []string{"view.current", "priority", "color", "timetrack.multiple", "timetrack.autostop", "pomo.work",
+	"pomo.break", "remind.lead", "remind.cmd", "remind.events", "remind.interval", "hooks.dir", "plugins.dir",
+	"sync.filepath", "sync.encrypt.passphrase"}
+
+var reportKeys = []string{"description", "columns", "headers", "sort", "filter", "group", "notes", "widths", "wrap"}
+
+//Whether the key is a setting, rather than a typo
+func isConfigKey(key string) bool {
+	keys := strings.Split(key, ".")
+	switch {
+	case keys[0] == "alias" && len(keys) == 2, keys[0] == "urgency" && len(keys) > 1:
+		return true
+	case keys[0] == "plugin" && len(keys) > 2: //Settings of plugins, which get them in their environment
+		return true
+	case keys[0] == "report" && len(keys) == 3:
+		return contains(reportKeys, keys[2])
+	case keys[0] == "view" && len(keys) == 3:
+		return keys[2] == "filter"
+	case keys[0] == "repo" && len(keys) == 3:
+		return keys[2] == "path"
+	case keys[0] == "color" && len(keys) > 1:
+		return key == "color.precedence" || isColorKey(key[6:])
+	case keys[0] == "open" && len(keys) == 3:
+		return keys[2] == "regex" || keys[2] == "cmd" || keys[1] == "notes" && (keys[2] == "ext" || keys[2] == "folder")
+	}
+	return contains(configKeys, key)
+}
+
+func (a *App) checkConfigValue(key string, value string) error {
+	if !isConfigKey(key) {
+		return fmt.Errorf("Unknown setting")
+	}
+	keys := strings.Split(key, ".")
+	switch {
+	case keys[0] == "alias" && len(keys) == 2:
+		if value == "" {
+			return fmt.Errorf("Empty alias")
+		}
+		if _, isAlias := a.CommandMap[keys[1]].(*AliasCmd); !isAlias {
+			return fmt.Errorf("The %s command has this name, so the alias is never used", keys[1])
+		}
+		_, err := a.AliasExpansion(keys[1], nil)
+		return err
+	case keys[0] == "report" && len(keys) == 3:
+		return checkReportValue(a.Cfg.Reports[keys[1]], keys[2], value)
+	case key == "view.current":
+		if _, found := a.Cfg.Views[value]; !found && value != "" {
+			return fmt.Errorf("No view named %s", value)
+		}
+	case key == "priority":
+		seen := map[string]bool{}
+		for _, p := range strings.Split(value, ",") {
+			if p = strings.TrimSpace(p); p == "" || seen[p] {
+				return fmt.Errorf("Expected distinct priorities (comma-sep), e.g. H,M,L")
+			}
+			seen[p] = true
+		}
+	case key == "color":
+		_, err := parseColorOutput(value)
+		return err
+	case key == "color.precedence":
+		for _, rule := range strings.Split(strings.Replace(value, " ", "", -1), ",") {
+			if !contains(DefaultColorPrecedence, rule) {
+				return fmt.Errorf("Unknown rule %s, expected %s", rule, strings.Join(DefaultColorPrecedence, ","))
+			}
+		}
+	case keys[0] == "color":
+		_, err := ParseColorSpec(value)
+		return err
+	case keys[0] == "urgency":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("Expected a number")
+		}
+	case key == "timetrack.multiple", key == "timetrack.autostop":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("Expected true or false")
+		}
+	case key == "pomo.work", key == "pomo.break":
+		if _, err := parsePomoLength(value); err != nil {
+			return fmt.Errorf("Expected minutes or a duration over 0, e.g. 25 or 1h")
+		}
+	case key == "remind.lead":
+		_, err := ParseLeadTimes(value)
+		return err
+	case key == "remind.events":
+		_, err := parseRemindEvents(value)
+		return err
+	case key == "remind.interval":
+		if interval, err := parsePomoLength(value); err != nil || interval <= 0 {
+			return fmt.Errorf("Expected minutes or a duration, e.g. 5 or 90s")
+		}
+	case key == "hooks.dir", key == "plugins.dir":
+		if info, err := os.Stat(value); err != nil || !info.IsDir() {
+			return fmt.Errorf("No such directory")
+		}
+	case keys[0] == "repo":
+		if _, err := os.Stat(filepath.Join(expandHome(value), ".todos.json")); err != nil {
+			return fmt.Errorf("No .todos.json in %s", value)
+		}
+	case keys[0] == "open" && keys[2] == "regex":
+		_, err := regexp.Compile(value)
+		return err
+	}
+	return nil
+}
+
+//Check a report's setting, against its other settings for the header count
+func checkReportValue(rc map[string]string, attr string, value string) error {
+	columns := strings.Split(rc["columns"], ",")
+	switch attr {
+	case "description", "filter":
+	case "columns":
+		for _, col := range strings.Split(value, ",") {
+			if !isReportColumn(col) {
+				return fmt.Errorf("Unknown column %s", col)
+			}
+		}
+	case "headers":
+		if headers := strings.Split(value, ","); len(headers) != len(columns) {
+			return fmt.Errorf("%d headers for %d columns", len(headers), len(columns))
+		}
+	case "sort":
+		for _, key := range strings.Split(value, ",") {
+			if key != "" && !isSortKey(key) {
+				return fmt.Errorf("Unknown sort key %s", key)
+			}
+		}
+	case "group":
+		if group := strings.ToLower(value); group != "" && group != "project" && group != "context" {
+			return fmt.Errorf("Expected project or context")
+		}
+	case "notes", "wrap":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("Expected true or false")
+		}
+	case "widths":
+		widths := strings.Split(value, ",")
+		for _, width := range widths {
+			if _, err := strconv.Atoi(strings.TrimSpace(width)); err != nil {
+				return fmt.Errorf("Expected widths (comma-sep), e.g. 0,12")
+			}
+		}
+		if len(widths) > len(columns) {
+			return fmt.Errorf("%d widths for %d columns", len(widths), len(columns))
+		}
+	}
+	return nil
+}
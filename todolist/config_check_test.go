@@ -0,0 +1,83 @@
+package todolist
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckConfig(t *testing.T) {
+	assert := assert.New(t)
+	values := map[string]string{
+		"report.bad.columns": "id,subjekt",
+		"report.bad.headers": "Id",
+		"report.bad.sort":    "+due,-urgncy",
+		"report.ok.columns":  "id,subject",
+		"report.ok.headers":  "Id,Subject",
+		"open.jira.regex":    "(JIRA-\\d+",
+		"priority":           "H,M,H",
+		"priorty":            "A,B",
+		"alias.l1":           "l2",
+		"alias.l2":           "l1",
+		"alias.list":         "l",
+		"plugin.count.by":    "context",
+	}
+	cfg := &Config{Values: values, Sources: map[string]ConfigSource{}, Reports: map[string]map[string]string{
+		"bad": {"columns": "id,subjekt", "headers": "Id", "sort": "+due,-urgncy"},
+		"ok":  {"columns": "id,subject", "headers": "Id,Subject"},
+	}}
+	for i, key := range []string{"report.bad.columns", "report.bad.headers", "report.bad.sort", "report.ok.columns",
+		"report.ok.headers", "open.jira.regex", "priority", "priorty", "alias.l1", "alias.l2", "alias.list", "plugin.count.by"} {
+		cfg.Sources[key] = ConfigSource{File: ".todorc", Line: i + 1}
+	}
+	app := &App{Cfg: cfg, CommandMap: map[string]Command{"list": NewCommand("list", false, true, nil)}}
+	app.AddAliasCommand("l1", "l2")
+	app.AddAliasCommand("l2", "l1")
+
+	assert.Equal([]string{
+		".todorc:1: report.bad.columns: Unknown column subjekt",
+		".todorc:2: report.bad.headers: 1 headers for 2 columns",
+		".todorc:3: report.bad.sort: Unknown sort key -urgncy",
+		".todorc:6: open.jira.regex: error parsing regexp: missing closing ): `(JIRA-\\d+`",
+		".todorc:7: priority: Expected distinct priorities (comma-sep), e.g. H,M,L",
+		".todorc:8: priorty: Unknown setting",
+		".todorc:9: alias.l1: Alias loop: l1 -> l2 -> l1",
+		".todorc:10: alias.l2: Alias loop: l2 -> l1 -> l2",
+		".todorc:11: alias.list: The list command has this name, so the alias is never used",
+	}, app.CheckConfig())
+
+	//A pomodoro of no length would never end
+	assert.NotNil(app.checkConfigValue("pomo.work", "0"))
+	assert.NotNil(app.checkConfigValue("pomo.break", "-5m"))
+	assert.Nil(app.checkConfigValue("pomo.break", "90s"))
+	_, err := parsePomoLength("0")
+	assert.NotNil(err)
+}
+
+func TestSetConfigValue(t *testing.T) {
+	assert := assert.New(t)
+	dir, _ := ioutil.TempDir("", "config")
+	defer os.RemoveAll(dir)
+	wd, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(wd)
+	ioutil.WriteFile(".todos.json", []byte("[]"), 0644)
+	ioutil.WriteFile(".todorc", []byte("#pomo.work=25\npomo.work=30\nremind.lead=1h,1d\nview.current=home"), 0644)
+
+	store := NewConfigStore()
+	assert.Nil(store.SetConfigValue("pomo.work", "45"))
+	assert.Nil(store.SetConfigValue("view.current", "work"))
+	assert.Nil(store.SetConfigValue("pomo.break", "10"))
+	assert.Nil(store.UnsetConfigValue("remind.lead"))
+	assert.True(errors.Is(store.UnsetConfigValue("remind.lead"), ErrBadInput))
+	data, _ := ioutil.ReadFile(".todorc")
+	assert.Equal("#pomo.work=25\npomo.work=45\nview.current=work\npomo.break=10\n", string(data))
+
+	cfg, err := store.Load()
+	assert.Nil(err)
+	assert.Equal("45", cfg.Values["pomo.work"])
+	assert.Equal(ConfigSource{File: ".todorc", Line: 4}, cfg.Sources["pomo.break"])
+}
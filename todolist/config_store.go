@@ -4,10 +4,13 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"os/user"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type ConfigStore struct {
@@ -28,19 +31,49 @@ type Config struct {
 	OpenNotesCmd             string
 	OpenCustomRegex          map[string]string
 	OpenCustomCmd            map[string]string
+	TimeTrackMultiple        bool
+	TimeTrackAutoStop        bool
+	PomoWork                 time.Duration
+	PomoBreak                time.Duration
+	RemindLeads              []time.Duration
+	RemindCmd                string
+	RemindEvents             []string
+	RemindInterval           time.Duration
+	HooksDir                 string
+	PluginsDir               string
+	Repos                    map[string]string       //Named repos, repo.<name>.path, for --repo=, TODO_REPO and repo: filters
+	RepoDir                  string                  //The directory of the repo in use
+	File                     string                  //The .todorc config set changes, if it exists
+	Files                    []string                //The files read, in order
+	ReadErr                  error                   //Why a file could not be read. The files after it were not.
+	Values                   map[string]string       //Every key=value read, for plugins and config list
+	Sources                  map[string]ConfigSource //Where each of the Values was read
+	Priority                 map[string]int          //Order of the priority values, highest first
+	UrgencyCoefficients      map[string]float64
+	Colors                   map[string]string //color.<element or rule>=<spec>, without the color. prefix
 }
 
-//Declare Priority global because need access in filter and sorter
-var (
-	Priority map[string]int
-)
+//The file and line a config value was read from
+type ConfigSource struct {
+	File string
+	Line int
+}
+
+//file:line, or the environment variable or command line
+func (s ConfigSource) String() string {
+	if s.Line == 0 {
+		return s.File
+	}
+	return fmt.Sprintf("%s:%d", s.File, s.Line)
+}
 
 func NewConfigStore() *ConfigStore {
 	return &ConfigStore{FileLocation: ".todorc", Loaded: false}
 }
 
-func (f *ConfigStore) Load() (*Config, error) {
-	f.FileLocation = getConfigLocation()
+//Load the builtin defaults, overridden by $XDG_CONFIG_HOME/todo/config, ~/.todorc, the repo's .todorc, TODO_<KEY>
+//environment variables and then the overrides (key=value, from rc.key=value on the command line)
+func (f *ConfigStore) Load(overrides ...string) (*Config, error) {
 	usr, _ := user.Current()
 	notesDir := fmt.Sprintf("%s/.todo_notes", usr.HomeDir)
 
@@ -58,6 +91,18 @@ func (f *ConfigStore) Load() (*Config, error) {
 		OpenNotesCmd:             "",
 		OpenCustomRegex:          map[string]string{},
 		OpenCustomCmd:            map[string]string{},
+		PomoWork:                 25 * time.Minute,
+		PomoBreak:                5 * time.Minute,
+		RemindLeads:              []time.Duration{time.Hour},
+		RemindCmd:                "",
+		RemindEvents:             []string{AgendaDue, AgendaScheduled, AgendaWaitEnds},
+		RemindInterval:           time.Minute,
+		Repos:                    map[string]string{},
+		Values:                   map[string]string{},
+		Sources:                  map[string]ConfigSource{},
+		Priority:                 DefaultPriority(),
+		UrgencyCoefficients:      DefaultUrgencyCoefficients(),
+		Colors:                   map[string]string{},
 	}
 	//Default regex for web URLs
 	config.OpenCustomRegex["browser"] = "((((https?://)?(www.))|(https?://))\\S+)"
@@ -65,21 +110,128 @@ func (f *ConfigStore) Load() (*Config, error) {
 	slash := string(os.PathSeparator)
 	config.OpenCustomRegex["file"] = "((\\/|\\.\\/|~\\/|\\w:\\" + slash + ").+)"
 
-	// default values for priority
-	Priority = map[string]int{"H": 1, "M": 2, "L": 3}
+	//No color when piping output, see https://no-color.org
+	if os.Getenv("NO_COLOR") != "" {
+		SetColorOutput("off")
+	}
 
-	if len(f.FileLocation) == 0 {
-		return &config, nil
+	//Layers, each overriding the one before: the global files, the repo's, the environment and the command line
+	locations := configLocations()
+	for _, location := range locations {
+		if err := f.readFile(&config, location, []string{}); err != nil {
+			config.ReadErr = err
+			return &config, err
+		}
 	}
-	file, err := os.Open(f.FileLocation)
+	//The repo, which may be named in the global files, and then its .todorc
+	dir, err := FindRepo(config.Repos)
 	if err != nil {
-		return &config, nil
+		config.ReadErr = err
+		return &config, err
+	}
+	repoDir = dir
+	config.RepoDir = dir
+	f.FileLocation = getConfigLocation()
+	//Not the home .todorc again, when the repo is the home directory
+	if absPath(f.FileLocation) != absPath(locations[1]) {
+		if err := f.readFile(&config, f.FileLocation, []string{}); err != nil {
+			config.ReadErr = err
+			return &config, err
+		}
+	}
+	for _, env := range os.Environ() {
+		if equal := strings.Index(env, "="); strings.HasPrefix(env, "TODO_") && equal >= 0 {
+			//Only settings. Other TODO_ variables (TODO_NOW, TODO_REPO, the TODO_CFG_ config given to plugins ...) are not config.
+			if key := configEnvKey(env[:equal]); isConfigKey(key) {
+				config.set(key, env[equal+1:], ConfigSource{File: "env " + env[:equal]})
+			}
+		}
+	}
+	for _, override := range overrides {
+		if equal := strings.Index(override, "="); equal > 0 {
+			config.set(strings.TrimSpace(override[:equal]), strings.TrimSpace(override[equal+1:]), ConfigSource{File: "command line"})
+		}
+	}
+
+	if _, err := os.Stat(f.FileLocation); err == nil {
+		config.File = f.FileLocation
+	}
+	//The repo's, by default
+	if _, set := config.Values["hooks.dir"]; !set {
+		config.HooksDir = getHooksLocation()
+	}
+	if _, set := config.Values["plugins.dir"]; !set {
+		config.PluginsDir = getPluginsLocation()
+	}
+	f.Loaded = true
+	//Check the reports now, so GetReport can't fail later
+	for name, rc := range config.Reports {
+		if err := (&Report{}).Init(rc); err != nil {
+			return &config, fmt.Errorf("report.%s: %w", name, err)
+		}
+	}
+	return &config, nil
+}
+
+//Remove the rc.key=value args, returning the rest and the key=value overrides they set, as NewApp takes them
+func SplitOverrides(args []string) (rest []string, overrides []string) {
+	rest = []string{}
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "rc.") && strings.Contains(arg, "=") {
+			overrides = append(overrides, arg[3:])
+		} else {
+			rest = append(rest, arg)
+		}
+	}
+	return rest, overrides
+}
+
+//The global config files: $XDG_CONFIG_HOME/todo/config (default ~/.config) and ~/.todorc
+func configLocations() []string {
+	usr, _ := user.Current()
+	xdg := os.Getenv("XDG_CONFIG_HOME")
+	if xdg == "" {
+		xdg = filepath.Join(usr.HomeDir, ".config")
+	}
+	return []string{filepath.Join(xdg, "todo", "config"), filepath.Join(usr.HomeDir, ".todorc")}
+}
+
+//The config key for a TODO_<KEY> environment variable, e.g. sync.filepath for TODO_SYNC_FILEPATH. Keys with
+//capitals or underscores can't be set this way. Use rc.key=value on the command line instead.
+func configEnvKey(name string) string {
+	return strings.Replace(strings.ToLower(strings.TrimPrefix(name, "TODO_")), "_", ".", -1)
+}
+
+//A path starting with ~/ is in the home directory
+func expandHome(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		usr, _ := user.Current()
+		return filepath.Join(usr.HomeDir, path[2:])
+	}
+	return path
+}
+
+//Read the key=value lines of a .todorc file. include=path reads another file at that point, relative to this one.
+//A missing file is skipped, unless it is included. Includes lists the files including this one, to catch loops.
+func (f *ConfigStore) readFile(config *Config, location string, includes []string) error {
+	for _, include := range includes {
+		if include == absPath(location) {
+			return newError(ErrBadConfig, "Include loop: %s", strings.Join(append(includes, absPath(location)), " -> "))
+		}
+	}
+	file, err := os.Open(location)
+	if os.IsNotExist(err) && len(includes) == 0 {
+		return nil
+	}
+	if err != nil {
+		return newError(ErrBadConfig, "Error reading %s: %s", location, err)
 	}
 	defer file.Close()
+	config.Files = append(config.Files, location)
 
 	reader := bufio.NewReader(file)
 
-	for {
+	for lineNum := 1; ; lineNum++ {
 		line, err := reader.ReadString('\n')
 		if strings.HasPrefix(line, "#") {
 			continue
@@ -92,67 +244,16 @@ func (f *ConfigStore) Load() (*Config, error) {
 				if len(line) > equal {
 					value = strings.TrimSpace(line[equal+1:])
 				}
-				// assign the config map
-				if strings.HasPrefix(key, "alias") {
-					keys := strings.Split(key, ".")
-					if len(keys) > 1 {
-						config.Aliases[keys[1]] = value
+				if key == "include" {
+					path := expandHome(value)
+					if !filepath.IsAbs(path) {
+						path = filepath.Join(filepath.Dir(location), path)
 					}
-				} else if strings.HasPrefix(key, "report") {
-					keys := strings.Split(key, ".")
-					if len(keys) > 2 {
-						rep, ok := config.Reports[keys[1]]
-						if !ok {
-							rep = map[string]string{}
-							config.Reports[keys[1]] = rep
-						}
-						rep[keys[2]] = value
-					}
-				} else if strings.HasPrefix(key, "view") {
-					keys := strings.Split(key, ".")
-					if len(keys) > 2 {
-						if keys[2] == "filter" {
-							config.Views[keys[1]] = strings.Split(value, " ")
-						}
-					} else {
-						if keys[1] == "current" {
-							config.CurrentView = value
-						}
-					}
-				} else if strings.HasPrefix(key, "priority") {
-					Priority = map[string]int{} //replace default values
-					v := strings.Split(strings.TrimSpace(value), ",")
-					for i, p := range v {
-						Priority[p] = i
-					}
-				} else if strings.HasPrefix(key, "sync.filepath") {
-					config.SyncFilepath = strings.TrimSpace(value)
-				} else if strings.HasPrefix(key, "sync.encrypt.passphrase") {
-					config.SyncEncryptionPassphrase = strings.TrimSpace(value)
-				} else if strings.HasPrefix(key, "open") {
-					keys := strings.Split(key, ".")
-					if len(keys) < 3 {
-						continue
-					}
-					if keys[1] == "notes" {
-						switch keys[2] {
-						case "ext":
-							config.OpenNotesExt = strings.TrimSpace(value)
-						case "folder":
-							config.OpenNotesFolder = strings.TrimSpace(value)
-						case "cmd":
-							config.OpenNotesCmd = strings.TrimSpace(value)
-						case "regex":
-							config.OpenNotesRegex = strings.TrimSpace(value)
-						}
-					} else {
-						switch keys[2] {
-						case "regex":
-							config.OpenCustomRegex[strings.TrimSpace(keys[1])] = strings.TrimSpace(value)
-						case "cmd":
-							config.OpenCustomCmd[strings.TrimSpace(keys[1])] = strings.TrimSpace(value)
-						}
+					if err := f.readFile(config, path, append(includes, absPath(location))); err != nil {
+						return fmt.Errorf("%s:%d: include: %w", location, lineNum, err)
 					}
+				} else {
+					config.set(key, value, ConfigSource{File: location, Line: lineNum})
 				}
 			}
 		}
@@ -160,88 +261,227 @@ func (f *ConfigStore) Load() (*Config, error) {
 			break
 		}
 		if err != nil {
-			return &config, nil
+			return newError(ErrBadConfig, "Error reading %s: %s", location, err)
 		}
 	}
-
-	f.Loaded = true
-	return &config, nil
+	return nil
 }
 
-func (f *ConfigStore) SetConfigValue(attr string, attrValue string) error {
-
-	var key string
-	var line string
-	var err error
-	var file *os.File
-
-	f.FileLocation = getConfigLocation()
-
-	if len(f.FileLocation) == 0 {
-		return nil
-	}
-	file, err = os.Open(f.FileLocation)
-	if err != nil {
-		return nil
-	}
-
-	reader := bufio.NewReader(file)
-	modified := false
-	todorc := []string{}
-
-	for {
-		line, err = reader.ReadString('\n')
-		if len(line) < 1 {
-			break
+//Set a config value, recording where it was set
+func (c *Config) set(key string, value string, source ConfigSource) {
+	c.Values[key] = value
+	c.Sources[key] = source
+	if strings.HasPrefix(key, "alias") {
+		keys := strings.Split(key, ".")
+		if len(keys) > 1 {
+			c.Aliases[keys[1]] = value
 		}
-
-		//Only modify non-comment lines
-		if !strings.HasPrefix(line, "#") {
-
-			// check if the line has = sign
-			// and process the line. Ignore the rest.
-			if equal := strings.Index(line, "="); equal >= 0 {
-				key = strings.TrimSpace(line[:equal])
+	} else if strings.HasPrefix(key, "report") {
+		keys := strings.Split(key, ".")
+		if len(keys) > 2 {
+			rep, ok := c.Reports[keys[1]]
+			if !ok {
+				rep = map[string]string{}
+				c.Reports[keys[1]] = rep
 			}
-			if key == attr {
-				modified = true
-				line = attr + "=" + attrValue + "\n"
-				todorc = append(todorc, line)
-			} else {
-				todorc = append(todorc, line)
+			rep[keys[2]] = value
+		}
+	} else if strings.HasPrefix(key, "view") {
+		keys := strings.Split(key, ".")
+		if len(keys) > 2 {
+			if keys[2] == "filter" {
+				c.Views[keys[1]] = strings.Split(value, " ")
 			}
-			//put comment back in resulting file
 		} else {
-			todorc = append(todorc, line)
+			if keys[1] == "current" {
+				c.CurrentView = value
+			}
+		}
+	} else if strings.HasPrefix(key, "priority") {
+		c.Priority = map[string]int{} //replace default values
+		v := strings.Split(strings.TrimSpace(value), ",")
+		for i, p := range v {
+			c.Priority[p] = i
+		}
+	} else if key == "color" {
+		if os.Getenv("NO_COLOR") == "" && SetColorOutput(value) != nil {
+			fmt.Println("Error parsing color configuration, expected on or off: ", key, "=", value)
+		}
+	} else if strings.HasPrefix(key, "color.") {
+		if _, perr := ParseColorSpec(value); key != "color.precedence" && (perr != nil || !isColorKey(key[6:])) {
+			fmt.Println("Error parsing color configuration: ", key, "=", value)
+		} else {
+			c.Colors[key[6:]] = value
+		}
+	} else if strings.HasPrefix(key, "urgency.") {
+		coefficient, perr := strconv.ParseFloat(value, 64)
+		if perr != nil {
+			fmt.Println("Error parsing number from urgency configuration: ", key, "=", value)
+		} else {
+			c.UrgencyCoefficients[urgencyConfigKey(key[8:])] = coefficient
+		}
+	} else if strings.HasPrefix(key, "timetrack.") {
+		flag, perr := strconv.ParseBool(value)
+		if perr != nil {
+			fmt.Println("Error parsing bool from timetrack configuration: ", key, "=", value)
+		} else if key == "timetrack.multiple" {
+			c.TimeTrackMultiple = flag
+		} else if key == "timetrack.autostop" {
+			c.TimeTrackAutoStop = flag
+		}
+	} else if key == "pomo.work" || key == "pomo.break" {
+		length, perr := parsePomoLength(value)
+		if perr != nil {
+			fmt.Println("Error parsing length from pomo configuration: ", key, "=", value)
+		} else if key == "pomo.work" {
+			c.PomoWork = length
+		} else {
+			c.PomoBreak = length
+		}
+	} else if key == "remind.lead" {
+		leads, perr := ParseLeadTimes(value)
+		if perr != nil {
+			fmt.Println("Error parsing lead times from remind configuration: ", key, "=", value)
+		} else {
+			c.RemindLeads = leads
+		}
+	} else if key == "remind.cmd" {
+		c.RemindCmd = value
+	} else if key == "remind.events" {
+		events, perr := parseRemindEvents(value)
+		if perr != nil {
+			fmt.Println("Error parsing events from remind configuration: ", key, "=", value)
+		} else {
+			c.RemindEvents = events
+		}
+	} else if key == "remind.interval" {
+		interval, perr := parsePomoLength(value)
+		if perr != nil || interval <= 0 {
+			fmt.Println("Error parsing interval from remind configuration: ", key, "=", value)
+		} else {
+			c.RemindInterval = interval
+		}
+	} else if key == "hooks.dir" {
+		c.HooksDir = value
+	} else if key == "plugins.dir" {
+		c.PluginsDir = value
+	} else if keys := strings.Split(key, "."); keys[0] == "repo" && len(keys) == 3 && keys[2] == "path" {
+		c.Repos[keys[1]] = value
+	} else if strings.HasPrefix(key, "sync.filepath") {
+		c.SyncFilepath = strings.TrimSpace(value)
+	} else if strings.HasPrefix(key, "sync.encrypt.passphrase") {
+		c.SyncEncryptionPassphrase = strings.TrimSpace(value)
+	} else if strings.HasPrefix(key, "open") {
+		keys := strings.Split(key, ".")
+		if len(keys) < 3 {
+			return
+		}
+		if keys[1] == "notes" {
+			switch keys[2] {
+			case "ext":
+				c.OpenNotesExt = strings.TrimSpace(value)
+			case "folder":
+				c.OpenNotesFolder = strings.TrimSpace(value)
+			case "cmd":
+				c.OpenNotesCmd = strings.TrimSpace(value)
+			case "regex":
+				c.OpenNotesRegex = strings.TrimSpace(value)
+			}
+		} else {
+			switch keys[2] {
+			case "regex":
+				c.OpenCustomRegex[strings.TrimSpace(keys[1])] = strings.TrimSpace(value)
+			case "cmd":
+				c.OpenCustomCmd[strings.TrimSpace(keys[1])] = strings.TrimSpace(value)
+			}
 		}
 	}
+}
+
+//Pomodoro lengths are minutes (e.g. 25) or a duration (e.g. 90s, 1h), more than 0
+func parsePomoLength(value string) (time.Duration, error) {
+	length, err := time.ParseDuration(value)
+	if minutes, merr := strconv.Atoi(value); merr == nil {
+		length, err = time.Duration(minutes)*time.Minute, nil
+	}
+	if err == nil && length <= 0 {
+		err = fmt.Errorf("length %s is not more than 0", value)
+	}
+	return length, err
+}
 
+//Set the key to the value in .todorc, replacing the line setting it or else adding one. Creates .todorc if missing.
+func (f *ConfigStore) SetConfigValue(attr string, attrValue string) error {
+	f.FileLocation = getConfigLocation()
+	modified := false
+	todorc, err := f.rewriteConfig(func(key string, line string) []string {
+		if key != attr {
+			return []string{line}
+		}
+		modified = true
+		return []string{attr + "=" + attrValue + "\n"}
+	})
+	if err != nil {
+		return err
+	}
 	//If no modification of existing attribute, then append as a new attribute.
 	if !modified {
-		line = attr + "=" + attrValue + "\n"
-		todorc = append(todorc, line)
+		if len(todorc) > 0 && !strings.HasSuffix(todorc[len(todorc)-1], "\n") {
+			todorc = append(todorc, "\n")
+		}
+		todorc = append(todorc, attr+"="+attrValue+"\n")
 	}
+	return f.writeConfig(todorc)
+}
 
-	file.Close()
-
-	file, err = os.OpenFile(f.FileLocation, os.O_RDWR, os.FileMode(int(0777)))
-	if err != nil {
+//Remove the lines setting the key from .todorc. Returns ErrBadInput if none do.
+func (f *ConfigStore) UnsetConfigValue(attr string) error {
+	f.FileLocation = getConfigLocation()
+	modified := false
+	todorc, err := f.rewriteConfig(func(key string, line string) []string {
+		if key != attr {
+			return []string{line}
+		}
+		modified = true
 		return nil
+	})
+	if err != nil {
+		return err
 	}
-	defer file.Close()
+	if !modified {
+		return newError(ErrBadInput, "%s is not set in %s", attr, f.FileLocation)
+	}
+	return f.writeConfig(todorc)
+}
 
-	cnt := 0
-	writer := bufio.NewWriter(file)
-	for _, line := range todorc {
-		num, err := writer.WriteString(line)
-		if err != nil {
-			return nil
-		}
-		cnt += num
+//The lines of .todorc, passing each key=value line through change (with its key) and keeping comments as they are.
+//A missing file has no lines.
+func (f *ConfigStore) rewriteConfig(change func(key string, line string) []string) ([]string, error) {
+	data, err := ioutil.ReadFile(f.FileLocation)
+	if os.IsNotExist(err) {
+		return []string{}, nil
 	}
-	err = writer.Flush()
 	if err != nil {
-		return nil
+		return nil, fmt.Errorf("Error reading %s: %w", f.FileLocation, err)
+	}
+	todorc := []string{}
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		if line == "" {
+			continue
+		}
+		//Only modify non-comment lines
+		if equal := strings.Index(line, "="); equal >= 0 && !strings.HasPrefix(line, "#") {
+			todorc = append(todorc, change(strings.TrimSpace(line[:equal]), line)...)
+		} else {
+			todorc = append(todorc, line)
+		}
+	}
+	return todorc, nil
+}
+
+func (f *ConfigStore) writeConfig(todorc []string) error {
+	if err := ioutil.WriteFile(f.FileLocation, []byte(strings.Join(todorc, "")), 0644); err != nil {
+		return fmt.Errorf("Error writing %s: %w", f.FileLocation, err)
 	}
 	return nil
 }
@@ -256,13 +496,15 @@ func CreateDefaultConfig() error {
 	defer file.Close()
 	writer := bufio.NewWriter(file)
 	_, err = writer.WriteString("## Notes on reports and commands. Type 'todolist help' for details and examles.\n")
-	_, err = writer.WriteString("## Columns: 'id' 'completed' 'age' 'due' 'context' 'project' 'ord:all' 'ord:pro' 'ord:ctx'\n")
+	_, err = writer.WriteString("## Columns: 'id' 'completed' 'age' 'due' 'scheduled' 'context' 'project' 'tags' 'vtags' 'urgency' 'spent' 'pomodoros' 'ord:all' 'ord:pro' 'ord:ctx'\n")
 	_, err = writer.WriteString("## Headers: Labels for the columns.\n")
-	_, err = writer.WriteString("## Sort: '+/-' plus 'id' 'age' 'due' 'context' 'project' 'ord:all' 'ord:pro' 'ord:ctx'\n")
+	_, err = writer.WriteString("## Sort: '+/-' plus 'id' 'age' 'due' 'scheduled' 'context' 'project' 'urgency' 'ord:all' 'ord:pro' 'ord:ctx'\n")
 	_, err = writer.WriteString("## Filter: Show results matching projects, contexts, due dates, etc.\n")
 	_, err = writer.WriteString("###### Exclusion: Prefix the filter with '-' to include todos that do NOT match that filter.\n")
 	_, err = writer.WriteString("## Group: Show results grouped by 'project' or 'context'\n")
 	_, err = writer.WriteString("## Notes: Show notes if true.\n")
+	_, err = writer.WriteString("## Widths: Max width of each column, 0 for no limit. The subject is also narrowed to fit the terminal.\n")
+	_, err = writer.WriteString("## Wrap: Wrap columns wider than their width if true, otherwise truncate them.\n")
 	_, err = writer.WriteString("\n")
 	_, err = writer.WriteString("## Define a default report format used to print tasks to terminal\n")
 	_, err = writer.WriteString("report.default.description='Default report of pending todos'\n")
@@ -272,10 +514,29 @@ func CreateDefaultConfig() error {
 	_, err = writer.WriteString("report.default.filter=\n")
 	_, err = writer.WriteString("report.default.group=project\n")
 	_, err = writer.WriteString("#report.default.notes=true\n")
+	_, err = writer.WriteString("#report.default.widths=0,0,0,0,12,12,0\n")
+	_, err = writer.WriteString("#report.default.wrap=false\n")
 	_, err = writer.WriteString("\n")
 	_, err = writer.WriteString("## Define custom priorities. Default is H,M,L.\n")
 	_, err = writer.WriteString("#priority=H,M,L\n")
 	_, err = writer.WriteString("\n")
+	_, err = writer.WriteString("## Tune the urgency score (sort:urgency). Use 'todo list explain' to see the terms for each todo.\n")
+	_, err = writer.WriteString("#urgency.due.coefficient=12.0\n")
+	_, err = writer.WriteString("#urgency.age.coefficient=2.0\n")
+	_, err = writer.WriteString("#urgency.project.BigProject.coefficient=5.0\n")
+	_, err = writer.WriteString("\n")
+	_, err = writer.WriteString("## Colors. 'color=off' turns them off (as does the NO_COLOR environment variable). Specs are like 'red', 'bold bright cyan', 'black on yellow' or 'none'.\n")
+	_, err = writer.WriteString("## Elements: header label id status started subject due late date project context tag priority number spent pomodoro\n")
+	_, err = writer.WriteString("#color.due=yellow\n")
+	_, err = writer.WriteString("## Rules color the whole row. The first matching rule in precedence order wins.\n")
+	_, err = writer.WriteString("#color.overdue=bold red\n")
+	_, err = writer.WriteString("#color.due.today=bold yellow\n")
+	_, err = writer.WriteString("#color.active=black on green\n")
+	_, err = writer.WriteString("#color.priority.H=bright red\n")
+	_, err = writer.WriteString("#color.project.BigProject=magenta\n")
+	_, err = writer.WriteString("#color.tag.waiting=bright black\n")
+	_, err = writer.WriteString("#color.precedence=active,overdue,due.today,priority,tag,project\n")
+	_, err = writer.WriteString("\n")
 	_, err = writer.WriteString("## Define sync file path and encryption passphrase.\n")
 	_, err = writer.WriteString("###### encrypt.passphrase options: actual passphrase, *=prompt, <blank>=do not encrypt.\n")
 	_, err = writer.WriteString("###### filepath includes filename. Directory must exist.\n")
@@ -284,6 +545,19 @@ func CreateDefaultConfig() error {
 	_, err = writer.WriteString("\n")
 	_, err = writer.WriteString("## Define aliases to save typing on common commands\n")
 	_, err = writer.WriteString("#alias.top2=top:pro:2 list sort:+project,+due\n")
+	_, err = writer.WriteString("## Time tracking. Allow more than one started todo. Stop the started todo when adding a 'done' todo.\n")
+	_, err = writer.WriteString("#timetrack.multiple=false\n")
+	_, err = writer.WriteString("#timetrack.autostop=false\n")
+	_, err = writer.WriteString("## Pomodoro work and break lengths in minutes\n")
+	_, err = writer.WriteString("#pomo.work=25\n")
+	_, err = writer.WriteString("#pomo.break=5\n")
+	_, err = writer.WriteString("## Reminders fired by 'todo remind' before todos are due, scheduled or stop waiting. Without a cmd, reminders are printed.\n")
+	_, err = writer.WriteString("#remind.lead=1h,1d\n")
+	_, err = writer.WriteString("#remind.cmd=notify-send Todo\n")
+	_, err = writer.WriteString("#remind.events=due,scheduled,wait\n")
+	_, err = writer.WriteString("#remind.interval=1\n")
+	_, err = writer.WriteString("## The built-in 'next' report can be overridden like any other report\n")
+	_, err = writer.WriteString("#report.next.filter=-completed,-#BLOCKED,top:pro:2:none\n")
 	_, err = writer.WriteString("\n")
 	_, err = writer.WriteString("## Define named view filters that can be applied by default\n")
 	_, err = writer.WriteString("#view.work.filter=@Work\n")
@@ -308,17 +582,11 @@ func CreateDefaultConfig() error {
 	_, err = writer.WriteString("# File paths\n")
 	_, err = writer.WriteString("#open.file.regex=((\\/|\\.\\/|~\\/|\\w:\\/\\w)\\S+)\n")
 
-	err = writer.Flush()
-	if err != nil {
-		return nil
-	} else {
-		return err
-	}
-	return nil
+	return writer.Flush()
 }
 
 func getConfigLocation() string {
-	localrepo := ".todorc"
+	localrepo := filepath.Join(currentRepoDir(), ".todorc")
 	usr, _ := user.Current()
 	homerepo := fmt.Sprintf("%s/.todorc", usr.HomeDir)
 	_, ferr := os.Stat(localrepo)
@@ -338,9 +606,54 @@ type Report struct {
 	Sorter      *TodoSorter
 	Group       string
 	PrintNotes  bool
+	Explain     bool
+	Checklist   bool  //Markdown and HTML output lists todos as checklists rather than tables
+	Widths      []int //Max width of each column on screen. 0 for no limit.
+	Wrap        bool  //Wrap columns wider than their max width (or the subject if the terminal is too narrow) rather than truncate
+}
+
+//Index of the subject column, which is narrowed to fit the terminal. -1 (the last column) if there is none.
+func (r *Report) SubjectColumn() int {
+	for i, col := range r.Columns {
+		if col == "subject" {
+			return i
+		}
+	}
+	return -1
+}
+
+//Built-in 'next' report of the most actionable todos. Excludes waiting, blocked and completed todos,
+//sorts by urgency combined with the manual order and keeps the top 3 per project. Columns are
+//dropped as the terminal narrows. Each report.next.* setting in .todorc (rc) replaces the built-in one.
+func NewNextReport(width int, rc map[string]string) *Report {
+	next := map[string]string{
+		"description": "Most actionable todos, top 3 per project",
+		"filter":      "-completed,-#BLOCKED,top:pro:3:none",
+		"sort":        "-next,+id",
+		"columns":     "id,urgency,due,context,project,tags,subject",
+		"headers":     "Id,Urg,Due,Context,Project,Tags,Subject",
+	}
+	if width > 0 && width < 70 {
+		next["columns"] = "id,project,subject"
+		next["headers"] = "Id,Project,Subject"
+	} else if width > 0 && width < 100 {
+		next["columns"] = "id,urgency,due,project,subject"
+		next["headers"] = "Id,Urg,Due,Project,Subject"
+	}
+	//The built-in headers only fit the built-in columns
+	if _, ok := rc["columns"]; ok {
+		next["headers"] = rc["columns"]
+	}
+	for key, value := range rc {
+		next[key] = value
+	}
+	r := &Report{}
+	r.Init(next)
+	return r
 }
 
-func (r *Report) Init(rc map[string]string) {
+//Set up the report from its report.<name>.<key> values. Returns ErrBadConfig if a value can't be parsed.
+func (r *Report) Init(rc map[string]string) error {
 	/*
 		report.default.description="Default report of pending todos"
 		report.default.columns=id,completed,due,context,project,subject
@@ -388,11 +701,29 @@ func (r *Report) Init(rc map[string]string) {
 	if tmp, ok := rc["notes"]; ok {
 		doPrint, err := strconv.ParseBool(tmp)
 		if err != nil {
-			fmt.Println("Error parsing bool from report configuration: ", rc["notes"])
-			os.Exit(1)
+			return newError(ErrBadConfig, "Error parsing bool from report configuration: %s", rc["notes"])
 		}
 		r.PrintNotes = doPrint
 	}
+	//Max column widths
+	if tmp, ok := rc["widths"]; ok && tmp != "" {
+		for _, val := range strings.Split(tmp, ",") {
+			width, err := strconv.Atoi(strings.TrimSpace(val))
+			if err != nil {
+				return newError(ErrBadConfig, "Error parsing widths from report configuration: %s", tmp)
+			}
+			r.Widths = append(r.Widths, width)
+		}
+	}
+	//Wrap or truncate
+	if tmp, ok := rc["wrap"]; ok {
+		wrap, err := strconv.ParseBool(tmp)
+		if err != nil {
+			return newError(ErrBadConfig, "Error parsing bool from report configuration: %s", rc["wrap"])
+		}
+		r.Wrap = wrap
+	}
+	return nil
 }
 
 func (c *Config) GetAlias(alias string) (string, bool) {
@@ -0,0 +1,72 @@
+package todolist
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadConfigLayers(t *testing.T) {
+	assert := assert.New(t)
+	dir, _ := ioutil.TempDir("", "config")
+	defer os.RemoveAll(dir)
+	wd, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(wd)
+	os.MkdirAll(filepath.Join(dir, "xdg", "todo"), 0755)
+	os.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "xdg"))
+	defer os.Unsetenv("XDG_CONFIG_HOME")
+	os.Setenv("TODO_POMO_BREAK", "8")
+	defer os.Unsetenv("TODO_POMO_BREAK")
+
+	ioutil.WriteFile(".todos.json", []byte("[]"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "xdg", "todo", "config"), []byte("pomo.work=10\npomo.break=6\nremind.lead=1d\n"), 0644)
+	ioutil.WriteFile(".todorc", []byte("pomo.work=20\ninclude=team/team.rc\n"), 0644)
+	os.Mkdir("team", 0755)
+	ioutil.WriteFile(filepath.Join("team", "team.rc"), []byte("report.team.columns=id,subject\nremind.lead=1h\n"), 0644)
+
+	cfg, err := NewConfigStore().Load("remind.lead=2h")
+	assert.Nil(err)
+	assert.Equal(20*time.Minute, cfg.PomoWork)
+	assert.Equal(ConfigSource{File: ".todorc", Line: 1}, cfg.Sources["pomo.work"])
+	assert.Equal(8*time.Minute, cfg.PomoBreak)
+	assert.Equal("env TODO_POMO_BREAK", cfg.Sources["pomo.break"].String())
+	assert.Equal([]time.Duration{2 * time.Hour}, cfg.RemindLeads)
+	assert.Equal("command line", cfg.Sources["remind.lead"].String())
+	assert.Equal("id,subject", cfg.Reports["team"]["columns"])
+	assert.Equal("team/team.rc:1", cfg.Sources["report.team.columns"].String())
+	assert.Equal(".todorc", cfg.File)
+
+	//Priorities, urgency coefficients and colors are kept on the config each load returns
+	cfg, err = NewConfigStore().Load("priority=A,B", "urgency.due.coefficient=3", "color.id=red")
+	assert.Nil(err)
+	assert.Equal(map[string]int{"A": 0, "B": 1}, cfg.Priority)
+	assert.Equal(3.0, cfg.UrgencyCoefficients["due"])
+	assert.Equal(map[string]string{"id": "red"}, cfg.Colors)
+	cfg, _ = NewConfigStore().Load()
+	assert.Equal(DefaultPriority(), cfg.Priority)
+	assert.Equal(DefaultUrgencyCoefficients(), cfg.UrgencyCoefficients)
+	assert.Empty(cfg.Colors)
+
+	//Includes that loop or are missing stop loading
+	ioutil.WriteFile(filepath.Join("team", "team.rc"), []byte("include=../.todorc\n"), 0644)
+	cfg, err = NewConfigStore().Load()
+	assert.True(errors.Is(err, ErrBadConfig))
+	assert.Contains(err.Error(), "Include loop")
+	assert.Equal(err, cfg.ReadErr)
+	ioutil.WriteFile(filepath.Join("team", "team.rc"), []byte("include=missing.rc\n"), 0644)
+	_, err = NewConfigStore().Load()
+	assert.True(errors.Is(err, ErrBadConfig))
+
+	assert.Equal("sync.filepath", configEnvKey("TODO_SYNC_FILEPATH"))
+	assert.False(isConfigKey(configEnvKey("TODO_NOW")))
+	assert.False(isConfigKey(configEnvKey(ConfigEnvName("alias.mylist"))))
+	rest, overrides := SplitOverrides([]string{"rc.color=off", "+Work", "list", "rc.report.default.group=context"})
+	assert.Equal([]string{"+Work", "list"}, rest)
+	assert.Equal([]string{"color=off", "report.default.group=context"}, overrides)
+}
@@ -0,0 +1,410 @@
+package todolist
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//Prints reports and listings as plain data (no colors or padding) for other programs, e.g. jq or a spreadsheet.
+//Values are raw: dates as stored (RFC3339), effort in days, time spent in decimal hours and sets as lists.
+//Embedded by the JSON, CSV and TSV printers, which only differ in how the table of values is written.
+type dataPrinter struct {
+	Writer     io.Writer
+	Clock      Clock
+	writeTable func(headers []string, rows [][]interface{}) error
+}
+
+type JSONPrinter struct {
+	dataPrinter
+}
+
+type CSVPrinter struct {
+	dataPrinter
+}
+
+type TSVPrinter struct {
+	dataPrinter
+}
+
+//Note, lines of text (i.e. notes) are kept distinct from sets so they can be joined by newline rather than comma.
+type lines []string
+
+func NewJSONPrinter(w io.Writer, clock Clock) *JSONPrinter {
+	p := &JSONPrinter{}
+	p.dataPrinter = dataPrinter{Writer: w, Clock: clock, writeTable: p.writeTable}
+	return p
+}
+
+func NewCSVPrinter(w io.Writer, clock Clock) *CSVPrinter {
+	p := &CSVPrinter{}
+	p.dataPrinter = dataPrinter{Writer: w, Clock: clock, writeTable: p.writeTable}
+	return p
+}
+
+func NewTSVPrinter(w io.Writer, clock Clock) *TSVPrinter {
+	p := &TSVPrinter{}
+	p.dataPrinter = dataPrinter{Writer: w, Clock: clock, writeTable: p.writeTable}
+	return p
+}
+
+//Array of objects, one per row, keyed by the headers in column order
+func (p *JSONPrinter) writeTable(headers []string, rows [][]interface{}) error {
+	var buf bytes.Buffer
+	buf.WriteString("[")
+	for i, row := range rows {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n  {")
+		for j, header := range headers {
+			if j > 0 {
+				buf.WriteString(", ")
+			}
+			key, err := marshalJSON(header)
+			if err != nil {
+				return err
+			}
+			val, err := marshalJSON(row[j])
+			if err != nil {
+				return err
+			}
+			buf.Write(key)
+			buf.WriteString(": ")
+			buf.Write(val)
+		}
+		buf.WriteString("}")
+	}
+	if len(rows) > 0 {
+		buf.WriteString("\n")
+	}
+	buf.WriteString("]\n")
+	_, err := p.Writer.Write(buf.Bytes())
+	return err
+}
+
+//Same as json.Marshal, without escaping <, > and & (e.g. in subjects and notes)
+func marshalJSON(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+func (p *CSVPrinter) writeTable(headers []string, rows [][]interface{}) error {
+	writer := csv.NewWriter(p.Writer)
+	if err := writer.Write(headers); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := writer.Write(textValues(row)); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+//Tab separated, one row per line. Tabs and line breaks within values are replaced by spaces, since TSV has no quoting.
+func (p *TSVPrinter) writeTable(headers []string, rows [][]interface{}) error {
+	clean := strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ", "\r", " ")
+	var buf bytes.Buffer
+	writeRow := func(vals []string) {
+		for i, val := range vals {
+			vals[i] = clean.Replace(val)
+		}
+		buf.WriteString(strings.Join(vals, "\t"))
+		buf.WriteString("\n")
+	}
+	writeRow(append([]string{}, headers...))
+	for _, row := range rows {
+		writeRow(textValues(row))
+	}
+	_, err := p.Writer.Write(buf.Bytes())
+	return err
+}
+
+//Values as text for CSV and TSV
+func textValues(row []interface{}) []string {
+	vals := []string{}
+	for _, v := range row {
+		switch val := v.(type) {
+		case nil:
+			vals = append(vals, "")
+		case string:
+			vals = append(vals, val)
+		case int:
+			vals = append(vals, strconv.Itoa(val))
+		case float64:
+			vals = append(vals, strconv.FormatFloat(val, 'f', -1, 64))
+		case bool:
+			vals = append(vals, strconv.FormatBool(val))
+		case []string:
+			vals = append(vals, strings.Join(val, ","))
+		case lines:
+			vals = append(vals, strings.Join(val, "\n"))
+		case map[string]int:
+			keys := []string{}
+			for key := range val {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			pairs := []string{}
+			for _, key := range keys {
+				pairs = append(pairs, key+":"+strconv.Itoa(val[key]))
+			}
+			vals = append(vals, strings.Join(pairs, ","))
+		default:
+			vals = append(vals, fmt.Sprint(val))
+		}
+	}
+	return vals
+}
+
+func (p *dataPrinter) write(headers []string, rows [][]interface{}) error {
+	if err := p.writeTable(headers, rows); err != nil {
+		return fmt.Errorf("Error writing output: %w", err)
+	}
+	return nil
+}
+
+//Print the report columns for each todo. If the report is grouped by a project or context not among its columns,
+//the group is added as the first column.
+func (p *dataPrinter) PrintReport(report *Report, todos []*Todo) error {
+	report.Sorter.Sort(todos, p.Clock.Now())
+	filtered, err := NewToDoFilter(todos, p.Clock).Filter(report.Filters)
+	if err != nil {
+		return err
+	}
+	headers := []string{}
+	cols := []string{}
+	groupCol := ""
+	if report.Group != "" {
+		groupCol = "context"
+		if report.Group == "project" {
+			groupCol = "project"
+		}
+		for _, col := range report.Columns {
+			if col == groupCol {
+				groupCol = ""
+			}
+		}
+	}
+	if groupCol != "" {
+		cols = append(cols, groupCol)
+		headers = append(headers, strings.Title(groupCol))
+	}
+	for i, col := range report.Columns {
+		if isReportColumn(col) {
+			cols = append(cols, col)
+			headers = append(headers, report.Headers[i])
+		}
+	}
+	rows := [][]interface{}{}
+	for _, todo := range filtered {
+		row := []interface{}{}
+		for _, col := range cols {
+			val, _ := columnValue(todo, col, todos, p.Clock.Now())
+			row = append(row, val)
+		}
+		rows = append(rows, row)
+	}
+	return p.write(headers, rows)
+}
+
+func isReportColumn(col string) bool {
+	_, ok := columnValue(&Todo{}, col, []*Todo{}, time.Time{})
+	return ok
+}
+
+//Raw value of a report column. Returns false if the column is unknown.
+func columnValue(todo *Todo, col string, todos []*Todo, now time.Time) (interface{}, bool) {
+	switch col {
+	case "id":
+		return todo.Id, true
+	case "completed":
+		return todo.Completed, true
+	case "age":
+		return daysSince(todo.CreatedDate, now), true
+	case "idle":
+		return daysSince(todo.ModifiedDate, now), true
+	case "due":
+		return dateValue(todo.Due), true
+	case "scheduled":
+		return dateValue(todo.Scheduled), true
+	case "done":
+		return dateValue(todo.CompletedDate), true
+	case "modified":
+		return dateValue(todo.ModifiedDate), true
+	case "priority":
+		return todo.Priority, true
+	case "effort":
+		return todo.EffortDays, true
+	case "exec_order":
+		return todo.ExecOrder, true
+	case "urgency":
+		return round2(todo.Urgency), true
+	case "spent":
+		return round2(todo.TimeSpent(now).Hours()), true
+	case "pomodoros":
+		return todo.Pomodoros(), true
+	case "ord:all":
+		return ordinalValue(todo, "all"), true
+	case "ord:pro":
+		if len(todo.Projects) > 0 {
+			return ordinalValue(todo, "+"+todo.Projects[0]), true
+		}
+		return nil, true
+	case "ord:ctx":
+		if len(todo.Contexts) > 0 {
+			return ordinalValue(todo, "@"+todo.Contexts[0]), true
+		}
+		return nil, true
+	case "notes":
+		return len(todo.Notes), true
+	case "context":
+		return setValue(todo.Contexts), true
+	case "project":
+		return setValue(todo.Projects), true
+	case "tags":
+		return setValue(todo.Tags), true
+	case "vtags":
+		return setValue(todo.GetVirtualTags(todos, now)), true
+	case "subject":
+		return todo.Subject, true
+	}
+	return nil, false
+}
+
+func ordinalValue(todo *Todo, key string) interface{} {
+	if ord, ok := todo.Ordinals[key]; ok {
+		return ord
+	}
+	return nil
+}
+
+//Blank dates are null in JSON rather than an empty string
+func dateValue(date string) interface{} {
+	if date == "" {
+		return nil
+	}
+	return date
+}
+
+//Empty rather than null in JSON
+func setValue(vals []string) []string {
+	if vals == nil {
+		return []string{}
+	}
+	return vals
+}
+
+func round2(val float64) float64 {
+	return math.Round(val*100) / 100
+}
+
+//Print the count of todos for each project, context or tag, sorted by name
+func (p *dataPrinter) PrintSetCounts(set string, m map[string]int) error {
+	names := []string{}
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	rows := [][]interface{}{}
+	for _, name := range names {
+		rows = append(rows, []interface{}{name, m[name]})
+	}
+	return p.write([]string{strings.TrimSuffix(set, "s"), "Count"}, rows)
+}
+
+//Print all details of each todo, as shown by the print command
+func (p *dataPrinter) PrintTodoDetail(todos []*Todo) error {
+	headers := []string{"ID", "UUID", "Subject", "Contexts", "Projects", "Tags", "Due", "Priority", "EffortDays", "Ordinals",
+		"Completed", "Status", "CreatedDate", "ModifiedDate", "CompletedDate", "Until", "Wait", "Scheduled", "Start", "Spent", "Recur", "Depends", "Notes"}
+	rows := [][]interface{}{}
+	for _, todo := range todos {
+		ordinals := todo.Ordinals
+		if ordinals == nil {
+			ordinals = map[string]int{}
+		}
+		rows = append(rows, []interface{}{todo.Id, todo.Uuid, todo.Subject, setValue(todo.Contexts), setValue(todo.Projects), setValue(todo.Tags),
+			dateValue(todo.Due), todo.Priority, todo.EffortDays, ordinals, todo.Completed, todo.Status, dateValue(todo.CreatedDate),
+			dateValue(todo.ModifiedDate), dateValue(todo.CompletedDate), dateValue(todo.Until), dateValue(todo.Wait), dateValue(todo.Scheduled), dateValue(todo.Start),
+			round2(todo.TimeSpent(p.Clock.Now()).Hours()), todo.Recur, setValue(todo.Depends), lines(setValue(todo.Notes))})
+	}
+	return p.write(headers, rows)
+}
+
+//Print one row per group and period. Charts are not supported, so chart is ignored.
+func (p *dataPrinter) PrintStats(filtered []*Todo, groupBy string, sumBy string, cols []string, chart bool, rangeTimes []time.Time) error {
+	headers, rows := statsTable(filtered, groupBy, sumBy, cols, rangeTimes, p.Clock)
+	for _, row := range rows {
+		for i, val := range row {
+			if spent, ok := val.(time.Duration); ok {
+				row[i] = round2(spent.Hours())
+			}
+		}
+	}
+	return p.write(headers, rows)
+}
+
+//Stats as rows of group, period start date and the requested columns (cols: modifier). Groups are sorted by name.
+func statsTable(filtered []*Todo, groupBy string, sumBy string, cols []string, rangeTimes []time.Time, clock Clock) ([]string, [][]interface{}) {
+	sum, _ := parseSumBy(sumBy)
+	statsData := NewStatsData(clock)
+	statsData.CalcStats(filtered, groupBy, sum, rangeTimes)
+	groupedStats := statsData.GetSortedGroups()
+	sort.Slice(groupedStats, func(i, j int) bool {
+		return groupedStats[i].Group < groupedStats[j].Group
+	})
+
+	headers := []string{"Group", "Period"}
+	statCols := []string{}
+	for _, col := range cols {
+		if header, ok := statsColumnHeader(col); ok {
+			headers = append(headers, header)
+			statCols = append(statCols, col)
+		}
+	}
+	rows := [][]interface{}{}
+	for _, sg := range groupedStats {
+		for _, stat := range sg.Stats {
+			row := []interface{}{sg.Group, timeToSimpleDateString(stat.PeriodStartDate)}
+			for _, col := range statCols {
+				row = append(row, statValue(stat, col))
+			}
+			rows = append(rows, row)
+		}
+	}
+	return headers, rows
+}
+
+func statValue(stat *TodoStat, col string) interface{} {
+	switch col {
+	case "p":
+		return stat.Pending
+	case "a":
+		return stat.Added
+	case "m":
+		return stat.Modified
+	case "c":
+		return stat.Completed
+	case "ar":
+		return stat.Archived
+	case "ts":
+		return stat.Spent
+	case "po":
+		return stat.Pomodoros
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package todolist
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataPrinters(t *testing.T) {
+	assert := assert.New(t)
+	clock := FixedClock{Time: time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)}
+	created := timeToString(clock.Now().AddDate(0, 0, -3))
+	todos := []*Todo{
+		&Todo{Id: 1, Subject: "Write <report>, draft", Projects: []string{"Acme"}, Due: "2026-10-15T00:00:00Z", EffortDays: 0.5, Status: "Pending", CreatedDate: created},
+		&Todo{Id: 2, Subject: "Call\tBob", Contexts: []string{"phone"}, EffortDays: 2, Status: "Pending", CreatedDate: created},
+	}
+	report := &Report{
+		Columns: []string{"id", "age", "due", "effort", "project", "subject"},
+		Headers: []string{"Id", "Age", "Due", "Effort", "Project", "Subject"},
+		Sorter:  NewTodoSorter("id"),
+		Filters: []string{},
+	}
+
+	var buf bytes.Buffer
+	NewJSONPrinter(&buf, clock).PrintReport(report, todos)
+	assert.Equal("[\n"+
+		`  {"Id": 1, "Age": 3, "Due": "2026-10-15T00:00:00Z", "Effort": 0.5, "Project": ["Acme"], "Subject": "Write <report>, draft"},`+"\n"+
+		`  {"Id": 2, "Age": 3, "Due": null, "Effort": 2, "Project": [], "Subject": "Call\tBob"}`+"\n]\n", buf.String())
+
+	buf.Reset()
+	NewCSVPrinter(&buf, clock).PrintReport(report, todos)
+	assert.Equal("Id,Age,Due,Effort,Project,Subject\n1,3,2026-10-15T00:00:00Z,0.5,Acme,\"Write <report>, draft\"\n2,3,,2,,Call\tBob\n", buf.String())
+
+	//Grouped by context, which is not a report column, so it is added first
+	report.Group = "context"
+	buf.Reset()
+	NewTSVPrinter(&buf, clock).PrintReport(report, todos)
+	assert.Equal("Context\tId\tAge\tDue\tEffort\tProject\tSubject\n\t1\t3\t2026-10-15T00:00:00Z\t0.5\tAcme\tWrite <report>, draft\nphone\t2\t3\t\t2\t\tCall Bob\n", buf.String())
+
+	buf.Reset()
+	NewCSVPrinter(&buf, clock).PrintSetCounts("Projects", map[string]int{"Beta": 1, "Acme": 2})
+	assert.Equal("Project,Count\nAcme,2\nBeta,1\n", buf.String())
+
+	buf.Reset()
+	NewJSONPrinter(&buf, clock).PrintReport(&Report{Columns: []string{"id"}, Headers: []string{"Id"}, Sorter: NewTodoSorter("id"), Filters: []string{"9"}}, todos)
+	assert.Equal("[]\n", buf.String())
+
+	_, err := NewPrinter("xml", &buf, clock, nil)
+	assert.NotNil(err)
+}
@@ -13,9 +13,9 @@ type DateFilter struct {
 	Parser Parser
 }
 
-func NewDateFilter(todos []*Todo) *DateFilter {
-	now := Now
-	return &DateFilter{Todos: todos, Now: now}
+//Filter todos by dates relative to now on the clock
+func NewDateFilter(todos []*Todo, clock Clock) *DateFilter {
+	return &DateFilter{Todos: todos, Now: clock.Now(), Parser: Parser{Clock: clock}}
 }
 
 func filterOnDue(todo *Todo) time.Time {
@@ -30,6 +30,18 @@ func filterOnModifiedDate(todo *Todo) time.Time {
 	return stringToTime(todo.ModifiedDate)
 }
 
+func filterOnScheduled(todo *Todo) time.Time {
+	return stringToTime(todo.Scheduled)
+}
+
+func filterOnWait(todo *Todo) time.Time {
+	return stringToTime(todo.Wait)
+}
+
+func filterOnUntil(todo *Todo) time.Time {
+	return stringToTime(todo.Until)
+}
+
 func (f *DateFilter) FilterExpired() []*Todo {
 	//dateInfo will be a simple date format (2018-12-01) or a day of week (sun-sat) or relative day reference (ie. today, tomorrow, yesterday)
 	var ret []*Todo
@@ -48,7 +60,7 @@ func (f *DateFilter) FilterWaiting(filters []string) ([]*Todo, []string) {
 	index := -1
 	var todos []*Todo
 	for i, filter := range filters {
-		if strings.HasPrefix(filter, "wait") {
+		if strings.HasPrefix(filter, "wait") || filter == "#WAITING" {
 			index = i
 			todos = f.FilterIncludeWaiting()
 			break
@@ -127,30 +139,35 @@ func (f *DateFilter) ageInDays(todo *Todo) int {
 		tmpTime, err := time.Parse(time.RFC3339, todo.CreatedDate)
 		if err == nil {
 			createTime := tmpTime.Unix()
-			now := Now.Unix()
-			diff := now - createTime
+			diff := f.Now.Unix() - createTime
 			days = (int)(diff / (60 * 60 * 24))
 		}
 	}
 	return days
 }
 
-func (f *DateFilter) FilterDueDate(filters []string) ([]*Todo, []string) {
+func (f *DateFilter) FilterDueDate(filters []string) ([]*Todo, []string, error) {
 	r, _ := regexp.Compile(`due:([^:]+)?(:(.*))?`)
 	return f.FilterDateRange(filters, r, filterOnDue)
 }
 
-func (f *DateFilter) FilterDoneDate(filters []string) ([]*Todo, []string) {
+func (f *DateFilter) FilterScheduledDate(filters []string) ([]*Todo, []string, error) {
+	r, _ := regexp.Compile(`^sched(?:uled)?:([^:]+)?(:(.*))?`)
+	return f.FilterDateRange(filters, r, filterOnScheduled)
+}
+
+func (f *DateFilter) FilterDoneDate(filters []string) ([]*Todo, []string, error) {
 	r, _ := regexp.Compile(`done:([^:]+)?(:(.*))?`)
 	return f.FilterDateRange(filters, r, filterOnCompletedDate)
 }
 
-func (f *DateFilter) FilterModDate(filters []string) ([]*Todo, []string) {
+func (f *DateFilter) FilterModDate(filters []string) ([]*Todo, []string, error) {
 	r, _ := regexp.Compile(`mod:([^:]+)?(:(.*))?`)
 	return f.FilterDateRange(filters, r, filterOnModifiedDate)
 }
 
-func (f *DateFilter) FilterDateRange(filters []string, regex *regexp.Regexp, dateConvFunc func(*Todo) time.Time) ([]*Todo, []string) {
+//Returns ErrBadDate if the date of the filter can't be parsed
+func (f *DateFilter) FilterDateRange(filters []string, regex *regexp.Regexp, dateConvFunc func(*Todo) time.Time) ([]*Todo, []string, error) {
 
 	var todos []*Todo
 	index := -1
@@ -161,6 +178,7 @@ loop:
 		var d1 string
 		var d2 string
 		var times []time.Time
+		var err error
 		//r, _ := regexp.Compile(`due:([^:]+)?(:(.*))?`)
 		matches := regex.FindStringSubmatch(filter)
 		if len(matches) > 0 {
@@ -169,10 +187,10 @@ loop:
 			//Handle special values not mapping to dates
 			switch {
 			case strings.HasPrefix(d1, "any"):
-				todos = f.filterAnyDueDate()
+				todos = f.filterAnyDate(dateConvFunc)
 				break loop
 			case strings.HasPrefix(d1, "non"):
-				todos = f.filterNoDueDate()
+				todos = f.filterNoDate(dateConvFunc)
 				break loop
 			case strings.HasPrefix(d1, "overdue"):
 				todos = f.filterOverdue(bod(f.Now))
@@ -182,9 +200,12 @@ loop:
 			//Handle if there is a date range
 			if strings.HasPrefix(matches[2], ":") {
 				d2 = strings.ToLower(matches[3])
-				times = translateToDates(f.Now, d1, d2)
+				times, err = translateToDates(f.Now, d1, d2)
 			} else {
-				times = translateToDates(f.Now, d1)
+				times, err = translateToDates(f.Now, d1)
+			}
+			if err != nil {
+				return nil, filters, err
 			}
 			len := len(times)
 			switch len {
@@ -207,23 +228,24 @@ loop:
 	} else {
 		todos = f.Todos
 	}
-	return todos, filters
+	return todos, filters, nil
 }
 
-func (f *DateFilter) filterAnyDueDate() []*Todo {
+//Todos with the date set. Unset dates convert to 1900-01-01.
+func (f *DateFilter) filterAnyDate(filterOn func(*Todo) time.Time) []*Todo {
 	var ret []*Todo
 	for _, todo := range f.Todos {
-		if len(todo.Due) > 6 {
+		if filterOn(todo).Year() > 1900 {
 			ret = append(ret, todo)
 		}
 	}
 	return ret
 }
 
-func (f *DateFilter) filterNoDueDate() []*Todo {
+func (f *DateFilter) filterNoDate(filterOn func(*Todo) time.Time) []*Todo {
 	var ret []*Todo
 	for _, todo := range f.Todos {
-		if len(todo.Due) < 6 {
+		if filterOn(todo).Year() <= 1900 {
 			ret = append(ret, todo)
 		}
 	}
@@ -7,17 +7,21 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+//A Wednesday, so the days around it are in the same week
+var dateFilterClock = FixedClock{Time: time.Date(2026, 10, 14, 12, 0, 0, 0, time.Local)}
+
 func TestFilterToday(t *testing.T) {
 	assert := assert.New(t)
+	now := dateFilterClock.Now()
 
 	var todos []*Todo
-	todayTodo := &Todo{Id: 1, Subject: "one", Due: Now.Format("2006-01-02")}
-	tomorrowTodo := &Todo{Id: 2, Subject: "two", Due: Now.AddDate(0, 0, 1).Format("2006-01-02")}
+	todayTodo := &Todo{Id: 1, Subject: "one", Due: timeToString(bod(now))}
+	tomorrowTodo := &Todo{Id: 2, Subject: "two", Due: timeToString(bod(now).AddDate(0, 0, 1))}
 	todos = append(todos, todayTodo)
 	todos = append(todos, tomorrowTodo)
 
-	filter := NewDateFilter(todos)
-	filtered := filter.filterDueToday(Now)
+	filter := NewDateFilter(todos, dateFilterClock)
+	filtered, _, _ := filter.FilterDueDate([]string{"due:tod"})
 
 	assert.Equal(1, len(filtered))
 	assert.Equal(1, filtered[0].Id)
@@ -25,15 +29,16 @@ func TestFilterToday(t *testing.T) {
 
 func TestFilterTomorrow(t *testing.T) {
 	assert := assert.New(t)
+	now := dateFilterClock.Now()
 
 	var todos []*Todo
-	todayTodo := &Todo{Id: 1, Subject: "one", Due: Now.Format("2006-01-02")}
-	tomorrowTodo := &Todo{Id: 2, Subject: "two", Due: Now.AddDate(0, 0, 1).Format("2006-01-02")}
+	todayTodo := &Todo{Id: 1, Subject: "one", Due: timeToString(bod(now))}
+	tomorrowTodo := &Todo{Id: 2, Subject: "two", Due: timeToString(bod(now).AddDate(0, 0, 1))}
 	todos = append(todos, todayTodo)
 	todos = append(todos, tomorrowTodo)
 
-	filter := NewDateFilter(todos)
-	filtered := filter.filterDueTomorrow(Now)
+	filter := NewDateFilter(todos, dateFilterClock)
+	filtered, _, _ := filter.FilterDueDate([]string{"due:tom"})
 
 	assert.Equal(1, len(filtered))
 	assert.Equal(2, filtered[0].Id)
@@ -41,28 +46,29 @@ func TestFilterTomorrow(t *testing.T) {
 
 func TestFilterCompletedToday(t *testing.T) {
 	assert := assert.New(t)
+	now := dateFilterClock.Now()
 
 	var todos []*Todo
-	todoNo1 := &Todo{Id: 1, Subject: "one", Due: Now.Format("2006-01-02")}
-	todoNo2 := &Todo{Id: 2, Subject: "two", Due: Now.Format("2006-01-02")}
+	todoNo1 := &Todo{Id: 1, Subject: "one", Due: timeToString(bod(now))}
+	todoNo2 := &Todo{Id: 2, Subject: "two", Due: timeToString(bod(now))}
 
 	todos = append(todos, todoNo1)
 	todos = append(todos, todoNo2)
 
-	filter := NewDateFilter(todos)
-	filtered := filter.filterCompletedToday(Now)
+	filter := NewDateFilter(todos, dateFilterClock)
+	filtered := filter.filterCompletedToday(now)
 
 	assert.Equal(0, len(filtered))
 
-	todoNo1.Complete()
-	filtered = filter.filterCompletedToday(Now)
+	todoNo1.Complete(now)
+	filtered = filter.filterCompletedToday(now)
 
 	assert.Equal(1, len(filtered))
 	assert.Equal(1, filtered[0].Id)
 
 	todoNo1.Uncomplete()
-	todoNo2.Complete()
-	filtered = filter.filterCompletedToday(Now)
+	todoNo2.Complete(now)
+	filtered = filter.filterCompletedToday(now)
 
 	assert.Equal(1, len(filtered))
 	assert.Equal(2, filtered[0].Id)
@@ -71,17 +77,18 @@ func TestFilterCompletedToday(t *testing.T) {
 
 func TestFilterThisWeek(t *testing.T) {
 	assert := assert.New(t)
+	now := dateFilterClock.Now()
 
 	var todos []*Todo
-	lastWeekTodo := &Todo{Id: 1, Subject: "two", Due: Now.AddDate(0, 0, -7).Format("2006-01-02")}
-	todayTodo := &Todo{Id: 2, Subject: "one", Due: Now.Format("2006-01-02")}
-	nextWeekTodo := &Todo{Id: 3, Subject: "two", Due: Now.AddDate(0, 0, 8).Format("2006-01-02")}
+	lastWeekTodo := &Todo{Id: 1, Subject: "two", Due: timeToString(bod(now).AddDate(0, 0, -7))}
+	todayTodo := &Todo{Id: 2, Subject: "one", Due: timeToString(bod(now))}
+	nextWeekTodo := &Todo{Id: 3, Subject: "two", Due: timeToString(bod(now).AddDate(0, 0, 8))}
 	todos = append(todos, lastWeekTodo)
 	todos = append(todos, todayTodo)
 	todos = append(todos, nextWeekTodo)
 
-	filter := NewDateFilter(todos)
-	filtered := filter.filterThisWeek(Now)
+	filter := NewDateFilter(todos, dateFilterClock)
+	filtered, _, _ := filter.FilterDueDate([]string{"due:this_week"})
 
 	assert.Equal(1, len(filtered))
 	assert.Equal(2, filtered[0].Id)
@@ -89,22 +96,23 @@ func TestFilterThisWeek(t *testing.T) {
 
 func TestFilterCompletedThisWeek(t *testing.T) {
 	assert := assert.New(t)
+	now := dateFilterClock.Now()
 
 	var todos []*Todo
-	lastWeekTodo := &Todo{Id: 1, Subject: "two", Due: Now.AddDate(0, 0, -7).Format("2006-01-02")}
-	todayTodo := &Todo{Id: 2, Subject: "one", Due: Now.Format("2006-01-02")}
-	nextWeekTodo := &Todo{Id: 3, Subject: "two", Due: Now.AddDate(0, 0, 8).Format("2006-01-02")}
+	lastWeekTodo := &Todo{Id: 1, Subject: "two", Due: timeToString(bod(now).AddDate(0, 0, -7))}
+	todayTodo := &Todo{Id: 2, Subject: "one", Due: timeToString(bod(now))}
+	nextWeekTodo := &Todo{Id: 3, Subject: "two", Due: timeToString(bod(now).AddDate(0, 0, 8))}
 	todos = append(todos, lastWeekTodo)
 	todos = append(todos, todayTodo)
 	todos = append(todos, nextWeekTodo)
 
-	filter := NewDateFilter(todos)
-	filtered := filter.filterCompletedThisWeek(Now)
+	filter := NewDateFilter(todos, dateFilterClock)
+	filtered := filter.filterCompletedThisWeek(now)
 
 	assert.Equal(0, len(filtered))
 
-	todayTodo.Complete()
-	filtered = filter.filterCompletedThisWeek(Now)
+	todayTodo.Complete(now)
+	filtered = filter.filterCompletedThisWeek(now)
 
 	assert.Equal(1, len(filtered))
 	assert.Equal(2, filtered[0].Id)
@@ -113,18 +121,19 @@ func TestFilterCompletedThisWeek(t *testing.T) {
 
 func TestFilterOverdue(t *testing.T) {
 	assert := assert.New(t)
+	now := dateFilterClock.Now()
 
 	var todos []*Todo
-	lastWeekTodo := &Todo{Id: 1, Subject: "one", Due: Now.AddDate(0, 0, -7).Format("2006-01-02")}
-	todayTodo := &Todo{Id: 2, Subject: "two", Due: bod(Now).Format("2006-01-02")}
-	tomorrowTodo := &Todo{Id: 3, Subject: "three", Due: Now.AddDate(0, 0, 1).Format("2006-01-02")}
+	lastWeekTodo := &Todo{Id: 1, Subject: "one", Due: timeToString(bod(now).AddDate(0, 0, -7))}
+	todayTodo := &Todo{Id: 2, Subject: "two", Due: timeToString(bod(now))}
+	tomorrowTodo := &Todo{Id: 3, Subject: "three", Due: timeToString(bod(now).AddDate(0, 0, 1))}
 
 	todos = append(todos, lastWeekTodo)
 	todos = append(todos, todayTodo)
 	todos = append(todos, tomorrowTodo)
 
-	filter := NewDateFilter(todos)
-	filtered := filter.filterOverdue(bod(Now))
+	filter := NewDateFilter(todos, dateFilterClock)
+	filtered := filter.filterOverdue(bod(now))
 
 	assert.Equal(1, len(filtered))
 	assert.Equal(1, filtered[0].Id)
@@ -134,18 +143,17 @@ func TestFilterDay(t *testing.T) {
 	assert := assert.New(t)
 
 	var todos []*Todo
-	df := &DateFilter{}
-	sunday := df.FindSunday(Now)
+	sunday := mostRecentSunday(dateFilterClock.Now())
 
-	mondayTodo := &Todo{Id: 1, Subject: "one", Due: sunday.AddDate(0, 0, 1).Format("2006-01-02")}
-	tuesdayTodo := &Todo{Id: 2, Subject: "two", Due: sunday.AddDate(0, 0, 2).Format("2006-01-02")}
+	mondayTodo := &Todo{Id: 1, Subject: "one", Due: timeToString(bod(sunday).AddDate(0, 0, 1))}
+	tuesdayTodo := &Todo{Id: 2, Subject: "two", Due: timeToString(bod(sunday).AddDate(0, 0, 2))}
 
 	todos = append(todos, mondayTodo)
 	todos = append(todos, tuesdayTodo)
 
-	filter := NewDateFilter(todos)
+	filter := NewDateFilter(todos, dateFilterClock)
 
-	filtered := filter.filterDay(sunday, time.Monday)
+	filtered, _, _ := filter.FilterDueDate([]string{"due:" + sunday.AddDate(0, 0, 1).Format("2006-01-02")})
 
 	assert.Equal(1, len(filtered))
 	assert.Equal(1, filtered[0].Id)
@@ -153,18 +161,17 @@ func TestFilterDay(t *testing.T) {
 
 func TestFilterAgenda(t *testing.T) {
 	assert := assert.New(t)
+	now := dateFilterClock.Now()
 
 	var todos []*Todo
 
-	completedTodo := &Todo{Id: 1, Subject: "completed", Completed: true, Due: Now.Format("2006-01-02")}
-	uncompletedTodo := &Todo{Id: 2, Subject: "uncompleted", Due: Now.Format("2006-01-02")}
+	completedTodo := &Todo{Id: 1, Subject: "completed", Status: "Pending", Completed: true, Due: timeToString(bod(now))}
+	uncompletedTodo := &Todo{Id: 2, Subject: "uncompleted", Status: "Pending", Due: timeToString(bod(now))}
 
 	todos = append(todos, completedTodo)
 	todos = append(todos, uncompletedTodo)
 
-	filter := NewDateFilter(todos)
-
-	filtered := filter.filterAgenda(Now)
+	filtered, _ := NewToDoFilter(todos, dateFilterClock).Filter([]string{"due:tod", "-completed"})
 
 	assert.Equal(1, len(filtered))
 	assert.Equal(2, filtered[0].Id)
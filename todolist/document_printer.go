@@ -0,0 +1,528 @@
+package todolist
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//Prints reports as Markdown (GitHub flavored) for status updates, e.g. pasted into an email, wiki or issue.
+//Todos are grouped by the report group (project or context) as tables, or as checklists if the report sets Checklist.
+type MarkdownPrinter struct {
+	Writer io.Writer
+	Clock  Clock
+}
+
+//Prints reports as a standalone HTML page, suitable for the body of an email. Layout is the same as the MarkdownPrinter.
+type HTMLPrinter struct {
+	Writer io.Writer
+	Clock  Clock
+}
+
+func NewMarkdownPrinter(w io.Writer, clock Clock) *MarkdownPrinter {
+	return &MarkdownPrinter{Writer: w, Clock: clock}
+}
+
+func NewHTMLPrinter(w io.Writer, clock Clock) *HTMLPrinter {
+	return &HTMLPrinter{Writer: w, Clock: clock}
+}
+
+//Todos of one project or context in a grouped report. The name is blank if the report is not grouped.
+type todoGroup struct {
+	Name  string
+	Todos []*Todo
+}
+
+//Sort, filter and group the todos as the report would on screen
+func reportGroups(report *Report, todos []*Todo, clock Clock) ([]*todoGroup, []*Todo, error) {
+	report.Sorter.Sort(todos, clock.Now())
+	filtered, err := NewToDoFilter(todos, clock).Filter(report.Filters)
+	if err != nil {
+		return nil, nil, err
+	}
+	groups := []*todoGroup{}
+	var group *todoGroup
+	for _, todo := range filtered {
+		name := ""
+		if report.Group == "project" {
+			name = strings.Join(todo.Projects, ", ")
+			if name == "" {
+				name = "No project"
+			}
+		} else if report.Group != "" {
+			name = strings.Join(todo.Contexts, ", ")
+			if name == "" {
+				name = "No context"
+			}
+		}
+		if group == nil || group.Name != name {
+			group = &todoGroup{Name: name}
+			groups = append(groups, group)
+		}
+		group.Todos = append(group.Todos, todo)
+	}
+	return groups, filtered, nil
+}
+
+//One line summary of the todos, e.g. 12 todos. 2 overdue, 3 due within a week, 4 completed.
+func reportSummary(todos []*Todo, now time.Time) string {
+	overdue, dueSoon, completed := 0, 0, 0
+	for _, todo := range todos {
+		if todo.Completed {
+			completed++
+			continue
+		}
+		if todo.Due == "" {
+			continue
+		}
+		due := stringToTime(todo.Due)
+		if isPastDue(due, now) && !isToday(due, now) {
+			overdue++
+		} else if due.Before(now.AddDate(0, 0, 7)) {
+			dueSoon++
+		}
+	}
+	return fmt.Sprintf("%d %s. %d overdue, %d due within a week, %d completed.", len(todos), pluralize(len(todos), "todo", "todos"), overdue, dueSoon, completed)
+}
+
+func reportTitle(report *Report) string {
+	//Descriptions in .todorc are often quoted
+	if description := strings.Trim(report.Description, "'\""); description != "" {
+		return description
+	}
+	return "Todos"
+}
+
+//Columns of the report that are shown, less the grouped column which is the heading
+func documentColumns(report *Report) ([]string, []string) {
+	cols := []string{}
+	headers := []string{}
+	for i, col := range report.Columns {
+		if !isReportColumn(col) {
+			continue
+		}
+		if (report.Group == "project" && col == "project") || (report.Group == "context" && col == "context") {
+			continue
+		}
+		cols = append(cols, col)
+		headers = append(headers, report.Headers[i])
+	}
+	return cols, headers
+}
+
+//Value of a column as displayed in a document, without colors
+func documentCell(todo *Todo, col string, todos []*Todo, now time.Time) string {
+	switch col {
+	case "completed":
+		if todo.Completed {
+			return "x"
+		}
+		return ""
+	case "due":
+		return documentDate(todo.Due)
+	case "scheduled":
+		return documentDate(todo.Scheduled)
+	case "done":
+		return documentDate(todo.CompletedDate)
+	case "modified":
+		return documentDate(todo.ModifiedDate)
+	case "age":
+		return strconv.Itoa(daysSince(todo.CreatedDate, now)) + "d"
+	case "idle":
+		return strconv.Itoa(daysSince(todo.ModifiedDate, now)) + "d"
+	case "effort":
+		return strconv.FormatFloat(todo.EffortDays, 'f', -1, 64) + "d"
+	case "spent":
+		return durationToString(todo.TimeSpent(now))
+	case "pomodoros":
+		if todo.Pomodoros() == 0 {
+			return ""
+		}
+	}
+	val, _ := columnValue(todo, col, todos, now)
+	if vals, ok := val.([]string); ok {
+		return strings.Join(vals, ", ")
+	}
+	return textValues([]interface{}{val})[0]
+}
+
+func documentDate(date string) string {
+	if date == "" {
+		return ""
+	}
+	return stringToTime(date).Format("Mon Jan 02")
+}
+
+//How a due date should stand out: overdue, today, tomorrow or not at all ("")
+func dueHighlight(todo *Todo, now time.Time) string {
+	if todo.Due == "" || todo.Completed {
+		return ""
+	}
+	due := stringToTime(todo.Due)
+	if isToday(due, now) {
+		return "today"
+	} else if isTomorrow(due, now) {
+		return "tomorrow"
+	} else if isPastDue(due, now) {
+		return "overdue"
+	}
+	return ""
+}
+
+func writeDocument(w io.Writer, buf *bytes.Buffer) error {
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("Error writing output: %w", err)
+	}
+	return nil
+}
+
+//Markdown
+
+func (p *MarkdownPrinter) PrintReport(report *Report, todos []*Todo) error {
+	now := p.Clock.Now()
+	groups, filtered, err := reportGroups(report, todos, p.Clock)
+	if err != nil {
+		return err
+	}
+	cols, headers := documentColumns(report)
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# %s\n\n", mdEscape(reportTitle(report)))
+	fmt.Fprintf(&buf, "_%s. %s_\n", now.Format("Mon Jan 02 2006"), reportSummary(filtered, now))
+	for _, group := range groups {
+		buf.WriteString("\n")
+		if group.Name != "" {
+			fmt.Fprintf(&buf, "## %s\n\n", mdEscape(group.Name))
+		}
+		if report.Checklist {
+			for _, todo := range group.Todos {
+				p.writeChecklistItem(&buf, todo, cols, headers, report.PrintNotes, todos)
+			}
+			continue
+		}
+		fmt.Fprintf(&buf, "| %s |\n", strings.Join(mdEscapeAll(headers), " | "))
+		fmt.Fprintf(&buf, "|%s\n", strings.Repeat(" --- |", len(headers)))
+		for _, todo := range group.Todos {
+			cells := []string{}
+			for _, col := range cols {
+				cell := p.cell(todo, col, todos)
+				if col == "subject" && report.PrintNotes {
+					//Tables can't nest lists. GitHub renders line breaks in cells.
+					for _, note := range todo.Notes {
+						cell += "<br>• " + mdEscape(note)
+					}
+				}
+				cells = append(cells, cell)
+			}
+			fmt.Fprintf(&buf, "| %s |\n", strings.Join(cells, " | "))
+		}
+	}
+	return writeDocument(p.Writer, &buf)
+}
+
+//- [ ] Subject (Due: **Mon Oct 12 (overdue)**, Context: office)
+//  - note
+func (p *MarkdownPrinter) writeChecklistItem(buf *bytes.Buffer, todo *Todo, cols []string, headers []string, notes bool, todos []*Todo) {
+	check := " "
+	if todo.Completed {
+		check = "x"
+	}
+	details := []string{}
+	for i, col := range cols {
+		if col == "subject" || col == "completed" {
+			continue
+		}
+		if cell := p.cell(todo, col, todos); cell != "" {
+			details = append(details, mdEscape(headers[i])+": "+cell)
+		}
+	}
+	fmt.Fprintf(buf, "- [%s] %s", check, mdEscape(todo.Subject))
+	if len(details) > 0 {
+		fmt.Fprintf(buf, " (%s)", strings.Join(details, ", "))
+	}
+	buf.WriteString("\n")
+	if notes {
+		for _, note := range todo.Notes {
+			fmt.Fprintf(buf, "  - %s\n", mdEscape(note))
+		}
+	}
+}
+
+func (p *MarkdownPrinter) cell(todo *Todo, col string, todos []*Todo) string {
+	cell := mdEscape(documentCell(todo, col, todos, p.Clock.Now()))
+	if col == "due" {
+		switch dueHighlight(todo, p.Clock.Now()) {
+		case "overdue":
+			return "**" + cell + " (overdue)**"
+		case "today":
+			return "**today**"
+		case "tomorrow":
+			return "*tomorrow*"
+		}
+	}
+	return cell
+}
+
+func (p *MarkdownPrinter) PrintSetCounts(set string, m map[string]int) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "| %s | Count |\n| --- | --- |\n", strings.TrimSuffix(set, "s"))
+	for _, name := range sortedKeys(m) {
+		fmt.Fprintf(&buf, "| %s | %d |\n", mdEscape(name), m[name])
+	}
+	return writeDocument(p.Writer, &buf)
+}
+
+func (p *MarkdownPrinter) PrintTodoDetail(todos []*Todo) error {
+	var buf bytes.Buffer
+	for i, todo := range todos {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(&buf, "### %d. %s\n\n", todo.Id, mdEscape(todo.Subject))
+		for _, field := range todoDetailFields(todo, p.Clock.Now()) {
+			fmt.Fprintf(&buf, "- **%s:** %s\n", field[0], mdEscape(field[1]))
+		}
+		if len(todo.Notes) > 0 {
+			buf.WriteString("- **Notes:**\n")
+			for _, note := range todo.Notes {
+				fmt.Fprintf(&buf, "  - %s\n", mdEscape(note))
+			}
+		}
+	}
+	return writeDocument(p.Writer, &buf)
+}
+
+func (p *MarkdownPrinter) PrintStats(filtered []*Todo, groupBy string, sumBy string, cols []string, chart bool, rangeTimes []time.Time) error {
+	headers, rows := documentStats(filtered, groupBy, sumBy, cols, rangeTimes, p.Clock)
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "| %s |\n", strings.Join(headers, " | "))
+	fmt.Fprintf(&buf, "|%s\n", strings.Repeat(" --- |", len(headers)))
+	for _, row := range rows {
+		fmt.Fprintf(&buf, "| %s |\n", strings.Join(mdEscapeAll(row), " | "))
+	}
+	return writeDocument(p.Writer, &buf)
+}
+
+//Escape text that would be read as Markdown formatting or break a table
+func mdEscape(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "|", "\\|", "*", "\\*", "_", "\\_", "`", "\\`", "[", "\\[", "]", "\\]", "<", "&lt;", "\n", " ").Replace(s)
+}
+
+func mdEscapeAll(vals []string) []string {
+	escaped := []string{}
+	for _, val := range vals {
+		escaped = append(escaped, mdEscape(val))
+	}
+	return escaped
+}
+
+//HTML
+
+const htmlHeader = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+</head>
+<body style="font-family: sans-serif;">
+`
+
+const htmlTable = `<table cellpadding="4" style="border-collapse: collapse;" border="1">`
+
+func (p *HTMLPrinter) PrintReport(report *Report, todos []*Todo) error {
+	now := p.Clock.Now()
+	groups, filtered, err := reportGroups(report, todos, p.Clock)
+	if err != nil {
+		return err
+	}
+	cols, headers := documentColumns(report)
+	title := html.EscapeString(reportTitle(report))
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, htmlHeader, title)
+	fmt.Fprintf(&buf, "<h1>%s</h1>\n", title)
+	fmt.Fprintf(&buf, "<p><em>%s. %s</em></p>\n", now.Format("Mon Jan 02 2006"), reportSummary(filtered, now))
+	for _, group := range groups {
+		if group.Name != "" {
+			fmt.Fprintf(&buf, "<h2>%s</h2>\n", html.EscapeString(group.Name))
+		}
+		if report.Checklist {
+			buf.WriteString("<ul style=\"list-style: none;\">\n")
+			for _, todo := range group.Todos {
+				p.writeChecklistItem(&buf, todo, cols, headers, report.PrintNotes, todos)
+			}
+			buf.WriteString("</ul>\n")
+			continue
+		}
+		buf.WriteString(htmlTable + "\n<tr>")
+		for _, header := range headers {
+			fmt.Fprintf(&buf, "<th>%s</th>", html.EscapeString(header))
+		}
+		buf.WriteString("</tr>\n")
+		for _, todo := range group.Todos {
+			buf.WriteString("<tr>")
+			for _, col := range cols {
+				cell := p.cell(todo, col, todos)
+				if col == "subject" && report.PrintNotes && len(todo.Notes) > 0 {
+					cell += htmlNotes(todo.Notes)
+				}
+				fmt.Fprintf(&buf, "<td>%s</td>", cell)
+			}
+			buf.WriteString("</tr>\n")
+		}
+		buf.WriteString("</table>\n")
+	}
+	buf.WriteString("</body>\n</html>\n")
+	return writeDocument(p.Writer, &buf)
+}
+
+func (p *HTMLPrinter) writeChecklistItem(buf *bytes.Buffer, todo *Todo, cols []string, headers []string, notes bool, todos []*Todo) {
+	check := "&#9744;"
+	if todo.Completed {
+		check = "&#9745;"
+	}
+	details := []string{}
+	for i, col := range cols {
+		if col == "subject" || col == "completed" {
+			continue
+		}
+		if documentCell(todo, col, todos, p.Clock.Now()) != "" {
+			details = append(details, html.EscapeString(headers[i])+": "+p.cell(todo, col, todos))
+		}
+	}
+	fmt.Fprintf(buf, "<li>%s %s", check, html.EscapeString(todo.Subject))
+	if len(details) > 0 {
+		fmt.Fprintf(buf, " (%s)", strings.Join(details, ", "))
+	}
+	if notes && len(todo.Notes) > 0 {
+		buf.WriteString(htmlNotes(todo.Notes))
+	}
+	buf.WriteString("</li>\n")
+}
+
+func (p *HTMLPrinter) cell(todo *Todo, col string, todos []*Todo) string {
+	cell := html.EscapeString(documentCell(todo, col, todos, p.Clock.Now()))
+	if col == "due" {
+		switch dueHighlight(todo, p.Clock.Now()) {
+		case "overdue":
+			return `<strong style="color: #c00;">` + cell + " (overdue)</strong>"
+		case "today":
+			return `<strong style="color: #c60;">today</strong>`
+		case "tomorrow":
+			return `<em>tomorrow</em>`
+		}
+	}
+	return cell
+}
+
+func htmlNotes(notes []string) string {
+	items := []string{}
+	for _, note := range notes {
+		items = append(items, "<li>"+html.EscapeString(note)+"</li>")
+	}
+	return "<ul>" + strings.Join(items, "") + "</ul>"
+}
+
+func (p *HTMLPrinter) PrintSetCounts(set string, m map[string]int) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, htmlHeader, set)
+	fmt.Fprintf(&buf, "%s\n<tr><th>%s</th><th>Count</th></tr>\n", htmlTable, strings.TrimSuffix(set, "s"))
+	for _, name := range sortedKeys(m) {
+		fmt.Fprintf(&buf, "<tr><td>%s</td><td>%d</td></tr>\n", html.EscapeString(name), m[name])
+	}
+	buf.WriteString("</table>\n</body>\n</html>\n")
+	return writeDocument(p.Writer, &buf)
+}
+
+func (p *HTMLPrinter) PrintTodoDetail(todos []*Todo) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, htmlHeader, "Todos")
+	for _, todo := range todos {
+		fmt.Fprintf(&buf, "<h3>%d. %s</h3>\n<ul>\n", todo.Id, html.EscapeString(todo.Subject))
+		for _, field := range todoDetailFields(todo, p.Clock.Now()) {
+			fmt.Fprintf(&buf, "<li><strong>%s:</strong> %s</li>\n", field[0], html.EscapeString(field[1]))
+		}
+		if len(todo.Notes) > 0 {
+			fmt.Fprintf(&buf, "<li><strong>Notes:</strong>%s</li>\n", htmlNotes(todo.Notes))
+		}
+		buf.WriteString("</ul>\n")
+	}
+	buf.WriteString("</body>\n</html>\n")
+	return writeDocument(p.Writer, &buf)
+}
+
+func (p *HTMLPrinter) PrintStats(filtered []*Todo, groupBy string, sumBy string, cols []string, chart bool, rangeTimes []time.Time) error {
+	headers, rows := documentStats(filtered, groupBy, sumBy, cols, rangeTimes, p.Clock)
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, htmlHeader, "Stats")
+	buf.WriteString(htmlTable + "\n<tr>")
+	for _, header := range headers {
+		fmt.Fprintf(&buf, "<th>%s</th>", header)
+	}
+	buf.WriteString("</tr>\n")
+	for _, row := range rows {
+		buf.WriteString("<tr>")
+		for _, val := range row {
+			fmt.Fprintf(&buf, "<td>%s</td>", html.EscapeString(val))
+		}
+		buf.WriteString("</tr>\n")
+	}
+	buf.WriteString("</table>\n</body>\n</html>\n")
+	return writeDocument(p.Writer, &buf)
+}
+
+//Shared by both printers
+
+func sortedKeys(m map[string]int) []string {
+	keys := []string{}
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+//Name and value of the fields shown for a todo by the print command, less the id, subject and notes
+func todoDetailFields(todo *Todo, now time.Time) [][2]string {
+	fields := [][2]string{
+		{"Projects", strings.Join(todo.Projects, ", ")},
+		{"Contexts", strings.Join(todo.Contexts, ", ")},
+		{"Tags", strings.Join(todo.Tags, ", ")},
+		{"Due", documentDate(todo.Due)},
+		{"Priority", todo.Priority},
+		{"Status", todo.Status},
+		{"Created", documentDate(todo.CreatedDate)},
+		{"Modified", documentDate(todo.ModifiedDate)},
+		{"Completed", documentDate(todo.CompletedDate)},
+		{"Wait", documentDate(todo.Wait)},
+		{"Until", documentDate(todo.Until)},
+		{"Scheduled", documentDate(todo.Scheduled)},
+		{"Spent", durationToString(todo.TimeSpent(now))},
+		{"Depends", strings.Join(todo.Depends, ", ")},
+	}
+	shown := [][2]string{}
+	for _, field := range fields {
+		if field[1] != "" {
+			shown = append(shown, field)
+		}
+	}
+	return shown
+}
+
+//Stats as text, with time spent formatted as on screen (e.g. 2h05m)
+func documentStats(filtered []*Todo, groupBy string, sumBy string, cols []string, rangeTimes []time.Time, clock Clock) ([]string, [][]string) {
+	headers, rows := statsTable(filtered, groupBy, sumBy, cols, rangeTimes, clock)
+	textRows := [][]string{}
+	for _, row := range rows {
+		for i, val := range row {
+			if spent, ok := val.(time.Duration); ok {
+				row[i] = durationToString(spent)
+			}
+		}
+		textRows = append(textRows, textValues(row))
+	}
+	return headers, textRows
+}
@@ -0,0 +1,53 @@
+package todolist
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDocumentPrinters(t *testing.T) {
+	assert := assert.New(t)
+	clock := FixedClock{Time: time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)}
+	todos := []*Todo{
+		&Todo{Id: 1, Subject: "Write report", Projects: []string{"Acme"}, Due: "2026-10-12T00:00:00Z", Status: "Pending", Notes: []string{"draft | v2"}},
+		&Todo{Id: 2, Subject: "Call Bob", Projects: []string{"Acme"}, Due: "2026-10-15T00:00:00Z", Status: "Pending"},
+		&Todo{Id: 3, Subject: "Fix <bug>", Projects: []string{"Beta"}, Completed: true, Status: "Pending"},
+	}
+	report := &Report{
+		Description: "Weekly status",
+		Columns:     []string{"id", "due", "project", "subject"},
+		Headers:     []string{"Id", "Due", "Project", "Subject"},
+		Sorter:      NewTodoSorter("project", "id"),
+		Filters:     []string{},
+		Group:       "project",
+		PrintNotes:  true,
+	}
+
+	var buf bytes.Buffer
+	NewMarkdownPrinter(&buf, clock).PrintReport(report, todos)
+	assert.Equal("# Weekly status\n\n"+
+		"_Wed Oct 14 2026. 3 todos. 1 overdue, 1 due within a week, 1 completed._\n\n"+
+		"## Acme\n\n"+
+		"| Id | Due | Subject |\n| --- | --- | --- |\n"+
+		"| 1 | **Mon Oct 12 (overdue)** | Write report<br>• draft \\| v2 |\n"+
+		"| 2 | *tomorrow* | Call Bob |\n\n"+
+		"## Beta\n\n"+
+		"| Id | Due | Subject |\n| --- | --- | --- |\n"+
+		"| 3 |  | Fix &lt;bug> |\n", buf.String())
+
+	report.Checklist = true
+	buf.Reset()
+	NewMarkdownPrinter(&buf, clock).PrintReport(report, todos)
+	assert.Contains(buf.String(), "## Acme\n\n- [ ] Write report (Id: 1, Due: **Mon Oct 12 (overdue)**)\n  - draft \\| v2\n- [ ] Call Bob (Id: 2, Due: *tomorrow*)\n")
+	assert.Contains(buf.String(), "- [x] Fix &lt;bug> (Id: 3)\n")
+
+	report.Checklist = false
+	buf.Reset()
+	NewHTMLPrinter(&buf, clock).PrintReport(report, todos)
+	assert.Contains(buf.String(), "<h1>Weekly status</h1>")
+	assert.Contains(buf.String(), `<tr><td>1</td><td><strong style="color: #c00;">Mon Oct 12 (overdue)</strong></td><td>Write report<ul><li>draft | v2</li></ul></td></tr>`)
+	assert.Contains(buf.String(), "<td>Fix &lt;bug&gt;</td>")
+}
@@ -0,0 +1,38 @@
+package todolist
+
+import (
+	"errors"
+	"fmt"
+)
+
+//Kinds of error returned by the App, Store, Parser, TodoList and TodoSync. Nothing in the package exits or panics,
+//so it can be embedded in other programs. Test the kind with errors.Is. The todo command maps kinds to exit codes.
+var (
+	ErrRepoNotFound    = errors.New("No todo repo found")
+	ErrRepoExists      = errors.New("Todo repo already exists")
+	ErrBadDate         = errors.New("Could not parse date")
+	ErrBadInput        = errors.New("Invalid input")
+	ErrMissingFilter   = errors.New("Destructive operations require a filter. None specified. Aborting.")
+	ErrBadConfig       = errors.New("Invalid configuration")
+	ErrWrongPassphrase = errors.New("Wrong passphrase")
+	ErrHookRejected    = errors.New("Rejected by hook")
+	ErrNotFound        = errors.New("Not found") //No todo matches the filter, or a file to read is missing
+)
+
+//An error of one of the kinds above, with a message for the user
+type Error struct {
+	Kind error
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	return e.Msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+func newError(kind error, format string, a ...interface{}) error {
+	return &Error{Kind: kind, Msg: fmt.Sprintf(format, a...)}
+}
@@ -0,0 +1,87 @@
+package todolist
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrors(t *testing.T) {
+	assert := assert.New(t)
+	dir, _ := ioutil.TempDir("", "errors")
+	defer os.RemoveAll(dir)
+
+	store := &FileStore{PendingFileLocation: filepath.Join(dir, ".todos.json"), ArchivedFileLocation: filepath.Join(dir, ".todos_archive.json"), BacklogFileLocation: filepath.Join(dir, ".todos_backlog.json")}
+	_, err := store.LoadPending()
+	assert.True(errors.Is(err, ErrRepoNotFound))
+
+	clock := FixedClock{Time: time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)}
+	_, err = NewToDoFilter([]*Todo{&Todo{Id: 1, Status: "Pending"}}, clock).Filter([]string{"due:someday"})
+	assert.True(errors.Is(err, ErrBadDate))
+	_, err = (&Parser{Clock: clock}).ParseNewTodo([]string{"Call", "Bob", "due:someday"}, &TodoList{Clock: clock})
+	assert.True(errors.Is(err, ErrBadDate))
+
+	err = (&Report{}).Init(map[string]string{"widths": "wide"})
+	assert.True(errors.Is(err, ErrBadConfig))
+
+	encrypted, err := encrypt([]byte("[]"), "secret")
+	assert.Nil(err)
+	_, err = decrypt(encrypted, "guess")
+	assert.True(errors.Is(err, ErrWrongPassphrase))
+	plain, err := decrypt(encrypted, "secret")
+	assert.Nil(err)
+	assert.Equal("[]", string(plain))
+}
+
+func TestCommandErrors(t *testing.T) {
+	assert := assert.New(t)
+	dir, _ := ioutil.TempDir("", "errors")
+	defer os.RemoveAll(dir)
+	store := &recordingStore{pending: []*Todo{
+		&Todo{Id: 1, Subject: "one", Status: "Pending", Notes: []string{"a"}, Ordinals: map[string]int{}},
+		&Todo{Id: 2, Subject: "two", Status: "Pending", Notes: []string{"b"}, Ordinals: map[string]int{}},
+	}}
+	app := newTestApp()
+	app.TodoStore = store
+
+	//Nothing matched or missing
+	assert.True(errors.Is(app.AddNote(&CommandImpl{Filters: []string{"9"}, Mods: []string{"note"}}), ErrNotFound))
+	assert.True(errors.Is(app.EditNote(&CommandImpl{Filters: []string{"9"}, Mods: []string{"0", "note"}}), ErrNotFound))
+	assert.True(errors.Is(app.DeleteNote(&CommandImpl{Filters: []string{"9"}, Mods: []string{"0"}}), ErrNotFound))
+	assert.True(errors.Is(app.UnarchiveTodo(&CommandImpl{Filters: []string{"9"}}), ErrNotFound))
+	assert.True(errors.Is(app.ImportTodo(&CommandImpl{Args: []string{"file:" + filepath.Join(dir, "missing.json")}}), ErrNotFound))
+
+	//Bad input
+	err := app.EditNote(&CommandImpl{Filters: []string{"1,2"}, Mods: []string{"0", "note"}})
+	assert.True(errors.Is(err, ErrBadInput))
+	assert.Contains(err.Error(), "2: two")
+	assert.True(errors.Is(app.DeleteNote(&CommandImpl{Filters: []string{"1,2"}, Mods: []string{"0"}}), ErrBadInput))
+	assert.True(errors.Is(app.OrderTodos(&CommandImpl{Mods: []string{"all"}}), ErrBadInput))
+	assert.True(errors.Is(app.OrderTodos(&CommandImpl{Mods: []string{"all:2,x"}}), ErrBadInput))
+	assert.Equal([]string{"a"}, store.pending[0].Notes)
+
+	//A file that isn't todos is an IO error, not a missing one
+	ioutil.WriteFile(filepath.Join(dir, "bad.json"), []byte("not json"), 0644)
+	err = (&App{TodoStore: &FileStore{}, Clock: FixedClock{}}).ImportTodo(&CommandImpl{Args: []string{"file:" + filepath.Join(dir, "bad.json")}})
+	assert.NotNil(err)
+	assert.False(errors.Is(err, ErrNotFound))
+
+	//Edits that can't be parsed are kept in the file named
+	editor := filepath.Join(dir, "editor")
+	ioutil.WriteFile(editor, []byte("#!/bin/sh\necho 'not a todo' > \"$1\"\n"), 0755)
+	os.Setenv("VISUAL", editor)
+	defer os.Unsetenv("VISUAL")
+	err = app.EditFull(&CommandImpl{Filters: []string{"1"}})
+	assert.True(errors.Is(err, ErrBadInput))
+	assert.Contains(err.Error(), "Your edits are in ")
+	os.Remove(err.Error()[strings.LastIndex(err.Error(), " ")+1:])
+	assert.True(errors.Is(app.EditFull(&CommandImpl{Filters: []string{"9"}}), ErrNotFound))
+
+	assert.True(errors.Is(NewTodoSync(&Config{}, store, FixedClock{}).Sync(false), ErrBadConfig))
+}
@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"os"
 	"os/user"
+	"path/filepath"
 	"sync"
 )
 
@@ -22,7 +23,8 @@ func NewFileStore() *FileStore {
 	return &FileStore{PendingFileLocation: "", ArchivedFileLocation: "", BacklogFileLocation: "", PendingLoaded: false, ArchivedLoaded: false}
 }
 
-func (f *FileStore) Initialize() {
+//Create the repo files in the current directory. Returns ErrRepoExists if they all exist already.
+func (f *FileStore) Initialize() error {
 	if f.PendingFileLocation == "" {
 		f.PendingFileLocation = ".todos.json"
 	}
@@ -39,16 +41,14 @@ func (f *FileStore) Initialize() {
 	_, err := ioutil.ReadFile(f.PendingFileLocation)
 	if err != nil {
 		if err := ioutil.WriteFile(f.PendingFileLocation, []byte("[]"), 0644); err != nil {
-			fmt.Println("Error writing json file", err)
-			os.Exit(1)
+			return fmt.Errorf("Error writing json file: %w", err)
 		}
 		filePendingCreated = true
 	}
 	_, err = ioutil.ReadFile(f.ArchivedFileLocation)
 	if err != nil {
 		if err := ioutil.WriteFile(f.ArchivedFileLocation, []byte("[]"), 0644); err != nil {
-			fmt.Println("Error writing json file", err)
-			os.Exit(1)
+			return fmt.Errorf("Error writing json file: %w", err)
 		}
 		fileArchivedCreated = true
 	}
@@ -56,86 +56,78 @@ func (f *FileStore) Initialize() {
 	_, err = ioutil.ReadFile(f.BacklogFileLocation)
 	if err != nil {
 		if err := ioutil.WriteFile(f.BacklogFileLocation, []byte(""), 0644); err != nil {
-			fmt.Println("Error writing json file", err)
-			os.Exit(1)
+			return fmt.Errorf("Error writing json file: %w", err)
 		}
 		fileBacklogCreated = true
 	}
 
 	if !filePendingCreated && !fileArchivedCreated && !fileBacklogCreated {
-		fmt.Println("It looks like a .todos.json file already exists!  Doing nothing.")
-		os.Exit(0)
+		return newError(ErrRepoExists, "It looks like a .todos.json file already exists!  Doing nothing.")
 	}
-	fmt.Println("Todo repo initialized.")
+	return nil
 }
 
 func (f *FileStore) LoadPending() ([]*Todo, error) {
-	if f.PendingFileLocation == "" {
-		f.PendingFileLocation = getPendingLocation()
-	}
-	if f.ArchivedFileLocation == "" {
-		f.ArchivedFileLocation = getArchivedLocation()
-	}
-	if f.BacklogFileLocation == "" {
-		f.BacklogFileLocation = getBacklogLocation()
+	f.Locate()
+	todos, err := f.load(f.PendingFileLocation)
+	if err != nil {
+		return nil, err
 	}
-	todos, _ := f.load(f.PendingFileLocation)
 	f.PendingLoaded = true
 
 	return todos, nil
 }
 
 func (f *FileStore) LoadArchived() ([]*Todo, error) {
-	if f.ArchivedFileLocation == "" {
-		f.ArchivedFileLocation = getArchivedLocation()
+	f.Locate()
+	todos, err := f.load(f.ArchivedFileLocation)
+	if err != nil {
+		return nil, err
 	}
+	f.ArchivedLoaded = true
+	return todos, nil
+}
+
+//Find the repo files, for those not set already
+func (f *FileStore) Locate() {
 	if f.PendingFileLocation == "" {
 		f.PendingFileLocation = getPendingLocation()
 	}
+	if f.ArchivedFileLocation == "" {
+		f.ArchivedFileLocation = getArchivedLocation()
+	}
 	if f.BacklogFileLocation == "" {
 		f.BacklogFileLocation = getBacklogLocation()
 	}
-	todos, _ := f.load(f.ArchivedFileLocation)
-	f.ArchivedLoaded = true
-	return todos, nil
 }
 
 func (f *FileStore) Import(filepath string) ([]*Todo, error) {
 	return f.load(filepath)
 }
 
-func (f *FileStore) Export(filepath string, todos []*Todo) {
-	var wg sync.WaitGroup
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		data, _ := json.Marshal(todos)
-		f.saveTodos(data, filepath)
-	}()
-	wg.Wait()
+func (f *FileStore) Export(filepath string, todos []*Todo) error {
+	data, _ := json.Marshal(todos)
+	return f.saveTodos(data, filepath)
 }
 
 func (f *FileStore) load(filepath string) ([]*Todo, error) {
 
 	data, err := ioutil.ReadFile(filepath)
-	if err != nil {
-		fmt.Println("No todo file found!")
-		fmt.Println("Initialize a new todo repo by running 'todolist init'")
-		os.Exit(0)
-		return nil, err
+	if os.IsNotExist(err) {
+		return nil, newError(ErrRepoNotFound, "No todo file found!\nInitialize a new todo repo by running 'todo init'")
+	} else if err != nil {
+		return nil, fmt.Errorf("Error reading todo file %s: %w", filepath, err)
 	}
 
 	var todos []*Todo
 	jerr := json.Unmarshal(data, &todos)
 	if jerr != nil {
-		fmt.Println("Error reading json data", jerr)
-		os.Exit(1)
-		return nil, jerr
+		return nil, fmt.Errorf("Error reading json data in %s: %w", filepath, jerr)
 	}
 	return todos, nil
 }
 
-func (f *FileStore) Save(todos []*Todo) {
+func (f *FileStore) Save(todos []*Todo) error {
 	//Separate archived and pending and save separately
 	archivedTodos := []*Todo{}
 	pendingTodos := []*Todo{}
@@ -154,6 +146,7 @@ func (f *FileStore) Save(todos []*Todo) {
 		}
 	}
 	var wg sync.WaitGroup
+	errs := make([]error, 3) //one per file, so the goroutines don't share a variable
 
 	//Save archived
 	if f.ArchivedLoaded {
@@ -161,7 +154,7 @@ func (f *FileStore) Save(todos []*Todo) {
 		go func() {
 			defer wg.Done()
 			data, _ := json.Marshal(archivedTodos)
-			f.saveTodos(data, f.ArchivedFileLocation)
+			errs[0] = f.saveTodos(data, f.ArchivedFileLocation)
 		}()
 	}
 	//save pending
@@ -170,7 +163,7 @@ func (f *FileStore) Save(todos []*Todo) {
 		go func() {
 			defer wg.Done()
 			data, _ := json.Marshal(pendingTodos)
-			f.saveTodos(data, f.PendingFileLocation)
+			errs[1] = f.saveTodos(data, f.PendingFileLocation)
 		}()
 	}
 
@@ -179,39 +172,43 @@ func (f *FileStore) Save(todos []*Todo) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			f.AppendBacklog(f.BacklogFileLocation, modifiedTodos)
+			errs[2] = f.AppendBacklog(f.BacklogFileLocation, modifiedTodos)
 		}()
 	}
 
 	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (f *FileStore) saveTodos(data []byte, filepath string) {
+func (f *FileStore) saveTodos(data []byte, filepath string) error {
 	if err := ioutil.WriteFile(filepath, []byte(data), 0644); err != nil {
-		fmt.Println("Error writing json file: ", filepath, ". Error: ", err)
+		return fmt.Errorf("Error writing json file %s: %w", filepath, err)
 	}
+	return nil
 }
 
-func (f *FileStore) AppendBacklog(filepath string, todos []*Todo) {
+func (f *FileStore) AppendBacklog(filepath string, todos []*Todo) error {
 	fd, err := os.OpenFile(filepath, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0777)
 	if err != nil {
-		println("Error opening file ", filepath, " : ", err.Error)
-		panic(err)
+		return fmt.Errorf("Error opening backlog json file %s: %w", filepath, err)
 	}
 	defer fd.Close()
 
 	for _, todo := range todos {
 		data, _ := json.Marshal(todo)
 		if _, err = fd.Write(data); err != nil {
-			fmt.Println("Error appending to backlog json file: ", filepath, ". Error: ", err)
-			panic(err)
+			return fmt.Errorf("Error appending to backlog json file %s: %w", filepath, err)
 		}
 		if _, err = fd.WriteString("\n"); err != nil {
-			fmt.Println("Error appending newline to backlog json file: ", filepath, ". Error: ", err)
-			panic(err)
+			return fmt.Errorf("Error appending newline to backlog json file %s: %w", filepath, err)
 		}
 	}
-	fd.Sync()
+	return fd.Sync()
 }
 
 func (f *FileStore) LoadBacklog(filepath string) ([]*Todo, error) {
@@ -247,15 +244,16 @@ func (f *FileStore) LoadBacklog(filepath string) ([]*Todo, error) {
 	return todos, nil
 }
 
-func (f *FileStore) DeleteBacklog(filepath string) {
-	var err = os.Remove(filepath)
-	if err != nil {
-		return
+//Remove the backlog file. No error if there is none.
+func (f *FileStore) DeleteBacklog(filepath string) error {
+	if err := os.Remove(filepath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("Error deleting backlog json file %s: %w", filepath, err)
 	}
+	return nil
 }
 
 func getPendingLocation() string {
-	localrepo := ".todos.json"
+	localrepo := filepath.Join(currentRepoDir(), ".todos.json")
 	usr, _ := user.Current()
 	homerepo := fmt.Sprintf("%s/.todos.json", usr.HomeDir)
 	_, ferr := os.Stat(localrepo)
@@ -268,7 +266,7 @@ func getPendingLocation() string {
 }
 
 func getArchivedLocation() string {
-	localrepo := ".todos_archive.json"
+	localrepo := filepath.Join(currentRepoDir(), ".todos_archive.json")
 	usr, _ := user.Current()
 	homerepo := fmt.Sprintf("%s/.todos_archive.json", usr.HomeDir)
 	_, ferr := os.Stat(localrepo)
@@ -285,7 +283,7 @@ func (f *FileStore) GetBacklogFilepath() string {
 }
 
 func getBacklogLocation() string {
-	localrepo := ".todos_backlog.json"
+	localrepo := filepath.Join(currentRepoDir(), ".todos_backlog.json")
 	usr, _ := user.Current()
 	homerepo := fmt.Sprintf("%s/.todos_backlog.json", usr.HomeDir)
 	_, ferr := os.Stat(localrepo)
@@ -296,3 +294,47 @@ func getBacklogLocation() string {
 		return homerepo
 	}
 }
+
+func getRemindersLocation() string {
+	localrepo := filepath.Join(currentRepoDir(), ".todos_reminders.json")
+	usr, _ := user.Current()
+	homerepo := fmt.Sprintf("%s/.todos_reminders.json", usr.HomeDir)
+	_, ferr := os.Stat(localrepo)
+
+	if ferr == nil {
+		return localrepo
+	} else {
+		return homerepo
+	}
+}
+
+func getHooksLocation() string {
+	return repoHooksLocation(currentRepoDir())
+}
+
+//The repo's .todo_hooks, else the home directory's
+func repoHooksLocation(dir string) string {
+	localrepo := filepath.Join(dir, ".todo_hooks")
+	usr, _ := user.Current()
+	homerepo := fmt.Sprintf("%s/.todo_hooks", usr.HomeDir)
+	_, ferr := os.Stat(localrepo)
+
+	if ferr == nil {
+		return localrepo
+	} else {
+		return homerepo
+	}
+}
+
+func getPluginsLocation() string {
+	localrepo := filepath.Join(currentRepoDir(), ".todo_plugins")
+	usr, _ := user.Current()
+	homerepo := fmt.Sprintf("%s/.todo_plugins", usr.HomeDir)
+	_, ferr := os.Stat(localrepo)
+
+	if ferr == nil {
+		return localrepo
+	} else {
+		return homerepo
+	}
+}
@@ -1,20 +1,50 @@
 package todolist
 
 import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
+//A store for the repo files in a new directory, with one pending todo
+func newTestFileStore(dir string) *FileStore {
+	ioutil.WriteFile(filepath.Join(dir, ".todos.json"), []byte(`[{"id":1,"subject":"this is the first subject","status":"Pending"}]`), 0644)
+	ioutil.WriteFile(filepath.Join(dir, ".todos_archive.json"), []byte("[]"), 0644)
+	return &FileStore{
+		PendingFileLocation:  filepath.Join(dir, ".todos.json"),
+		ArchivedFileLocation: filepath.Join(dir, ".todos_archive.json"),
+		BacklogFileLocation:  filepath.Join(dir, ".todos_backlog.json"),
+	}
+}
+
 func TestFileStore(t *testing.T) {
 	assert := assert.New(t)
-	store := &FileStore{FileLocation: "todos.json"}
-	todos, _ := store.Load()
+	dir, _ := ioutil.TempDir("", "store")
+	defer os.RemoveAll(dir)
+	store := newTestFileStore(dir)
+	todos, err := store.LoadPending()
+	assert.Nil(err)
 	assert.Equal(todos[0].Subject, "this is the first subject", "")
+
+	store.PendingFileLocation = filepath.Join(dir, "missing.json")
+	_, err = store.LoadPending()
+	assert.True(errors.Is(err, ErrRepoNotFound))
 }
 
 func TestSave(t *testing.T) {
-	store := &FileStore{FileLocation: "todos.json"}
-	todos, _ := store.Load()
-	store.Save(todos)
+	assert := assert.New(t)
+	dir, _ := ioutil.TempDir("", "store")
+	defer os.RemoveAll(dir)
+	store := newTestFileStore(dir)
+	todos, _ := store.LoadPending()
+	todos[0].Subject = "changed"
+	todos[0].IsModified = true
+	assert.Nil(store.Save(todos))
+
+	todos, _ = store.LoadPending()
+	assert.Equal("changed", todos[0].Subject)
 }
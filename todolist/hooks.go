@@ -0,0 +1,144 @@
+package todolist
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+//Hook events. Scripts in the hooks directory named for an event (e.g. on-add, on-add-ticket.sh) run on it.
+const (
+	HookAdd      = "on-add"
+	HookModify   = "on-modify"
+	HookComplete = "on-complete"
+	HookDelete   = "on-delete"
+	HookExit     = "on-exit"
+)
+
+//Executable scripts run when todos are added, modified, completed or deleted, and when a command exits. Scripts
+//get the todos as JSON on stdin, one per line (the old then the new todo for on-modify). They may print the todo,
+//or just the fields to change, as JSON on the first line of stdout to change it. Other lines are printed as
+//feedback. A non-zero exit rejects the change, with the script's output as the message. on-exit gets the todos
+//changed by the command, and its output is feedback only.
+type Hooks struct {
+	Dir string    //The hooks directory. No hooks if blank or missing.
+	Out io.Writer //Where feedback is printed
+}
+
+func NewHooks(dir string) *Hooks {
+	return &Hooks{Dir: dir, Out: os.Stdout}
+}
+
+//The executable scripts for the event, in name order
+func (h *Hooks) Scripts(event string) []string {
+	if h == nil || h.Dir == "" {
+		return nil
+	}
+	files, err := ioutil.ReadDir(h.Dir)
+	if err != nil {
+		return nil
+	}
+	scripts := []string{}
+	for _, file := range files {
+		if strings.HasPrefix(file.Name(), event) && !file.IsDir() && file.Mode()&0111 != 0 {
+			scripts = append(scripts, filepath.Join(h.Dir, file.Name()))
+		}
+	}
+	sort.Strings(scripts)
+	return scripts
+}
+
+func (h *Hooks) OnAdd(todo *Todo) error {
+	return h.run(HookAdd, todo)
+}
+
+func (h *Hooks) OnModify(old *Todo, todo *Todo) error {
+	return h.run(HookModify, old, todo)
+}
+
+func (h *Hooks) OnComplete(todo *Todo) error {
+	return h.run(HookComplete, todo)
+}
+
+func (h *Hooks) OnDelete(todo *Todo) error {
+	return h.run(HookDelete, todo)
+}
+
+func (h *Hooks) OnExit(changed []*Todo) error {
+	return h.run(HookExit, changed...)
+}
+
+//Run the scripts for the event in turn. All but on-exit may change the last todo, which the next script sees.
+func (h *Hooks) run(event string, todos ...*Todo) error {
+	for _, script := range h.Scripts(event) {
+		input := []byte{}
+		for _, todo := range todos {
+			data, err := json.Marshal(todo)
+			if err != nil {
+				return err
+			}
+			input = append(append(input, data...), '\n')
+		}
+		var stdout, stderr bytes.Buffer
+		cmd := exec.Command(script)
+		cmd.Env = append(os.Environ(), "TODO_HOOK="+event)
+		cmd.Stdin = bytes.NewReader(input)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			if _, isExit := err.(*exec.ExitError); !isExit {
+				return fmt.Errorf("Error running hook %s: %w", script, err)
+			}
+			msg := strings.TrimSpace(stdout.String() + stderr.String())
+			if msg == "" {
+				msg = fmt.Sprintf("%s %s", filepath.Base(script), err)
+			}
+			return newError(ErrHookRejected, "%s", msg)
+		}
+		lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+		if event != HookExit && len(todos) > 0 && strings.HasPrefix(lines[0], "{") {
+			if err := applyHookOutput(todos[len(todos)-1], lines[0]); err != nil {
+				return fmt.Errorf("Error reading todo from hook %s: %w", script, err)
+			}
+			lines = lines[1:]
+		}
+		for _, line := range lines {
+			if line != "" {
+				fmt.Fprintln(h.Out, line)
+			}
+		}
+		fmt.Fprint(h.Out, stderr.String())
+	}
+	return nil
+}
+
+//Set the fields in the JSON on the todo. The id and uuid can't be changed.
+func applyHookOutput(todo *Todo, data string) error {
+	id, uuid := todo.Id, todo.Uuid
+	if err := json.Unmarshal([]byte(data), todo); err != nil {
+		return err
+	}
+	todo.Id, todo.Uuid = id, uuid
+	return nil
+}
+
+//A copy of the todo, to restore if a hook rejects the change
+func snapshotTodo(todo *Todo) (*Todo, error) {
+	data, err := json.Marshal(todo)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &Todo{}
+	if err := json.Unmarshal(data, snapshot); err != nil {
+		return nil, err
+	}
+	snapshot.IsModified = todo.IsModified
+	return snapshot, nil
+}
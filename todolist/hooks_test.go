@@ -0,0 +1,92 @@
+package todolist
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHooks(t *testing.T) {
+	assert := assert.New(t)
+	dir, _ := ioutil.TempDir("", "hooks")
+	defer os.RemoveAll(dir)
+	script := func(name string, body string) {
+		ioutil.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+body+"\n"), 0755)
+	}
+	log := filepath.Join(dir, "hooks.log")
+	//Tag new todos, require a due date on +Work todos, record completions and refuse deletes
+	script("on-add-ticket", `read todo; echo '{"tags":["ticket"]}'; echo "Tagged ticket"`)
+	script("on-modify-work", `read old; read new
+case "$new" in *'"Work"'*'"due":""'*) echo "Work todos need a due date"; exit 1;; esac`)
+	script("on-complete", `cat >> `+log)
+	script("on-delete", `exit 1`)
+	script("on-exit", `cat >> `+log)
+	ioutil.WriteFile(filepath.Join(dir, "on-complete.txt"), []byte("not executable"), 0644)
+
+	out := &bytes.Buffer{}
+	list := &TodoList{Clock: FixedClock{Time: time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)}, Hooks: &Hooks{Dir: dir, Out: out}}
+	assert.Equal([]string{filepath.Join(dir, "on-complete")}, list.Hooks.Scripts(HookComplete))
+
+	todo := &Todo{Subject: "Fix login", Status: "Pending", Ordinals: map[string]int{}}
+	id, err := list.Add(todo)
+	assert.Nil(err)
+	assert.Equal(1, id)
+	assert.Equal([]string{"ticket"}, todo.Tags)
+	assert.Equal("Tagged ticket\n", out.String())
+
+	edited, err := list.Edit([]string{"+Work"}, todo)
+	assert.False(edited)
+	assert.True(errors.Is(err, ErrHookRejected))
+	assert.Equal("Work todos need a due date", err.Error())
+	assert.Empty(todo.Projects)
+	edited, err = list.Edit([]string{"+Work", "due:2026-10-20"}, todo)
+	assert.True(edited)
+	assert.Nil(err)
+	assert.Equal([]string{"Work"}, todo.Projects)
+
+	assert.Nil(list.Complete(todo))
+	data, _ := ioutil.ReadFile(log)
+	assert.Contains(string(data), `"subject":"Fix login"`)
+	assert.Contains(string(data), `"completed":true`)
+
+	assert.True(errors.Is(list.Delete(todo), ErrHookRejected))
+	assert.NotEqual("Deleted", todo.Status)
+
+	os.Remove(log)
+	assert.Nil(list.Hooks.OnExit([]*Todo{todo, &Todo{Id: 2}}))
+	data, _ = ioutil.ReadFile(log)
+	assert.Equal(2, bytes.Count(data, []byte("\n")))
+
+	//Every mutation runs the on-modify hooks, so a +Work todo without a due date can't be started, uncompleted,
+	//archived, touched or reordered
+	other := &Todo{Id: 2, Subject: "Plan", Status: "Pending", Ordinals: map[string]int{"all": 0}}
+	work := &Todo{Id: 3, Subject: "Ship", Status: "Pending", Projects: []string{"Work"}, Completed: true,
+		CompletedDate: "2026-10-13T12:00:00Z", Ordinals: map[string]int{"all": 1}}
+	list.Data = []*Todo{other, work}
+	started, err := list.Start(work)
+	assert.False(started)
+	assert.True(errors.Is(err, ErrHookRejected))
+	assert.False(work.IsActive())
+	assert.True(errors.Is(list.Uncomplete(work), ErrHookRejected))
+	assert.True(work.Completed)
+	assert.True(errors.Is(list.Archive(work), ErrHookRejected))
+	assert.Equal("Pending", work.Status)
+	_, err = list.Touch(work)
+	assert.True(errors.Is(err, ErrHookRejected))
+	assert.True(errors.Is(list.UpdateOrdinals("all", []int{0, work.Id}), ErrHookRejected))
+	assert.Equal(1, work.Ordinals["all"])
+	assert.False(work.IsModified)
+	started, err = list.Start(other)
+	assert.True(started)
+	assert.Nil(err)
+
+	//No hooks without a directory
+	assert.Nil((*Hooks)(nil).OnAdd(todo))
+	assert.Empty(NewHooks(filepath.Join(dir, "missing")).Scripts(HookAdd))
+}
@@ -2,29 +2,48 @@ package todolist
 
 import (
 	"fmt"
-	"os"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
 )
 
-type Parser struct{}
+//A recur: value, e.g. 2w or weekly
+var recurrence = regexp.MustCompile(`^(\d+[dwmy]|daily|weekly|monthly|yearly)$`)
 
-func (p *Parser) ParseNewTodo(mods []string, todolist *TodoList) *Todo {
+type Parser struct {
+	Clock Clock //Relative dates (due:tomorrow) are relative to now on this clock. The system clock if nil.
+}
+
+func (p *Parser) now() time.Time {
+	if p.Clock == nil {
+		return time.Now()
+	}
+	return p.Clock.Now()
+}
+
+//Nil, without error, if there are no mods
+func (p *Parser) ParseNewTodo(mods []string, todolist *TodoList) (*Todo, error) {
 	if len(mods) == 0 {
-		return nil
+		return nil, nil
 	}
 
-	todo := NewTodo()
+	todo, err := NewTodo()
+	if err != nil {
+		return nil, err
+	}
 
-	p.ParseInput(mods, todo, todolist)
+	if err := p.ParseInput(mods, todo, todolist); err != nil {
+		return nil, err
+	}
 	todolist.AddOrdinal("all", todo)
 
-	return todo
+	return todo, nil
 }
 
-func (p *Parser) ParseInput(mods []string, todo *Todo, todolist *TodoList) {
+//Apply the mods to the todo. Returns ErrBadDate or ErrBadInput if a date, recurrence or effort can't be parsed.
+func (p *Parser) ParseInput(mods []string, todo *Todo, todolist *TodoList) error {
+	var err error
 	subj := []string{}
 	for i, part := range mods {
 		if strings.HasPrefix(part, "+") {
@@ -33,23 +52,70 @@ func (p *Parser) ParseInput(mods []string, todo *Todo, todolist *TodoList) {
 		} else if strings.HasPrefix(part, "@") {
 			tmp := part[1:]
 			todolist.AddContext(tmp, todo)
+		} else if strings.HasPrefix(part, "#") {
+			tmp := part[1:]
+			todolist.AddTag(tmp, todo)
 		} else if strings.HasPrefix(part, "-") {
 			if strings.Index(part, "@") == 1 {
 				tmp := part[2:]
 				todolist.RemoveContext(tmp, todo)
+			} else if strings.Index(part, "#") == 1 {
+				tmp := part[2:]
+				todolist.RemoveTag(tmp, todo)
 			} else {
 				tmp := part[1:]
 				todolist.RemoveProject(tmp, todo)
 			}
 		} else if strings.HasPrefix(part, "due:") {
 			tmp := part[4:]
-			todo.Due = p.FormatDateTime(tmp, Now)
+			if tmp == "" {
+				todo.Due = "" //blank clears the date
+			} else if todo.Due, err = p.FormatDateTime(tmp, p.now()); err != nil {
+				return err
+			}
 		} else if strings.HasPrefix(part, "wait:") {
 			tmp := part[5:]
-			todo.Wait = p.FormatDateTime(tmp, Now)
+			if tmp == "" {
+				todo.Wait = "" //blank clears the date
+			} else if todo.Wait, err = p.FormatDateTime(tmp, p.now()); err != nil {
+				return err
+			}
+		} else if strings.HasPrefix(part, "scheduled:") || strings.HasPrefix(part, "sched:") {
+			tmp := part[strings.Index(part, ":")+1:]
+			if tmp == "" {
+				todo.Scheduled = "" //blank clears the date
+			} else if todo.Scheduled, err = p.FormatDateTime(tmp, p.now()); err != nil {
+				return err
+			}
 		} else if strings.HasPrefix(part, "until:") {
 			tmp := part[6:]
-			todo.Until = p.FormatDateTime(tmp, Now)
+			if tmp == "" {
+				todo.Until = "" //blank clears the date
+			} else if todo.Until, err = p.FormatDateTime(tmp, p.now()); err != nil {
+				return err
+			}
+		} else if strings.HasPrefix(part, "recur:") {
+			//recur:1w -- the todo is RECURRING. recur: or recur:none clears.
+			tmp := strings.ToLower(part[6:])
+			if tmp == "" || strings.HasPrefix(tmp, "non") {
+				todo.Recur = ""
+			} else if recurrence.MatchString(tmp) {
+				todo.Recur = tmp
+			} else {
+				return newError(ErrBadInput, "Could not parse recurrence: %s. Expected e.g. 1d, 2w, 1m, 1y or daily, weekly, monthly, yearly.", tmp)
+			}
+		} else if strings.HasPrefix(part, "depends:") {
+			//depends:3,5 -- the todo is BLOCKED until todos 3 and 5 are completed. depends: clears.
+			todo.Depends = []string{}
+			for _, val := range strings.Split(part[8:], ",") {
+				id, err := strconv.Atoi(val)
+				if err != nil {
+					continue
+				}
+				if dep := todolist.FindById(id); dep != nil && dep.Uuid != todo.Uuid {
+					todo.Depends = AddIfNotThere(todo.Depends, []string{dep.Uuid})
+				}
+			}
 		} else if strings.HasPrefix(part, "pri:") {
 			tmp := part[4:]
 			todo.Priority = tmp
@@ -65,8 +131,7 @@ func (p *Parser) ParseInput(mods []string, todo *Todo, todolist *TodoList) {
 				//cnt, err := strconv.Atoi(matches[1])
 				f, err := strconv.ParseFloat(matches[1], 64)
 				if err != nil {
-					fmt.Println("Could not parse effort days: ", tmp, ":", err)
-					os.Exit(1)
+					return newError(ErrBadInput, "Could not parse effort days: %s: %s", tmp, err)
 				}
 				if unit == "d" {
 					cnt = float64(f)
@@ -83,7 +148,9 @@ func (p *Parser) ParseInput(mods []string, todo *Todo, todolist *TodoList) {
 			todo.EffortDays = cnt
 		} else if strings.HasPrefix(part, "mod:") {
 			tmp := part[4:]
-			todo.ModifiedDate = p.FormatDateTime(tmp, Now)
+			if todo.ModifiedDate, err = p.FormatDateTime(tmp, p.now()); err != nil {
+				return err
+			}
 		} else {
 			subj = append(subj, mods[i])
 		}
@@ -100,17 +167,19 @@ func (p *Parser) ParseInput(mods []string, todo *Todo, todolist *TodoList) {
 			todo.Subject = s
 		}
 	}
-	return
+	return nil
 }
 
-func (p *Parser) ParseEditTodo(todo *Todo, mods []string, todolist *TodoList) bool {
+func (p *Parser) ParseEditTodo(todo *Todo, mods []string, todolist *TodoList) (bool, error) {
 
 	if len(mods) == 0 {
-		return false
+		return false, nil
 	}
 
-	p.ParseInput(mods, todo, todolist)
-	return true
+	if err := p.ParseInput(mods, todo, todolist); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 func (p *Parser) Projects(filters []string) []string {
@@ -184,11 +253,16 @@ func (p *Parser) getNoteID(input string) (int, error) {
 	return ret, nil
 }
 
-func (p *Parser) FormatDateTime(input string, relativeTime time.Time) string {
-	return timeToString(p.ParseDateTime(input, relativeTime))
+func (p *Parser) FormatDateTime(input string, relativeTime time.Time) (string, error) {
+	t, err := p.ParseDateTime(input, relativeTime)
+	if err != nil {
+		return "", err
+	}
+	return timeToString(t), nil
 }
 
-func (p *Parser) ParseDateTime(input string, relativeTime time.Time) time.Time {
+//Parse a date relative to relativeTime. Returns ErrBadDate if the input is not a date.
+func (p *Parser) ParseDateTime(input string, relativeTime time.Time) (time.Time, error) {
 
 	tmp := strings.ToLower(input)
 	//Check for relative date
@@ -198,10 +272,9 @@ func (p *Parser) ParseDateTime(input string, relativeTime time.Time) time.Time {
 		unit := strings.ToLower(matches[2])
 		cnt, err := strconv.Atoi(matches[1])
 		if err != nil {
-			fmt.Println("Could not parse date: ", input, ":", err)
-			os.Exit(1)
+			return time.Time{}, newError(ErrBadDate, "Could not parse date: %s: %s", input, err)
 		}
-		targetDate := Now
+		targetDate := relativeTime
 		if unit == "d" {
 			targetDate = bod(targetDate.AddDate(0, 0, 1*cnt))
 		} else if unit == "w" {
@@ -214,7 +287,7 @@ func (p *Parser) ParseDateTime(input string, relativeTime time.Time) time.Time {
 		} else if unit == "h" {
 			targetDate = targetDate.Add(time.Duration(cnt) * time.Hour)
 		}
-		return targetDate
+		return targetDate, nil
 	}
 
 	//support look back a week as well as look forward
@@ -225,54 +298,63 @@ func (p *Parser) ParseDateTime(input string, relativeTime time.Time) time.Time {
 	}
 	switch {
 	case strings.HasPrefix(tmp, "non"):
-		return bod(relativeTime)
+		return bod(relativeTime), nil
 	case strings.HasPrefix(tmp, "tod"):
-		return bod(relativeTime)
+		return bod(relativeTime), nil
 	case strings.HasPrefix(tmp, "tom"):
-		return bod(relativeTime).AddDate(0, 0, 1)
+		return bod(relativeTime).AddDate(0, 0, 1), nil
 	case strings.HasPrefix(tmp, "yes"):
-		return bod(relativeTime).AddDate(0, 0, -1)
+		return bod(relativeTime).AddDate(0, 0, -1), nil
 	case strings.HasPrefix(tmp, "mon"):
-		return monday(relativeTime, forward)
+		return monday(relativeTime, forward), nil
 	case strings.HasPrefix(tmp, "tue"):
-		return tuesday(relativeTime, forward)
+		return tuesday(relativeTime, forward), nil
 	case strings.HasPrefix(tmp, "wed"):
-		return wednesday(relativeTime, forward)
+		return wednesday(relativeTime, forward), nil
 	case strings.HasPrefix(tmp, "thu"):
-		return thursday(relativeTime, forward)
+		return thursday(relativeTime, forward), nil
 	case strings.HasPrefix(tmp, "fri"):
-		return friday(relativeTime, forward)
+		return friday(relativeTime, forward), nil
 	case strings.HasPrefix(tmp, "sat"):
-		return saturday(relativeTime, forward)
+		return saturday(relativeTime, forward), nil
 	case strings.HasPrefix(tmp, "sun"):
-		return sunday(relativeTime, forward)
+		return sunday(relativeTime, forward), nil
 	case tmp == "last_week":
 		n := bod(relativeTime)
-		return mostRecentMonday(n).AddDate(0, 0, -7)
+		return mostRecentMonday(n).AddDate(0, 0, -7), nil
 	case tmp == "this_week":
 		n := bod(relativeTime)
-		return mostRecentMonday(n)
+		return mostRecentMonday(n), nil
 	case tmp == "next_week":
 		n := bod(relativeTime)
-		return mostRecentMonday(n).AddDate(0, 0, 7)
+		return mostRecentMonday(n).AddDate(0, 0, 7), nil
+	case tmp == "last_month":
+		return bom(relativeTime).AddDate(0, -1, 0), nil
+	case tmp == "this_month":
+		return bom(relativeTime), nil
+	case tmp == "next_month":
+		return bom(relativeTime).AddDate(0, 1, 0), nil
+	case tmp == "last_year":
+		return boy(relativeTime).AddDate(-1, 0, 0), nil
+	case tmp == "this_year":
+		return boy(relativeTime), nil
+	case tmp == "next_year":
+		return boy(relativeTime).AddDate(1, 0, 0), nil
 	}
 	return p.parseArbitraryDate(tmp)
 }
 
-func (p *Parser) parseArbitraryDate(_date string) time.Time {
+func (p *Parser) parseArbitraryDate(_date string) (time.Time, error) {
 
 	if date, err := time.Parse("2006-01-02", _date); err == nil {
-		return date
+		return date, nil
 	}
 
 	if date, err := time.Parse("20060102", _date); err == nil {
-		return date
+		return date, nil
 	}
 
-	fmt.Printf("Could not parse the date you gave me: %s\n", _date)
-	fmt.Println("I'm expecting a date like \"yyyy-MM-dd\" or \"yyyyMMdd\".")
-	os.Exit(-1)
-	return Now
+	return time.Time{}, newError(ErrBadDate, "Could not parse the date you gave me: %s\nI'm expecting a date like \"yyyy-MM-dd\" or \"yyyyMMdd\".", _date)
 }
 
 /*
@@ -1,50 +1,48 @@
 package todolist
 
 import (
-	"fmt"
-	"strconv"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
+var parserClock = FixedClock{Time: time.Date(2016, 4, 25, 9, 0, 0, 0, time.UTC)}
+
+func newTestParser() (*Parser, *TodoList) {
+	return &Parser{Clock: parserClock}, &TodoList{Clock: parserClock}
+}
+
 func TestParseSubject(t *testing.T) {
-	parser := &Parser{}
-	todo := parser.ParseNewTodo("do this thing")
+	parser, list := newTestParser()
+	todo, _ := parser.ParseNewTodo([]string{"do", "this", "thing"}, list)
 	if todo.Subject != "do this thing" {
 		t.Error("Expected todo.Subject to equal 'do this thing'")
 	}
 }
 
 func TestParseSubjectWithDue(t *testing.T) {
-	parser := &Parser{}
-	todo := parser.ParseNewTodo("do this thing due tomorrow")
+	parser, list := newTestParser()
+	todo, _ := parser.ParseNewTodo([]string{"do", "this", "thing", "due:tomorrow"}, list)
 	if todo.Subject != "do this thing" {
 		t.Error("Expected todo.Subject to equal 'do this thing', got ", todo.Subject)
 	}
 }
 
-func TestParseExpandProjects(t *testing.T) {
+func TestParseNoMods(t *testing.T) {
 	assert := assert.New(t)
-	parser := &Parser{}
-	correctFormat := parser.ExpandProject("ex 113 +meeting: figures, slides, coffee, suger")
-	assert.Equal("+meeting", correctFormat)
-	wrongFormat1 := parser.ExpandProject("ex 114 +meeting figures, slides, coffee, suger")
-	assert.Equal("", wrongFormat1)
-	wrongFormat2 := parser.ExpandProject("ex 115 meeting: figures, slides, coffee, suger")
-	assert.Equal("", wrongFormat2)
-	wrongFormat3 := parser.ExpandProject("ex 116 meeting figures, slides, coffee, suger")
-	assert.Equal("", wrongFormat3)
-	wrongFormat4 := parser.ExpandProject("ex 117 +重要な會議: 図, コーヒー, 砂糖")
-	assert.Equal("+重要な會議", wrongFormat4)
+	parser, list := newTestParser()
+	todo, err := parser.ParseNewTodo([]string{}, list)
+	assert.Nil(todo)
+	assert.Nil(err)
 }
 
 func TestParseProjects(t *testing.T) {
-	parser := &Parser{}
-	todo := parser.ParseNewTodo("do this thing +proj1 +proj2 +專案3 +proj-name due tomorrow")
+	parser, list := newTestParser()
+	todo, _ := parser.ParseNewTodo([]string{"do", "this", "thing", "+proj1", "+proj2", "+專案3", "+proj-name", "due:tomorrow"}, list)
 	if len(todo.Projects) != 4 {
-		t.Error("Expected Projects length to be 3")
+		t.Error("Expected Projects length to be 4")
 	}
 	if todo.Projects[0] != "proj1" {
 		t.Error("todo.Projects[0] should equal 'proj1' but got", todo.Projects[0])
@@ -61,13 +59,13 @@ func TestParseProjects(t *testing.T) {
 }
 
 func TestParseContexts(t *testing.T) {
-	parser := &Parser{}
-	todo := parser.ParseNewTodo("do this thing with @bob and @mary due tomorrow")
+	parser, list := newTestParser()
+	todo, _ := parser.ParseNewTodo([]string{"do", "this", "thing", "with", "@bob", "and", "@mary", "due:tomorrow"}, list)
 	if len(todo.Contexts) != 2 {
-		t.Error("Expected Projects length to be 2")
+		t.Error("Expected Contexts length to be 2")
 	}
 	if todo.Contexts[0] != "bob" {
-		t.Error("todo.Contexts[0] should equal 'mary' but got", todo.Contexts[0])
+		t.Error("todo.Contexts[0] should equal 'bob' but got", todo.Contexts[0])
 	}
 	if todo.Contexts[1] != "mary" {
 		t.Error("todo.Contexts[1] should equal 'mary' but got", todo.Contexts[1])
@@ -75,29 +73,29 @@ func TestParseContexts(t *testing.T) {
 }
 
 func TestParseAddNote(t *testing.T) {
-	parser := &Parser{}
-	todo := parser.ParseNewTodo("add write the test functions")
+	parser, list := newTestParser()
+	todo, _ := parser.ParseNewTodo([]string{"write", "the", "test", "functions"}, list)
 
-	b1 := parser.ParseAddNote(todo, "an 1 TestPasrseAddNote")
-	b2 := parser.ParseAddNote(todo, "an 1 TestPasrseDeleteNote")
-	b3 := parser.ParseAddNote(todo, "an 1 TestPasrseEditNote")
+	b1 := parser.ParseAddNote(todo, []string{"TestPasrseAddNote"})
+	b2 := parser.ParseAddNote(todo, []string{"TestPasrseDeleteNote"})
+	b3 := parser.ParseAddNote(todo, []string{"TestPasrseEditNote"})
 
-	if !b1 || !b2 || !b3 {
+	if !b1 || !b2 || !b3 || len(todo.Notes) != 3 {
 		t.Error("Fail adding notes, expected 3 notes but", len(todo.Notes))
 	}
 }
 
 func TestParseDeleteNote(t *testing.T) {
-	parser := &Parser{}
-	todo := parser.ParseNewTodo("add buy notebook")
+	parser, list := newTestParser()
+	todo, _ := parser.ParseNewTodo([]string{"buy", "notebook"}, list)
 
 	todo.Notes = append(todo.Notes, "ASUStek")
 	todo.Notes = append(todo.Notes, "Apple")
 	todo.Notes = append(todo.Notes, "Dell")
 	todo.Notes = append(todo.Notes, "Acer")
 
-	b1 := parser.ParseDeleteNote(todo, "dn 1 1")
-	b2 := parser.ParseDeleteNote(todo, "dn 1 1")
+	b1 := parser.ParseDeleteNote(todo, []string{"1"})
+	b2 := parser.ParseDeleteNote(todo, []string{"1"})
 
 	if !b1 || !b2 {
 		t.Error("Fail deleting notes, expected 2 notes left but", len(todo.Notes))
@@ -109,54 +107,54 @@ func TestParseDeleteNote(t *testing.T) {
 }
 
 func TestParseEditNote(t *testing.T) {
-	parser := &Parser{}
-	todo := parser.ParseNewTodo("add record the weather")
+	parser, list := newTestParser()
+	todo, _ := parser.ParseNewTodo([]string{"record", "the", "weather"}, list)
 
 	todo.Notes = append(todo.Notes, "Aug 29 Wed")
 	todo.Notes = append(todo.Notes, "Cloudy")
 	todo.Notes = append(todo.Notes, "40°C")
 	todo.Notes = append(todo.Notes, "Tokyo")
 
-	parser.ParseEditNote(todo, "en 1 0 Aug 29 Tue")
+	parser.ParseEditNote(todo, []string{"0", "Aug", "29", "Tue"})
 	if todo.Notes[0] != "Aug 29 Tue" {
 		t.Error("Fail editing notes, note 0 should be \"Aug 29 Tue\" but got", todo.Notes[0])
 	}
 
-	parser.ParseEditNote(todo, "en 1 1 Sunny")
+	parser.ParseEditNote(todo, []string{"1", "Sunny"})
 	if todo.Notes[1] != "Sunny" {
 		t.Error("Fail editing notes, note 1 should be \"Sunny\" but got", todo.Notes[1])
 	}
 
-	parser.ParseEditNote(todo, "en 1 2 22°C")
+	parser.ParseEditNote(todo, []string{"2", "22°C"})
 	if todo.Notes[2] != "22°C" {
 		t.Error("Fail editing notes, note 2 should be \"22°C\" but got", todo.Notes[2])
 	}
 
-	parser.ParseEditNote(todo, "en 1 3 Seoul")
+	parser.ParseEditNote(todo, []string{"3", "Seoul"})
 	if todo.Notes[3] != "Seoul" {
 		t.Error("Fail editing notes, note 3 should be \"Seoul\" but got", todo.Notes[3])
 	}
 }
 
 func TestHandleNotes(t *testing.T) {
-	parser := &Parser{}
-	todo := parser.ParseNewTodo("add search engine survey")
+	parser, list := newTestParser()
+	todo, _ := parser.ParseNewTodo([]string{"search", "engine", "survey"}, list)
 
-	if !parser.ParseAddNote(todo, "an 1 www.google.com") {
+	if !parser.ParseAddNote(todo, []string{"www.google.com"}) {
 		t.Error("Expected Notes to be added")
 	}
 	if todo.Notes[0] != "www.google.com" {
 		t.Error("Expected note 1 to be 'www.google.com' but got", todo.Notes[0])
 	}
 
-	if !parser.ParseEditNote(todo, "en 1 0 www.duckduckgo.com") {
+	if !parser.ParseEditNote(todo, []string{"0", "www.duckduckgo.com"}) {
 		t.Error("Expected Notes to be editted")
 	}
 	if todo.Notes[0] != "www.duckduckgo.com" {
 		t.Error("Expected note 1 to be 'www.duckduckgo.com' but got", todo.Notes[0])
 	}
 
-	if !parser.ParseDeleteNote(todo, "dn 1 0") {
+	if !parser.ParseDeleteNote(todo, []string{"0"}) {
 		t.Error("Expected Notes to be deleted")
 	}
 	if len(todo.Notes) != 0 {
@@ -166,135 +164,116 @@ func TestHandleNotes(t *testing.T) {
 
 func TestDueToday(t *testing.T) {
 	assert := assert.New(t)
-	parser := &Parser{}
-	expectedDate := bod(Now).Format("2006-01-02")
+	parser, list := newTestParser()
+	expectedDate := timeToString(bod(parserClock.Now()))
 
-	todo := parser.ParseNewTodo("do this thing with @bob and @mary due today")
+	todo, _ := parser.ParseNewTodo([]string{"do", "this", "thing", "due:today"}, list)
 	assert.Equal(expectedDate, todo.Due)
 
-	todo = parser.ParseNewTodo("do this thing with @bob and @mary due tod")
+	todo, _ = parser.ParseNewTodo([]string{"do", "this", "thing", "due:tod"}, list)
 	assert.Equal(expectedDate, todo.Due)
 }
 
 func TestDueTomorrow(t *testing.T) {
 	assert := assert.New(t)
-	parser := &Parser{}
-	expectedDate := bod(Now).AddDate(0, 0, 1).Format("2006-01-02")
+	parser, list := newTestParser()
+	expectedDate := timeToString(bod(parserClock.Now()).AddDate(0, 0, 1))
 
-	todo := parser.ParseNewTodo("do this thing with @bob and @mary due tomorrow")
+	todo, _ := parser.ParseNewTodo([]string{"do", "this", "thing", "due:tomorrow"}, list)
 	assert.Equal(expectedDate, todo.Due)
 
-	todo = parser.ParseNewTodo("do this thing with @bob and @mary due tom")
+	todo, _ = parser.ParseNewTodo([]string{"do", "this", "thing", "due:tom"}, list)
 	assert.Equal(expectedDate, todo.Due)
 }
 
 func TestDueSpecific(t *testing.T) {
 	assert := assert.New(t)
-	parser := &Parser{}
-	todo := parser.ParseNewTodo("do this thing with @bob and @mary due jun 1")
-	year := strconv.Itoa(Now.Year())
-	assert.Equal(fmt.Sprintf("%s-06-01", year), todo.Due)
+	parser, list := newTestParser()
+	todo, _ := parser.ParseNewTodo([]string{"do", "this", "thing", "due:2016-06-01"}, list)
+	assert.Equal(timeToString(time.Date(2016, 6, 1, 0, 0, 0, 0, time.UTC)), todo.Due)
+
+	todo, _ = parser.ParseNewTodo([]string{"do", "this", "thing", "due:20160601"}, list)
+	assert.Equal(timeToString(time.Date(2016, 6, 1, 0, 0, 0, 0, time.UTC)), todo.Due)
+}
+
+func TestDueBadDate(t *testing.T) {
+	assert := assert.New(t)
+	parser, list := newTestParser()
+	_, err := parser.ParseNewTodo([]string{"do", "this", "thing", "due:jun"}, list)
+	assert.True(errors.Is(err, ErrBadDate))
 }
 
-func TestDueSpecificEuropeanDate(t *testing.T) {
+func TestDueClear(t *testing.T) {
 	assert := assert.New(t)
-	parser := &Parser{}
-	todo := parser.ParseNewTodo("do this thing with @bob and @mary due 1 jun")
-	year := strconv.Itoa(Now.Year())
-	assert.Equal(fmt.Sprintf("%s-06-01", year), todo.Due)
+	parser, list := newTestParser()
+	todo := &Todo{Subject: "pick up the trash", Due: "2016-11-25"}
+	parser.ParseEditTodo(todo, []string{"due:"}, list)
+	assert.Equal("", todo.Due)
 }
 
 func TestMondayOnSunday(t *testing.T) {
 	assert := assert.New(t)
-	parser := &Parser{}
 	now, _ := time.Parse("2006-01-02", "2016-04-24")
-	assert.Equal("2016-04-25", parser.monday(now))
+	assert.Equal("2016-04-25", monday(now, true).Format("2006-01-02"))
 }
 
 func TestMondayOnMonday(t *testing.T) {
 	assert := assert.New(t)
-	parser := &Parser{}
 	now, _ := time.Parse("2006-01-02", "2016-04-25")
-	assert.Equal("2016-04-25", parser.monday(now))
+	assert.Equal("2016-04-25", monday(now, true).Format("2006-01-02"))
 }
 
 func TestMondayOnTuesday(t *testing.T) {
 	assert := assert.New(t)
-	parser := &Parser{}
 	now, _ := time.Parse("2006-01-02", "2016-04-26")
-	assert.Equal("2016-05-02", parser.monday(now))
+	assert.Equal("2016-05-02", monday(now, true).Format("2006-01-02"))
+	assert.Equal("2016-04-25", monday(now, false).Format("2006-01-02"))
 }
 
 func TestTuesdayOnMonday(t *testing.T) {
 	assert := assert.New(t)
-	parser := &Parser{}
 	now, _ := time.Parse("2006-01-02", "2016-04-25")
-	assert.Equal("2016-04-26", parser.tuesday(now))
+	assert.Equal("2016-04-26", tuesday(now, true).Format("2006-01-02"))
 }
 
 func TestTuesdayOnWednesday(t *testing.T) {
 	assert := assert.New(t)
-	parser := &Parser{}
 	now, _ := time.Parse("2006-01-02", "2016-04-27")
-	assert.Equal("2016-05-03", parser.tuesday(now))
-}
-
-func TestDueOnSpecificDate(t *testing.T) {
-	assert := assert.New(t)
-	parser := &Parser{}
-	year := strconv.Itoa(Now.Year())
-	assert.Equal(fmt.Sprintf("%s-05-02", year), parser.Due("due may 2", Now))
-	assert.Equal(fmt.Sprintf("%s-06-01", year), parser.Due("due jun 1", Now))
+	assert.Equal("2016-05-03", tuesday(now, true).Format("2006-01-02"))
 }
 
-func TestDueOnSpecificDateEuropeFormat(t *testing.T) {
+func TestParseRelativeDate(t *testing.T) {
 	assert := assert.New(t)
-	parser := &Parser{}
-	year := strconv.Itoa(Now.Year())
-	assert.Equal(fmt.Sprintf("%s-05-02", year), parser.Due("due 2 may", Now))
-	assert.Equal(fmt.Sprintf("%s-06-01", year), parser.Due("due 1 jun", Now))
-}
-
-func TestDueOnSpecificDateEuropean(t *testing.T) {
-	assert := assert.New(t)
-	parser := &Parser{}
-	year := strconv.Itoa(Now.Year())
-	assert.Equal(fmt.Sprintf("%s-05-02", year), parser.Due("due 2 may", Now))
-}
-
-func TestDueIntelligentlyChoosesCorrectYear(t *testing.T) {
-	assert := assert.New(t)
-	parser := &Parser{}
-	marchTime, _ := time.Parse("2006-01-02", "2016-03-25")
-	januaryTime, _ := time.Parse("2006-01-02", "2016-01-05")
-	septemberTime, _ := time.Parse("2006-01-02", "2016-09-25")
-	decemberTime, _ := time.Parse("2006-01-02", "2016-12-25")
-
-	assert.Equal("2016-01-10", parser.parseArbitraryDate("jan 10", januaryTime))
-	assert.Equal("2016-01-10", parser.parseArbitraryDate("jan 10", marchTime))
-	assert.Equal("2017-01-10", parser.parseArbitraryDate("jan 10", septemberTime))
-	assert.Equal("2017-01-10", parser.parseArbitraryDate("jan 10", decemberTime))
+	parser, _ := newTestParser()
+	now := parserClock.Now()
+
+	date, _ := parser.ParseDateTime("3d", now)
+	assert.Equal(bod(now).AddDate(0, 0, 3), date)
+	date, _ = parser.ParseDateTime("-1w", now)
+	assert.Equal(bod(now).AddDate(0, 0, -7), date)
+	date, _ = parser.ParseDateTime("2h", now)
+	assert.Equal(now.Add(2*time.Hour), date)
 }
 
 func TestParseEditTodoJustDate(t *testing.T) {
 	assert := assert.New(t)
-	parser := &Parser{}
-	todo := NewTodo()
-	tomorrow := Now.AddDate(0, 0, 1).Format("2006-01-02")
+	parser, list := newTestParser()
+	todo, _ := NewTodo()
+	tomorrow := timeToString(bod(parserClock.Now()).AddDate(0, 0, 1))
 
-	parser.ParseEditTodo(todo, "e 24 due tom")
+	parser.ParseEditTodo(todo, []string{"due:tom"}, list)
 
 	assert.Equal(todo.Due, tomorrow)
 }
 
 func TestParseEditTodoJustDateDoesNotEditExistingSubject(t *testing.T) {
 	assert := assert.New(t)
-	parser := &Parser{}
-	todo := NewTodo()
+	parser, list := newTestParser()
+	todo, _ := NewTodo()
 	todo.Subject = "pick up the trash"
-	tomorrow := Now.AddDate(0, 0, 1).Format("2006-01-02")
+	tomorrow := timeToString(bod(parserClock.Now()).AddDate(0, 0, 1))
 
-	parser.ParseEditTodo(todo, "e 24 due tom")
+	parser.ParseEditTodo(todo, []string{"due:tom"}, list)
 
 	assert.Equal(todo.Due, tomorrow)
 	assert.Equal(todo.Subject, "pick up the trash")
@@ -302,46 +281,59 @@ func TestParseEditTodoJustDateDoesNotEditExistingSubject(t *testing.T) {
 
 func TestParseEditTodoJustSubject(t *testing.T) {
 	assert := assert.New(t)
-	parser := &Parser{}
+	parser, list := newTestParser()
 	todo := &Todo{Subject: "pick up the trash", Due: "2016-11-25"}
 
-	parser.ParseEditTodo(todo, "e 24 changed the todo")
+	parser.ParseEditTodo(todo, []string{"changed", "the", "todo"}, list)
 
 	assert.Equal(todo.Due, "2016-11-25")
 	assert.Equal(todo.Subject, "changed the todo")
 }
 
-func TestParseEditTodoSubjectUpdatesProjectsAndContexts(t *testing.T) {
+func TestParseEditTodoPrependAndAppend(t *testing.T) {
 	assert := assert.New(t)
-	parser := &Parser{}
+	parser, list := newTestParser()
+	todo := &Todo{Subject: "the trash"}
+
+	parser.ParseEditTodo(todo, []string{"pre:pick", "up", ""}, list)
+	assert.Equal("pick up the trash", todo.Subject)
+
+	parser.ParseEditTodo(todo, []string{"app:", "today"}, list)
+	assert.Equal("pick up the trash today", todo.Subject)
+}
+
+func TestParseEditTodoUpdatesProjectsAndContexts(t *testing.T) {
+	assert := assert.New(t)
+	parser, list := newTestParser()
 	todo := &Todo{
-		Subject:  "pick up the +trash with @dad",
+		Subject:  "pick up the trash",
 		Due:      "2016-11-25",
 		Projects: []string{"trash"},
 		Contexts: []string{"dad"},
+		Ordinals: map[string]int{},
 	}
 
-	parser.ParseEditTodo(todo, "e 24 get the +garbage with @mom")
+	parser.ParseEditTodo(todo, []string{"+garbage", "-trash", "@mom", "-@dad"}, list)
 
 	assert.Equal(todo.Due, "2016-11-25")
-	assert.Equal(todo.Subject, "get the +garbage with @mom")
+	assert.Equal(todo.Subject, "pick up the trash")
 	assert.Equal(todo.Projects, []string{"garbage"})
 	assert.Equal(todo.Contexts, []string{"mom"})
 }
 
 func TestParseEditTodoWithSubjectAndDue(t *testing.T) {
 	assert := assert.New(t)
-	parser := &Parser{}
+	parser, list := newTestParser()
 	todo := &Todo{
-		Subject:  "pick up the +trash with @dad",
+		Subject:  "pick up the trash",
 		Due:      "2016-11-25",
 		Projects: []string{"trash"},
 		Contexts: []string{"dad"},
 	}
-	tomorrow := Now.AddDate(0, 0, 1).Format("2006-01-02")
+	tomorrow := timeToString(bod(parserClock.Now()).AddDate(0, 0, 1))
 
-	parser.ParseEditTodo(todo, "e 24 get the +garbage with @mom due tom")
+	parser.ParseEditTodo(todo, []string{"get", "the", "garbage", "due:tom"}, list)
 
 	assert.Equal(todo.Due, tomorrow)
-	assert.Equal(todo.Subject, "get the +garbage with @mom")
+	assert.Equal(todo.Subject, "get the garbage")
 }
@@ -0,0 +1,158 @@
+package todolist
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+//Plugins are executables named todo-<name>, in the plugins directory or on the PATH. 'todo [filters] <name> [args]'
+//runs one if no command or alias is named <name>.
+const PluginPrefix = "todo-"
+
+var pluginNameRegex = regexp.MustCompile(`^[a-zA-Z][\w-]*$`)
+
+//A plugin exited with a non-zero code. It has printed its own message, so the code is passed on quietly.
+type PluginExitError struct {
+	Name string
+	Code int
+}
+
+func (e *PluginExitError) Error() string {
+	return fmt.Sprintf("%s%s exited with code %d", PluginPrefix, e.Name, e.Code)
+}
+
+//The path to the plugin for a command name, from the plugins directory, or else the PATH. Blank if there is none.
+func FindPlugin(dir string, name string) string {
+	if !pluginNameRegex.MatchString(name) {
+		return ""
+	}
+	if dir != "" {
+		path := filepath.Join(dir, PluginPrefix+name)
+		if info, err := os.Stat(path); err == nil && isExecutable(info) {
+			return path
+		}
+	}
+	path, err := exec.LookPath(PluginPrefix + name)
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+//The installed plugins by name, with their paths. The plugins directory comes first, then the PATH in order.
+func ListPlugins(dir string) map[string]string {
+	plugins := map[string]string{}
+	for _, d := range append([]string{dir}, filepath.SplitList(os.Getenv("PATH"))...) {
+		if d == "" {
+			continue
+		}
+		files, err := ioutil.ReadDir(d)
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			name := strings.TrimPrefix(file.Name(), PluginPrefix)
+			if name == file.Name() || !pluginNameRegex.MatchString(name) {
+				continue
+			}
+			//Follow symlinks, as installs often link to the plugin
+			info, err := os.Stat(filepath.Join(d, file.Name()))
+			if _, found := plugins[name]; !found && err == nil && isExecutable(info) {
+				plugins[name] = filepath.Join(d, file.Name())
+			}
+		}
+	}
+	return plugins
+}
+
+func isExecutable(info os.FileInfo) bool {
+	return !info.IsDir() && info.Mode()&0111 != 0
+}
+
+//A command running the plugin. If withTodos, the todos matching the filters are sent on stdin as a JSON array.
+func (a *App) newPluginCommand(name string, path string, withTodos bool) *CommandImpl {
+	return NewCommand(name, false, true, func(c *CommandImpl) error {
+		return a.ExecPlugin(c, path, withTodos)
+	})
+}
+
+func (a *App) ExecPlugin(c *CommandImpl, path string, withTodos bool) error {
+	/*
+		td [filters] <plugin> [args]
+		Run todo-<plugin> with the args. Filters send the todos matching them on stdin as JSON.
+	*/
+	cmd := exec.Command(path, c.Args...)
+	cmd.Env = append(os.Environ(), a.pluginEnv(c)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if withTodos {
+		if err := a.LoadPending(); err != nil {
+			return err
+		}
+		filtered, err := NewToDoFilter(a.TodoList.Todos(), a.Clock).Filter(c.Filters)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(filtered)
+		if err != nil {
+			return err
+		}
+		cmd.Stdin = bytes.NewReader(data)
+	}
+	if err := cmd.Run(); err != nil {
+		if exitErr, isExit := err.(*exec.ExitError); isExit {
+			return &PluginExitError{Name: c.Cmd, Code: exitErr.ExitCode()}
+		}
+		return fmt.Errorf("Error running plugin %s: %w", path, err)
+	}
+	return nil
+}
+
+//The environment for a plugin: the repo files, the todo executable to call back, the filters, and the config
+//values as TODO_CFG_<KEY>, with dots as underscores (e.g. TODO_CFG_SYNC_FILEPATH for sync.filepath). Not TODO_<KEY>,
+//which todo reads back as a setting, so todo called back by the plugin would take them over the repo's .todorc.
+func (a *App) pluginEnv(c *CommandImpl) []string {
+	pending, archived, backlog := getPendingLocation(), getArchivedLocation(), getBacklogLocation()
+	if fs, ok := a.TodoStore.(*FileStore); ok {
+		fs.Locate()
+		pending, archived, backlog = fs.PendingFileLocation, fs.ArchivedFileLocation, fs.BacklogFileLocation
+	}
+	self, _ := os.Executable()
+	env := []string{}
+	for key, value := range a.Cfg.Values {
+		env = append(env, ConfigEnvName(key)+"="+value)
+	}
+	return append(env,
+		"TODO_PLUGIN="+c.Cmd,
+		"TODO_BIN="+self,
+		"TODO_PENDING_FILE="+absPath(pending),
+		"TODO_ARCHIVE_FILE="+absPath(archived),
+		"TODO_BACKLOG_FILE="+absPath(backlog),
+		"TODO_CONFIG_FILE="+absPath(a.Cfg.File),
+		"TODO_REPO="+absPath(currentRepoDir()),
+		"TODO_FILTERS="+strings.Join(c.Filters, " "),
+	)
+}
+
+//The plugin environment variable for a config key, e.g. TODO_CFG_REPORT_DEFAULT_COLUMNS for report.default.columns
+func ConfigEnvName(key string) string {
+	return "TODO_CFG_" + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+}
+
+func absPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
@@ -0,0 +1,72 @@
+package todolist
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlugins(t *testing.T) {
+	assert := assert.New(t)
+	dir, _ := ioutil.TempDir("", "plugins")
+	defer os.RemoveAll(dir)
+	bin := filepath.Join(dir, "bin")
+	os.Mkdir(bin, 0755)
+	script := func(path string, body string) {
+		ioutil.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0755)
+	}
+	out := filepath.Join(dir, "out")
+	script(filepath.Join(dir, "todo-count"), `echo "$TODO_PLUGIN|$TODO_FILTERS|$*|$TODO_CFG_SYNC_FILEPATH" > `+out+`; cat >> `+out+`; exit 3`)
+	script(filepath.Join(bin, "todo-count"), `exit 0`)
+	script(filepath.Join(bin, "todo-sum"), `exit 0`)
+	ioutil.WriteFile(filepath.Join(bin, "todo-notes.txt"), []byte("not executable"), 0644)
+	path := os.Getenv("PATH")
+	os.Setenv("PATH", bin+string(os.PathListSeparator)+path)
+	defer os.Setenv("PATH", path)
+
+	//The plugins directory comes before the PATH
+	assert.Equal(filepath.Join(dir, "todo-count"), FindPlugin(dir, "count"))
+	assert.Equal(filepath.Join(bin, "todo-sum"), FindPlugin(dir, "sum"))
+	assert.Equal("", FindPlugin(dir, "notes.txt"))
+	assert.Equal("", FindPlugin(dir, "+Work"))
+	plugins := ListPlugins(dir)
+	assert.Equal(filepath.Join(dir, "todo-count"), plugins["count"])
+	assert.Equal(filepath.Join(bin, "todo-sum"), plugins["sum"])
+	_, found := plugins["notes.txt"]
+	assert.False(found)
+
+	store := &recordingStore{pending: []*Todo{
+		&Todo{Id: 1, Subject: "one", Status: "Pending", Projects: []string{"Work"}},
+		&Todo{Id: 2, Subject: "two", Status: "Pending"},
+	}}
+	app := &App{
+		TodoList:   &TodoList{},
+		TodoStore:  store,
+		Cfg:        &Config{PluginsDir: dir, Values: map[string]string{"sync.filepath": "/tmp/sync"}},
+		CommandMap: map[string]Command{},
+		Clock:      FixedClock{Time: time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)},
+	}
+	command := app.ProcessCmdLine("+Work count by ctx")
+	assert.Equal("count", command.GetCmd())
+	assert.Equal([]string{"+Work"}, command.GetFilters())
+	assert.Equal([]string{"by", "ctx"}, command.GetArgs())
+
+	//The plugin's exit code is passed on. With filters, it gets the todos matching them.
+	err := command.Exec(app)
+	var exitErr *PluginExitError
+	assert.True(errors.As(err, &exitErr))
+	assert.Equal(3, exitErr.Code)
+	data, _ := ioutil.ReadFile(out)
+	lines := strings.Split(string(data), "\n")
+	assert.Equal("count|+Work|by ctx|/tmp/sync", lines[0])
+	assert.Contains(lines[1], `"subject":"one"`)
+	assert.NotContains(lines[1], `"subject":"two"`)
+
+	assert.Equal("TODO_CFG_REPORT_DEFAULT_COLUMNS", ConfigEnvName("report.default.columns"))
+}
@@ -0,0 +1,114 @@
+package todolist
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	ui "github.com/gizak/termui"
+	termbox "github.com/nsf/termbox-go"
+)
+
+//Full screen pomodoro timer. Counts down work then break periods with a progress gauge.
+type PomodoroTimer struct {
+	Subject string
+	Work    time.Duration
+	Break   time.Duration
+	events  <-chan ui.Event
+}
+
+func NewPomodoroTimer(subject string, work time.Duration, brk time.Duration) *PomodoroTimer {
+	return &PomodoroTimer{Subject: subject, Work: work, Break: brk}
+}
+
+//Run pomodoros until the user quits. onComplete is called with the start and end of each completed work period.
+func (p *PomodoroTimer) Run(onComplete func(start time.Time, end time.Time)) error {
+	err := ui.Init()
+	if err != nil {
+		return err
+	}
+	defer ui.Close()
+	if w, h := termbox.Size(); w <= 0 || h <= 0 {
+		return errors.New("terminal size unknown")
+	}
+	//Poll once. Each call to PollEvents starts a goroutine reading terminal events.
+	p.events = ui.PollEvents()
+
+	for count := 1; ; count++ {
+		start := time.Now()
+		if !p.countdown(fmt.Sprintf("Pomodoro %d: %s", count, p.Subject), p.Work, ui.ColorRed) {
+			return nil
+		}
+		onComplete(start, time.Now())
+		if !p.countdown("Break", p.Break, ui.ColorGreen) {
+			return nil
+		}
+		if !p.waitForNext() {
+			return nil
+		}
+	}
+}
+
+//Returns false if the user quit before the countdown finished
+func (p *PomodoroTimer) countdown(title string, length time.Duration, color ui.Attribute) bool {
+	start := time.Now()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	p.renderGauge(title, length, 0, color)
+	for {
+		select {
+		case e := <-p.events:
+			if isQuitEvent(e) {
+				return false
+			}
+			if e.Type == ui.ResizeEvent {
+				p.renderGauge(title, length, time.Since(start), color)
+			}
+		case <-ticker.C:
+			elapsed := time.Since(start)
+			if elapsed >= length {
+				return true
+			}
+			p.renderGauge(title, length, elapsed, color)
+		}
+	}
+}
+
+func (p *PomodoroTimer) waitForNext() bool {
+	par := ui.NewParagraph("Break over. Press any key to start the next pomodoro, q to quit.")
+	w, _ := termbox.Size()
+	par.Width = w
+	par.Height = 3
+	par.BorderLabel = p.Subject
+	par.BorderLabelFg = ui.ColorYellow
+	ui.Clear()
+	ui.Render(par)
+	for e := range p.events {
+		if e.Type != ui.KeyboardEvent {
+			continue
+		}
+		return !isQuitEvent(e)
+	}
+	return false
+}
+
+func (p *PomodoroTimer) renderGauge(title string, length time.Duration, elapsed time.Duration, color ui.Attribute) {
+	termbox.Sync()
+	w, _ := termbox.Size()
+	remaining := (length - elapsed).Round(time.Second)
+	g := ui.NewGauge()
+	g.Percent = int(elapsed * 100 / length)
+	g.Width = w
+	g.Height = 3
+	g.BarColor = color
+	g.BorderLabel = title
+	g.BorderLabelFg = ui.ColorYellow
+	g.Label = fmt.Sprintf("%02d:%02d remaining (q to quit)", int(remaining.Minutes()), int(remaining.Seconds())%60)
+	g.LabelAlign = ui.AlignCenter
+	ui.Clear()
+	ui.Render(g)
+}
+
+func isQuitEvent(e ui.Event) bool {
+	return e.Type == ui.KeyboardEvent && (e.ID == "q" || e.ID == "<C-c>" || e.ID == "<Escape>")
+}
@@ -1,6 +1,37 @@
 package todolist
 
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
 type Printer interface {
 	//Print(*GroupedTodos, bool)
-	PrintReport(*Report, []*Todo)
+	PrintReport(*Report, []*Todo) error
+	PrintSetCounts(set string, m map[string]int) error
+	PrintTodoDetail(todos []*Todo) error
+	PrintStats(filtered []*Todo, groupBy string, sumBy string, cols []string, chart bool, rangeTimes []time.Time) error
+}
+
+//Printer for the output: arg. Blank or screen for colored tables, json, csv or tsv for use by other programs,
+//md or html for documents. All but the screen printer write to w. Dates are shown relative to now on the clock.
+//Only the screen printer uses the colors.
+func NewPrinter(output string, w io.Writer, clock Clock, colors map[string]string) (Printer, error) {
+	switch strings.ToLower(output) {
+	case "", "screen":
+		return NewScreenPrinter(clock, colors), nil
+	case "json":
+		return NewJSONPrinter(w, clock), nil
+	case "csv":
+		return NewCSVPrinter(w, clock), nil
+	case "tsv":
+		return NewTSVPrinter(w, clock), nil
+	case "md", "markdown":
+		return NewMarkdownPrinter(w, clock), nil
+	case "html":
+		return NewHTMLPrinter(w, clock), nil
+	}
+	return nil, fmt.Errorf("unknown output %q. Expected json, csv, tsv, md or html", output)
 }
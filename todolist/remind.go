@@ -0,0 +1,297 @@
+package todolist
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//Fired reminders are forgotten after this long, to keep the file small
+const firedReminderExpiry = 60 * 24 * time.Hour
+
+//A reminder of an event (due, scheduled or wait ends) for a todo, fired a lead time before the event
+type Reminder struct {
+	Todo  *Todo
+	Event string
+	At    time.Time     //When the event happens
+	Lead  time.Duration //How long before the event to remind
+}
+
+//Identifies the reminder across restarts. Changing the date of the event makes it a new reminder.
+func (r *Reminder) Key() string {
+	return r.Todo.Uuid + "|" + r.Event + "|" + timeToString(r.At) + "|" + r.Lead.String()
+}
+
+func (r *Reminder) FireAt() time.Time {
+	return r.At.Add(-r.Lead)
+}
+
+func (r *Reminder) Message(now time.Time) string {
+	verb := "is due"
+	if r.Event == AgendaScheduled {
+		verb = "is scheduled"
+	} else if r.Event == AgendaWaitEnds {
+		verb = "stops waiting"
+	}
+	when := "now"
+	if left := r.At.Sub(now); left >= time.Minute {
+		when = "in " + durationToString(left)
+	}
+	return fmt.Sprintf("Todo %d %s %s (%s): %s", r.Todo.Id, verb, when, r.At.Format("Mon Jan 02 15:04"), r.Todo.Subject)
+}
+
+//Reminders for the events of todos at lead times before them, and which of them have been fired
+type Reminders struct {
+	Leads    []time.Duration
+	Events   []string          //Events to remind of: due, scheduled, wait ends
+	Interval time.Duration     //How often the todos are checked. A reminder may still fire this long after its event.
+	Fired    map[string]string //Keys of fired reminders, with when they fired
+}
+
+func NewReminders(leads []time.Duration, events []string, interval time.Duration) *Reminders {
+	return &Reminders{Leads: leads, Events: events, Interval: interval, Fired: map[string]string{}}
+}
+
+func reminderDateFunc(event string) func(*Todo) time.Time {
+	switch event {
+	case AgendaScheduled:
+		return filterOnScheduled
+	case AgendaWaitEnds:
+		return filterOnWait
+	}
+	return filterOnDue
+}
+
+func (r *Reminders) maxLead() time.Duration {
+	max := time.Duration(0)
+	for _, lead := range r.Leads {
+		if lead > max {
+			max = lead
+		}
+	}
+	return max
+}
+
+//Unfired reminders of pending todos with events from the last interval until the longest lead from now
+func (r *Reminders) pending(todos []*Todo, now time.Time) []*Reminder {
+	reminders := []*Reminder{}
+	df := NewDateFilter(todos, FixedClock{Time: now})
+	for _, event := range r.Events {
+		dateOf := reminderDateFunc(event)
+		for _, todo := range df.filterBetweenDatesInclusive(now.Add(-r.Interval), now.Add(r.maxLead()), dateOf) {
+			if todo.Completed {
+				continue
+			}
+			for _, lead := range r.Leads {
+				rem := &Reminder{Todo: todo, Event: event, At: dateOf(todo), Lead: lead}
+				if _, fired := r.Fired[rem.Key()]; !fired && now.Before(rem.At.Add(r.Interval)) {
+					reminders = append(reminders, rem)
+				}
+			}
+		}
+	}
+	sort.SliceStable(reminders, func(i, j int) bool { return reminders[i].FireAt().Before(reminders[j].FireAt()) })
+	return reminders
+}
+
+//Reminders to fire now. When more than one lead time has passed for an event (e.g. 1d and 1h, when the todo was
+//added an hour before it is due), only the shortest fires.
+func (r *Reminders) Due(todos []*Todo, now time.Time) []*Reminder {
+	due := []*Reminder{}
+	shortest := map[string]*Reminder{}
+	for _, rem := range r.pending(todos, now) {
+		if now.Before(rem.FireAt()) {
+			continue
+		}
+		event := rem.Todo.Uuid + "|" + rem.Event
+		if prev, ok := shortest[event]; ok {
+			if rem.Lead < prev.Lead {
+				*prev = *rem
+			}
+			continue
+		}
+		shortest[event] = rem
+		due = append(due, rem)
+	}
+	return due
+}
+
+//When the next reminder fires. Zero if none will fire within the longest lead.
+func (r *Reminders) Next(todos []*Todo, now time.Time) time.Time {
+	for _, rem := range r.pending(todos, now) {
+		if rem.FireAt().After(now) {
+			return rem.FireAt()
+		}
+	}
+	return time.Time{}
+}
+
+//Mark the reminder fired, and those at longer lead times for the same event, which are too late to fire
+func (r *Reminders) MarkFired(rem *Reminder, now time.Time) {
+	for _, lead := range r.Leads {
+		if lead >= rem.Lead {
+			r.Fired[(&Reminder{Todo: rem.Todo, Event: rem.Event, At: rem.At, Lead: lead}).Key()] = timeToString(now)
+		}
+	}
+}
+
+//Forget reminders fired long ago
+func (r *Reminders) Prune(now time.Time) {
+	for key, fired := range r.Fired {
+		if now.Sub(stringToTime(fired)) > firedReminderExpiry {
+			delete(r.Fired, key)
+		}
+	}
+}
+
+func (r *Reminders) LoadFired(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &r.Fired)
+}
+
+func (r *Reminders) SaveFired(path string) error {
+	data, err := json.MarshalIndent(r.Fired, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+//Lead times like 1h,1d (m, h, d or w). 0 reminds when the event happens.
+func ParseLeadTimes(value string) ([]time.Duration, error) {
+	r := regexp.MustCompile(`^(\d+)([mhdw])$`)
+	leads := []time.Duration{}
+	for _, val := range strings.Split(value, ",") {
+		val = strings.ToLower(strings.TrimSpace(val))
+		if val == "0" {
+			leads = append(leads, 0)
+			continue
+		}
+		matches := r.FindStringSubmatch(val)
+		if matches == nil {
+			return nil, errors.New("Expected lead times like 15m, 1h, 1d or 1w: " + val)
+		}
+		cnt, _ := strconv.Atoi(matches[1])
+		unit := map[string]time.Duration{"m": time.Minute, "h": time.Hour, "d": 24 * time.Hour, "w": 7 * 24 * time.Hour}[matches[2]]
+		leads = append(leads, time.Duration(cnt)*unit)
+	}
+	return leads, nil
+}
+
+//Lead time in the units it is configured in, e.g. 1h or 2d
+func leadToString(lead time.Duration) string {
+	units := []struct {
+		suffix string
+		length time.Duration
+	}{{"w", 7 * 24 * time.Hour}, {"d", 24 * time.Hour}, {"h", time.Hour}, {"m", time.Minute}}
+	for _, unit := range units {
+		if lead >= unit.length && lead%unit.length == 0 {
+			return fmt.Sprintf("%d%s", lead/unit.length, unit.suffix)
+		}
+	}
+	return "0"
+}
+
+func remindLeadsString(leads []time.Duration) string {
+	vals := []string{}
+	for _, lead := range leads {
+		vals = append(vals, leadToString(lead))
+	}
+	return strings.Join(vals, ", ")
+}
+
+//Events like due,scheduled,wait
+func parseRemindEvents(value string) ([]string, error) {
+	events := []string{}
+	for _, val := range strings.Split(value, ",") {
+		switch strings.ToLower(strings.TrimSpace(val)) {
+		case "due":
+			events = append(events, AgendaDue)
+		case "scheduled", "sched":
+			events = append(events, AgendaScheduled)
+		case "wait":
+			events = append(events, AgendaWaitEnds)
+		default:
+			return nil, errors.New("Expected due, scheduled or wait: " + val)
+		}
+	}
+	return events, nil
+}
+
+//Runs in the foreground, checking the todos every interval (or sooner, when a reminder is next due) and firing
+//reminders with the notification command, or printing them if there is none
+type ReminderDaemon struct {
+	Reminders *Reminders
+	Cmd       string              //Notification command. The message is added as the last argument.
+	FiredFile string              //Where fired reminders are remembered
+	Load      func() []*Todo      //Loads the todos afresh, to pick up changes made while running
+	Clock     Clock               //Current time
+	Sleep     func(time.Duration) //Waits until the next check
+	Out       io.Writer           //Where reminders are printed without a command, and errors
+}
+
+//Fire the reminders due now. Returns how many fired.
+func (d *ReminderDaemon) Check() int {
+	now := d.Clock.Now()
+	fired := 0
+	for _, rem := range d.Reminders.Due(d.Load(), now) {
+		if err := d.notify(rem, now); err != nil {
+			fmt.Fprintln(d.Out, "Error running reminder command: ", err)
+		}
+		d.Reminders.MarkFired(rem, now)
+		fired++
+	}
+	if fired > 0 {
+		d.Reminders.Prune(now)
+		if err := d.Reminders.SaveFired(d.FiredFile); err != nil {
+			fmt.Fprintln(d.Out, "Error saving fired reminders: ", err)
+		}
+	}
+	return fired
+}
+
+//Check until killed
+func (d *ReminderDaemon) Run() {
+	for {
+		d.Check()
+		now := d.Clock.Now()
+		wait := d.Reminders.Interval
+		if next := d.Reminders.Next(d.Load(), now); !next.IsZero() && next.Sub(now) < wait {
+			wait = next.Sub(now)
+		}
+		d.Sleep(wait)
+	}
+}
+
+func (d *ReminderDaemon) notify(rem *Reminder, now time.Time) error {
+	msg := rem.Message(now)
+	if d.Cmd == "" {
+		fmt.Fprintln(d.Out, "\a"+msg)
+		return nil
+	}
+	args := strings.Fields(d.Cmd) //e.g. remind.cmd=notify-send Todo
+	cmd := exec.Command(args[0], append(args[1:], msg)...)
+	cmd.Env = append(os.Environ(),
+		"TODO_ID="+strconv.Itoa(rem.Todo.Id),
+		"TODO_UUID="+rem.Todo.Uuid,
+		"TODO_SUBJECT="+rem.Todo.Subject,
+		"TODO_EVENT="+rem.Event,
+		"TODO_AT="+timeToString(rem.At))
+	cmd.Stdout = d.Out
+	cmd.Stderr = d.Out
+	return cmd.Run()
+}
@@ -0,0 +1,115 @@
+package todolist
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLeadTimes(t *testing.T) {
+	assert := assert.New(t)
+	leads, err := ParseLeadTimes("15m, 1h,2d,1w,0")
+	assert.Nil(err)
+	assert.Equal([]time.Duration{15 * time.Minute, time.Hour, 48 * time.Hour, 7 * 24 * time.Hour, 0}, leads)
+	assert.Equal("15m, 1h, 2d, 1w, 0", remindLeadsString(leads))
+	_, err = ParseLeadTimes("1y")
+	assert.NotNil(err)
+}
+
+func TestRemindersDue(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	todos := []*Todo{
+		&Todo{Id: 1, Uuid: "u1", Subject: "Pay rent", Due: "2026-10-14T12:30:00Z", Status: "Pending"},
+		&Todo{Id: 2, Uuid: "u2", Subject: "Later", Due: "2026-10-16T00:00:00Z", Status: "Pending"},
+		&Todo{Id: 3, Uuid: "u3", Subject: "Done", Due: "2026-10-14T12:30:00Z", Completed: true, Status: "Completed"},
+		&Todo{Id: 4, Uuid: "u4", Subject: "Start report", Scheduled: "2026-10-14T13:00:00Z", Status: "Pending"},
+	}
+	r := NewReminders([]time.Duration{time.Hour, 0}, []string{AgendaDue, AgendaScheduled}, time.Minute)
+	due := r.Due(todos, now)
+	assert.Equal(2, len(due))
+	assert.Equal(todos[0], due[0].Todo)
+	assert.Equal(time.Hour, due[0].Lead)
+	assert.Equal("Todo 1 is due in 30m (Wed Oct 14 12:30): Pay rent", due[0].Message(now))
+	assert.Equal(todos[3], due[1].Todo)
+	assert.Equal(AgendaScheduled, due[1].Event)
+
+	//Fired reminders don't fire again. The reminder when todo 1 is due is next.
+	for _, rem := range due {
+		r.MarkFired(rem, now)
+	}
+	assert.Equal(0, len(r.Due(todos, now)))
+	assert.Equal(time.Date(2026, 10, 14, 12, 30, 0, 0, time.UTC), r.Next(todos, now))
+	assert.Equal(1, len(r.Due(todos, now.Add(30*time.Minute))))
+
+	//Only the shortest of the lead times passed fires
+	r = NewReminders([]time.Duration{24 * time.Hour, time.Hour}, []string{AgendaDue}, time.Minute)
+	due = r.Due(todos, now)
+	assert.Equal(1, len(due))
+	assert.Equal(time.Hour, due[0].Lead)
+	r.MarkFired(due[0], now)
+	assert.Equal(0, len(r.Due(todos, now)))
+
+	//Moving the due date makes a new reminder
+	todos[0].Due = "2026-10-14T12:45:00Z"
+	assert.Equal(1, len(r.Due(todos, now)))
+}
+
+func TestReminderDaemon(t *testing.T) {
+	assert := assert.New(t)
+	dir, _ := ioutil.TempDir("", "remind")
+	defer os.RemoveAll(dir)
+	//Stand in for notify-send that records its arguments and environment
+	log := filepath.Join(dir, "notified.log")
+	script := filepath.Join(dir, "notify.sh")
+	ioutil.WriteFile(script, []byte("#!/bin/sh\necho \"$TODO_ID $TODO_EVENT $1 $2\" >> "+log+"\n"), 0755)
+
+	now := time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC)
+	todos := []*Todo{&Todo{Id: 7, Uuid: "u7", Subject: "Call Bob", Due: "2026-10-14T12:00:00Z", Status: "Pending"}}
+	newDaemon := func() *ReminderDaemon {
+		r := NewReminders([]time.Duration{time.Hour}, []string{AgendaDue}, time.Minute)
+		r.LoadFired(filepath.Join(dir, "fired.json"))
+		return &ReminderDaemon{
+			Reminders: r,
+			Cmd:       script + " Todo",
+			FiredFile: filepath.Join(dir, "fired.json"),
+			Load:      func() []*Todo { return todos },
+			Clock:     testClock{&now},
+			Sleep:     func(d time.Duration) { now = now.Add(d) },
+			Out:       &bytes.Buffer{},
+		}
+	}
+	daemon := newDaemon()
+	assert.Equal(0, daemon.Check())
+	now = now.Add(time.Hour)
+	assert.Equal(1, daemon.Check())
+	assert.Equal(0, daemon.Check())
+
+	//A restart remembers the fired reminder
+	assert.Equal(0, newDaemon().Check())
+	data, _ := ioutil.ReadFile(log)
+	assert.Equal("7 due Todo Todo 7 is due in 1h00m (Wed Oct 14 12:00): Call Bob\n", string(data))
+
+	//Without a command, reminders are printed
+	now = time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC)
+	os.Remove(filepath.Join(dir, "fired.json"))
+	daemon = newDaemon()
+	daemon.Cmd = ""
+	now = now.Add(time.Hour)
+	daemon.Check()
+	assert.Contains(daemon.Out.(*bytes.Buffer).String(), "Todo 7 is due in 1h00m")
+}
+
+//Clock following a time the test advances
+type testClock struct {
+	now *time.Time
+}
+
+func (c testClock) Now() time.Time {
+	return *c.now
+}
@@ -0,0 +1,199 @@
+package todolist
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+//The --repo= option, a repo name (repo.<name>.path in ~/.todorc) or a directory. Overrides $TODO_REPO.
+var RepoOption string
+
+//The directory of the repo in use, once the config is loaded
+var repoDir string
+
+//A todo repo, a directory with a .todos.json. Name is empty for a repo not named in .todorc.
+type Repo struct {
+	Name string
+	Dir  string
+}
+
+//Remove the --repo= arg, returning the rest and its value
+func SplitRepoOption(args []string) (rest []string, repo string) {
+	rest = []string{}
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--repo=") {
+			repo = arg[len("--repo="):]
+		} else {
+			rest = append(rest, arg)
+		}
+	}
+	return rest, repo
+}
+
+//The repo directory: --repo= or $TODO_REPO, as a name in repos or a directory, else the nearest directory up from
+//the current one with a .todos.json, as git finds .git, else the home directory. ErrRepoNotFound if the override
+//is neither a name nor a directory.
+func FindRepo(repos map[string]string) (string, error) {
+	name := RepoOption
+	if name == "" {
+		name = os.Getenv("TODO_REPO")
+	}
+	if name != "" {
+		dir := expandHome(name)
+		if path, ok := repos[name]; ok {
+			dir = expandHome(path)
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return "", newError(ErrRepoNotFound, "No repo named %s in ~/.todorc, and no directory %s", name, dir)
+		}
+		return dir, nil
+	}
+	if dir, found := findRepoUp(); found {
+		return dir, nil
+	}
+	usr, _ := user.Current()
+	return usr.HomeDir, nil
+}
+
+//The nearest directory up from the current one with a .todos.json, relative to the current one
+func findRepoUp() (string, bool) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", false
+	}
+	rel := "."
+	for dir := wd; ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, ".todos.json")); err == nil {
+			return rel, true
+		}
+		if dir == filepath.Dir(dir) {
+			return "", false
+		}
+		if rel == "." {
+			rel = ".."
+		} else {
+			rel = filepath.Join(rel, "..")
+		}
+	}
+}
+
+//The repo directory, as found when the config was loaded. Before that, found without the named repos.
+func currentRepoDir() string {
+	if repoDir != "" {
+		return repoDir
+	}
+	dir, _ := FindRepo(nil)
+	return dir
+}
+
+//The named repos, sorted by name, then the current repo if it has no name
+func (a *App) Repos() []Repo {
+	repos := []Repo{}
+	current := Repo{Dir: currentRepoDir()}
+	for name, dir := range a.Cfg.Repos {
+		dir = expandHome(dir)
+		repos = append(repos, Repo{Name: name, Dir: dir})
+		if absPath(dir) == absPath(current.Dir) {
+			current.Name = name
+		}
+	}
+	sort.Slice(repos, func(i, j int) bool {
+		return repos[i].Name < repos[j].Name
+	})
+	if current.Name == "" {
+		repos = append(repos, current)
+	}
+	return repos
+}
+
+//Whether the repo is the one in use
+func (r Repo) IsCurrent() bool {
+	return absPath(r.Dir) == absPath(currentRepoDir())
+}
+
+//The repos for a repo:<name>,<name> filter, or every repo for repo:all. ErrRepoNotFound for an unknown name.
+func (a *App) selectRepos(value string) ([]Repo, error) {
+	repos := a.Repos()
+	if value == "all" {
+		return repos, nil
+	}
+	selected := []Repo{}
+	for _, name := range strings.Split(value, ",") {
+		found := false
+		for _, repo := range repos {
+			if repo.Name == name {
+				selected = append(selected, repo)
+				found = true
+			}
+		}
+		if !found {
+			return nil, newError(ErrRepoNotFound, "No repo named %s. Name repos with repo.<name>.path in .todorc", name)
+		}
+	}
+	return selected, nil
+}
+
+//The repo:<value> filter, if any, and the other filters
+func splitRepoFilter(filters []string) (string, []string) {
+	value := ""
+	rest := []string{}
+	for _, filter := range filters {
+		if strings.HasPrefix(filter, "repo:") {
+			value = filter[len("repo:"):]
+		} else {
+			rest = append(rest, filter)
+		}
+	}
+	return value, rest
+}
+
+//Run the report in each repo in turn, under a line naming it
+func (a *App) execReportInRepos(c *ReportCmd, repos []Repo) error {
+	store, list := a.TodoStore, a.TodoList
+	defer func() {
+		a.TodoStore, a.TodoList = store, list
+	}()
+	for i, repo := range repos {
+		if i > 0 {
+			fmt.Println()
+		}
+		name := repo.Name
+		if name == "" {
+			name = "(unnamed)"
+		}
+		fmt.Printf("%s: %s\n", name, repo.Dir)
+		a.TodoStore = &FileStore{
+			PendingFileLocation:  filepath.Join(repo.Dir, ".todos.json"),
+			ArchivedFileLocation: filepath.Join(repo.Dir, ".todos_archive.json"),
+			BacklogFileLocation:  filepath.Join(repo.Dir, ".todos_backlog.json"),
+		}
+		a.TodoList = NewTodoList(list.Clock)
+		//Each repo's own hooks, unless hooks.dir names the hooks for every repo
+		a.TodoList.Hooks = list.Hooks
+		if _, set := a.Cfg.Values["hooks.dir"]; !set {
+			a.TodoList.Hooks = NewHooks(repoHooksLocation(repo.Dir))
+		}
+		if err := c.Exec(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//List the named repos and the current one, marked with *
+func (a *App) ListRepos(c *CommandImpl) error {
+	rows := [][]string{}
+	for _, repo := range a.Repos() {
+		current := ""
+		if repo.IsCurrent() {
+			current = "*"
+		}
+		rows = append(rows, []string{current, repo.Name, repo.Dir})
+	}
+	NewScreenPrinter(a.Clock, a.Cfg.Colors).PrintRepos(rows)
+	return nil
+}
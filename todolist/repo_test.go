@@ -0,0 +1,82 @@
+package todolist
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindRepo(t *testing.T) {
+	assert := assert.New(t)
+	dir, _ := ioutil.TempDir("", "repo")
+	defer os.RemoveAll(dir)
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	defer func() {
+		repoDir, RepoOption = "", ""
+	}()
+	repoDir = ""
+	ioutil.WriteFile(filepath.Join(dir, ".todos.json"), []byte("[]"), 0644)
+	os.MkdirAll(filepath.Join(dir, "src", "pkg"), 0755)
+	os.MkdirAll(filepath.Join(dir, "work"), 0755)
+
+	//The nearest repo up from a subdirectory
+	os.Chdir(filepath.Join(dir, "src", "pkg"))
+	found, err := FindRepo(nil)
+	assert.Nil(err)
+	assert.Equal(filepath.Join("..", ".."), found)
+	assert.Equal(filepath.Join("..", "..", ".todos.json"), getPendingLocation())
+
+	//TODO_REPO, by name or directory, and --repo= over it
+	repos := map[string]string{"work": filepath.Join(dir, "work")}
+	os.Setenv("TODO_REPO", "work")
+	defer os.Unsetenv("TODO_REPO")
+	found, _ = FindRepo(repos)
+	assert.Equal(filepath.Join(dir, "work"), found)
+	RepoOption = dir
+	found, _ = FindRepo(repos)
+	assert.Equal(dir, found)
+	RepoOption = "home"
+	_, err = FindRepo(repos)
+	assert.True(errors.Is(err, ErrRepoNotFound))
+
+	rest, repo := SplitRepoOption([]string{"--repo=work", "+Work", "list"})
+	assert.Equal([]string{"+Work", "list"}, rest)
+	assert.Equal("work", repo)
+}
+
+func TestSelectRepos(t *testing.T) {
+	assert := assert.New(t)
+	defer func() {
+		repoDir = ""
+	}()
+	repoDir = "/tmp/current"
+	app := &App{Cfg: &Config{Repos: map[string]string{"work": "/tmp/work", "home": "/tmp/home"}}}
+
+	repos, err := app.selectRepos("all")
+	assert.Nil(err)
+	assert.Equal([]Repo{{"home", "/tmp/home"}, {"work", "/tmp/work"}, {"", "/tmp/current"}}, repos)
+	assert.True(repos[2].IsCurrent())
+	repos, _ = app.selectRepos("work")
+	assert.Equal([]Repo{{"work", "/tmp/work"}}, repos)
+	_, err = app.selectRepos("play")
+	assert.True(errors.Is(err, ErrRepoNotFound))
+
+	value, filters := splitRepoFilter([]string{"+Work", "repo:all", "due:tod"})
+	assert.Equal("all", value)
+	assert.Equal([]string{"+Work", "due:tod"}, filters)
+
+	//Other commands would run in this repo, so repo: is refused
+	ran := false
+	add := &CommandImpl{Cmd: "add", Filters: []string{"repo:work"}, ExecFunc: func(c *CommandImpl) error {
+		ran = true
+		return nil
+	}}
+	err = add.Exec(app)
+	assert.True(errors.Is(err, ErrBadInput))
+	assert.False(ran)
+}
@@ -0,0 +1,42 @@
+package todolist
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduled(t *testing.T) {
+	assert := assert.New(t)
+	clock := FixedClock{Time: time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)}
+	now := clock.Now()
+	list := &TodoList{Clock: clock}
+	parser := &Parser{Clock: clock}
+	todo, _ := parser.ParseNewTodo([]string{"Write", "report", "sched:2026-10-15", "due:2026-10-20"}, list)
+	assert.Equal("Write report", todo.Subject)
+	assert.Equal("2026-10-15T00:00:00Z", todo.Scheduled)
+	parser.ParseInput([]string{"scheduled:"}, todo, list)
+	assert.Equal("", todo.Scheduled)
+
+	todos := []*Todo{
+		&Todo{Id: 1, Subject: "Reached", Scheduled: "2026-10-13T00:00:00Z"},
+		&Todo{Id: 2, Subject: "Later", Scheduled: "2026-10-16T00:00:00Z"},
+		&Todo{Id: 3, Subject: "Waiting", Scheduled: "2026-10-13T00:00:00Z", Wait: "2026-10-15T00:00:00Z"},
+		&Todo{Id: 4, Subject: "Unscheduled"},
+	}
+	assert.True(todos[0].HasVirtualTag("READY", todos, now))
+	assert.False(todos[1].HasVirtualTag("READY", todos, now))
+	assert.False(todos[2].HasVirtualTag("READY", todos, now))
+	assert.False(todos[3].HasVirtualTag("READY", todos, now))
+
+	filtered, rest, _ := NewDateFilter(todos, clock).FilterScheduledDate([]string{"sched:today:2d", "+Work"})
+	assert.Equal([]*Todo{todos[1]}, filtered)
+	assert.Equal([]string{"+Work"}, rest)
+	filtered, _, _ = NewDateFilter(todos, clock).FilterScheduledDate([]string{"scheduled:none"})
+	assert.Equal([]*Todo{todos[3]}, filtered)
+
+	NewTodoSorter("-scheduled", "id").Sort(todos, now)
+	assert.Equal(2, todos[0].Id)
+	assert.Equal(4, todos[3].Id)
+}
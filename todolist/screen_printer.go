@@ -2,9 +2,9 @@ package todolist
 
 import (
 	"fmt"
-	"os"
 
 	//"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"text/tabwriter"
@@ -17,17 +17,21 @@ import (
 )
 
 type ScreenPrinter struct {
-	Writer    *tabwriter.Writer
-	fgGreen   func(a ...interface{}) string
-	fgYellow  func(a ...interface{}) string
-	fgRed     func(a ...interface{}) string
-	fgWhite   func(a ...interface{}) string
-	fgBlue    func(a ...interface{}) string
-	fgMagenta func(a ...interface{}) string
-	fgCyan    func(a ...interface{}) string
+	Writer     *tabwriter.Writer
+	fgGreen    func(a ...interface{}) string
+	fgYellow   func(a ...interface{}) string
+	fgRed      func(a ...interface{}) string
+	fgWhite    func(a ...interface{}) string
+	fgBlue     func(a ...interface{}) string
+	fgMagenta  func(a ...interface{}) string
+	fgCyan     func(a ...interface{}) string
+	colors     map[string]func(a ...interface{}) string //Configured element and row colors
+	precedence []string                               //Order row color rules are tried in
+	Clock      Clock                                  //Due dates, ages and urgency are shown relative to now on this clock
 }
 
-func NewScreenPrinter() *ScreenPrinter {
+//The colors are those configured with color.<element or rule>=<spec> (see Config.Colors)
+func NewScreenPrinter(clock Clock, colors map[string]string) *ScreenPrinter {
 	w := new(tabwriter.Writer)
 	w.Init(color.Output, 0, 8, 1, ' ', 0) //Changed from os.Stdout to color.Output when compiled on Windows.
 	//w.Init(color.Output, 5, 0, 1, ' ', tabwriter.StripEscape)
@@ -38,14 +42,15 @@ func NewScreenPrinter() *ScreenPrinter {
 	blue := color.New(color.FgBlue).Add(color.Bold).SprintFunc()
 	magenta := color.New(color.FgMagenta).Add(color.Bold).SprintFunc()
 	cyan := color.New(color.FgCyan).Add(color.Bold).SprintFunc()
-	formatter := &ScreenPrinter{Writer: w, fgGreen: green, fgYellow: yellow, fgRed: red, fgWhite: white, fgBlue: blue, fgMagenta: magenta, fgCyan: cyan}
+	formatter := &ScreenPrinter{Writer: w, fgGreen: green, fgYellow: yellow, fgRed: red, fgWhite: white, fgBlue: blue, fgMagenta: magenta, fgCyan: cyan, Clock: clock}
+	formatter.applyColors(colors)
 	return formatter
 }
 
 func (f *ScreenPrinter) printTodo(todo *Todo) {
 
 	fmt.Fprintf(f.Writer, " %s\t%s\t%s\t%s\t%s\t%s\t\n",
-		f.fgYellow(strconv.Itoa(todo.Id)),
+		f.color("id")(strconv.Itoa(todo.Id)),
 		f.formatCompleted(todo.Completed),
 		f.formatDue(todo.Due),
 		f.formatContexts(todo.Contexts),
@@ -53,15 +58,17 @@ func (f *ScreenPrinter) printTodo(todo *Todo) {
 		f.formatSubject(todo.Subject))
 }
 
-func (f *ScreenPrinter) PrintSetCounts(set string, m map[string]int) {
+func (f *ScreenPrinter) PrintSetCounts(set string, m map[string]int) error {
 	setColor := f.fgBlue
-	valColor := f.fgYellow
+	valColor := f.color("number")
 	if set == "Projects" {
-		setColor = f.fgMagenta
+		setColor = f.color("project")
 	} else if set == "Contexts" {
-		setColor = f.fgRed
+		setColor = f.color("context")
+	} else if set == "Tags" {
+		setColor = f.color("tag")
 	}
-	set = f.fgGreen(set)
+	set = f.color("header")(set)
 	fmt.Fprintf(f.Writer, "%s:\n", set)
 	for k, v := range m {
 		k = setColor(k)
@@ -69,11 +76,12 @@ func (f *ScreenPrinter) PrintSetCounts(set string, m map[string]int) {
 		fmt.Fprintf(f.Writer, " %s\t%s\n", k, val)
 	}
 	f.Writer.Flush()
+	return nil
 }
 
-func (f *ScreenPrinter) PrintTodoDetail(todos []*Todo) {
-	key := f.fgGreen
-	val := f.fgYellow
+func (f *ScreenPrinter) PrintTodoDetail(todos []*Todo) error {
+	key := f.color("header")
+	val := f.color("number")
 
 	for i, todo := range todos {
 		if i > 0 {
@@ -84,6 +92,7 @@ func (f *ScreenPrinter) PrintTodoDetail(todos []*Todo) {
 		fmt.Fprintf(f.Writer, " %s\t%s\n", key("Subject:"), val(todo.Subject))
 		fmt.Fprintf(f.Writer, " %s\t%s\n", key("Contexts:"), val(strings.Join(todo.Contexts, ",")))
 		fmt.Fprintf(f.Writer, " %s\t%s\n", key("Projects:"), val(strings.Join(todo.Projects, ",")))
+		fmt.Fprintf(f.Writer, " %s\t%s\n", key("Tags:"), val(strings.Join(todo.Tags, ",")))
 		fmt.Fprintf(f.Writer, " %s\t%s\n", key("Due:"), val(todo.Due))
 		fmt.Fprintf(f.Writer, " %s\t%s\n", key("Priority:"), val(todo.Priority))
 		fmt.Fprintf(f.Writer, " %s\t%s\n", key("EffortDays:"), val(fmt.Sprint(todo.EffortDays)))
@@ -95,7 +104,12 @@ func (f *ScreenPrinter) PrintTodoDetail(todos []*Todo) {
 		fmt.Fprintf(f.Writer, " %s\t%s\n", key("ModifiedDate:"), val(todo.ModifiedDate))
 		fmt.Fprintf(f.Writer, " %s\t%s\n", key("CompletedDate:"), val(todo.CompletedDate))
 		fmt.Fprintf(f.Writer, " %s\t%s\n", key("Until:"), val(todo.Until))
+		fmt.Fprintf(f.Writer, " %s\t%s\n", key("Scheduled:"), val(todo.Scheduled))
 		fmt.Fprintf(f.Writer, " %s\t%s\n", key("Wait:"), val(todo.Wait))
+		fmt.Fprintf(f.Writer, " %s\t%s\n", key("Start:"), val(todo.Start))
+		fmt.Fprintf(f.Writer, " %s\t%s\n", key("Spent:"), val(fmt.Sprintf("%s (%d intervals)", durationToString(todo.TimeSpent(f.Clock.Now())), len(todo.TimeIntervals()))))
+		fmt.Fprintf(f.Writer, " %s\t%s\n", key("Recur:"), val(todo.Recur))
+		fmt.Fprintf(f.Writer, " %s\t%s\n", key("Depends:"), val(strings.Join(todo.Depends, ",")))
 		notes := todo.Notes
 		if len(notes) > 0 {
 			//fmt.Fprintf(f.Writer, " %s\t%s\n", key("Notes:"), val(""))
@@ -106,6 +120,7 @@ func (f *ScreenPrinter) PrintTodoDetail(todos []*Todo) {
 
 		f.Writer.Flush()
 	}
+	return nil
 }
 
 func (f *ScreenPrinter) printNotes(notes []string) {
@@ -115,28 +130,33 @@ func (f *ScreenPrinter) printNotes(notes []string) {
 	}
 }
 
-func (f *ScreenPrinter) PrintReport(report *Report, todos []*Todo) {
+func (f *ScreenPrinter) PrintReport(report *Report, todos []*Todo) error {
 
-	report.Sorter.Sort(todos)
-	filtered := NewToDoFilter(todos).Filter(report.Filters)
+	report.Sorter.Sort(todos, f.Clock.Now())
+	filtered, err := NewToDoFilter(todos, f.Clock).Filter(report.Filters)
+	if err != nil {
+		return err
+	}
 	if len(filtered) == 0 {
 		fmt.Println("No todos matching filter criteria.")
-		return
+		return nil
 	}
 	consoleHeight := goterm.Height()
 	//consoleHeight := 5
 	rowNum := 0
 	doGroups := report.Group != ""
 	lastGroup := "none"
+	//Lay out the report to fit the terminal. The subject column is narrowed to fit and notes are printed under it.
+	layout := NewTableLayout(goterm.Width(), report.Widths, report.SubjectColumn(), report.Wrap)
 	for i, todo := range filtered {
 		if doGroups {
 			if report.Group == "project" {
 				projects := strings.Join(todo.Projects, ",")
 				if projects != lastGroup {
 					if i > 0 {
-						fmt.Fprintf(f.Writer, "%s\n", "")
+						layout.AddText("")
 					}
-					fmt.Fprintln(f.Writer, f.fgYellow("[")+f.formatProjects(todo.Projects)+f.fgYellow("]"))
+					layout.AddText(f.color("label")("[") + f.formatProjects(todo.Projects) + f.color("label")("]"))
 					lastGroup = projects
 					rowNum = 0
 				}
@@ -144,75 +164,97 @@ func (f *ScreenPrinter) PrintReport(report *Report, todos []*Todo) {
 				contexts := strings.Join(todo.Contexts, ",")
 				if contexts != lastGroup {
 					if i > 0 {
-						fmt.Fprintf(f.Writer, "%s\n", "")
+						layout.AddText("")
 					}
-					fmt.Fprintln(f.Writer, f.fgYellow("[")+f.formatContexts(todo.Contexts)+f.fgYellow("]"))
+					layout.AddText(f.color("label")("[") + f.formatContexts(todo.Contexts) + f.color("label")("]"))
 					lastGroup = contexts
 					rowNum = 0
 				}
 			}
 		}
 		if rowNum%consoleHeight == 0 {
-
 			if rowNum > 0 {
-				fmt.Fprintf(f.Writer, "%s\n", "")
-				f.Writer.Flush()
+				layout.AddText("")
 			}
-
-			f.printColumnHeaders(report.Columns, report.Headers)
+			layout.AddRow(f.columnHeaderCells(report.Columns, report.Headers)...)
 		}
 
-		f.printCustomTodo(todo, report.Columns)
+		layout.AddRow(f.customTodoCells(todo, report.Columns, todos)...)
 		if report.PrintNotes && len(todo.Notes) > 0 {
-			f.printNotes(todo.Notes)
+			for _, note := range todo.Notes {
+				layout.AddSpan("- " + note)
+			}
+		}
+		if report.Explain {
+			for _, line := range urgencyTermLines(todo, todos, f.Clock.Now(), report.Sorter) {
+				layout.AddSpan(line)
+			}
 		}
 		rowNum++
 	}
+	layout.Write(f.Writer)
 	f.Writer.Flush()
+	return nil
 }
 
 func (f *ScreenPrinter) printColumnHeaders(cols []string, headers []string) {
+	f.PrintRow(f.columnHeaderCells(cols, headers))
+}
+
+func (f *ScreenPrinter) columnHeaderCells(cols []string, headers []string) []string {
 	vals := []string{}
 	for i, col := range cols {
 		//Note the switch statement simply ensures cols are valid choices
 		switch col {
 		case "id":
-			vals = append(vals, f.fgGreen(headers[i]))
+			vals = append(vals, f.color("header")(headers[i]))
 		case "completed":
-			vals = append(vals, f.fgGreen(headers[i]))
+			vals = append(vals, f.color("header")(headers[i]))
 		case "age":
-			vals = append(vals, f.fgGreen(headers[i]))
+			vals = append(vals, f.color("header")(headers[i]))
 		case "idle":
-			vals = append(vals, f.fgGreen(headers[i]))
+			vals = append(vals, f.color("header")(headers[i]))
 		case "due":
-			vals = append(vals, f.fgGreen(headers[i]))
+			vals = append(vals, f.color("header")(headers[i]))
+		case "scheduled":
+			vals = append(vals, f.color("header")(headers[i]))
 		case "done":
-			vals = append(vals, f.fgGreen(headers[i]))
+			vals = append(vals, f.color("header")(headers[i]))
 		case "modified":
-			vals = append(vals, f.fgGreen(headers[i]))
+			vals = append(vals, f.color("header")(headers[i]))
 		case "priority":
-			vals = append(vals, f.fgGreen(headers[i]))
+			vals = append(vals, f.color("header")(headers[i]))
 		case "effort":
-			vals = append(vals, f.fgGreen(headers[i]))
+			vals = append(vals, f.color("header")(headers[i]))
 		case "exec_order":
-			vals = append(vals, f.fgGreen(headers[i]))
+			vals = append(vals, f.color("header")(headers[i]))
+		case "urgency":
+			vals = append(vals, f.color("header")(headers[i]))
+		case "spent":
+			vals = append(vals, f.color("header")(headers[i]))
+		case "pomodoros":
+			vals = append(vals, f.color("header")(headers[i]))
 		case "ord:all":
-			vals = append(vals, f.fgGreen(headers[i]))
+			vals = append(vals, f.color("header")(headers[i]))
 		case "ord:pro":
-			vals = append(vals, f.fgGreen(headers[i]))
+			vals = append(vals, f.color("header")(headers[i]))
 		case "ord:ctx":
-			vals = append(vals, f.fgGreen(headers[i]))
+			vals = append(vals, f.color("header")(headers[i]))
 		case "notes":
-			vals = append(vals, f.fgGreen(headers[i]))
+			vals = append(vals, f.color("header")(headers[i]))
 		case "context":
-			vals = append(vals, f.fgGreen(headers[i]))
+			vals = append(vals, f.color("header")(headers[i]))
 		case "project":
-			vals = append(vals, f.fgGreen(headers[i]))
+			vals = append(vals, f.color("header")(headers[i]))
+		case "tags":
+			vals = append(vals, f.color("header")(headers[i]))
+		case "vtags":
+			vals = append(vals, f.color("header")(headers[i]))
 		case "subject":
-			vals = append(vals, f.fgGreen(headers[i]))
+			vals = append(vals, f.color("header")(headers[i]))
 		}
 	}
-	f.PrintRow(vals)
+	return vals
 }
 
 func (f *ScreenPrinter) PrintRow(line []string) {
@@ -228,24 +270,32 @@ func iface(list []string) []interface{} {
 }
 
 //Print todo with specific columns, order of columns, column headings, sort order
-func (f *ScreenPrinter) printCustomTodo(todo *Todo, cols []string) {
-	//PROBLEM: tabwriter chokes on the additional formatting codes for italic and bold. Throws off column alignment. Fix later (maybe new tabwriter)
-	//if todo.IsPriority {
-	//	yellow.Add(color.Bold, color.Italic)
-	//}
+func (f *ScreenPrinter) printCustomTodo(todo *Todo, cols []string, todos []*Todo) {
+	f.PrintRow(f.customTodoCells(todo, cols, todos))
+}
+
+//Formatted (colored) value of each column for a todo
+func (f *ScreenPrinter) customTodoCells(todo *Todo, cols []string, todos []*Todo) []string {
+	//Reports are laid out by TableLayout, which ignores formatting codes when measuring columns
 	vals := []string{}
 	for _, col := range cols {
 		switch col {
 		case "id":
-			vals = append(vals, f.fgYellow(strconv.Itoa(todo.Id)))
+			vals = append(vals, f.color("id")(strconv.Itoa(todo.Id)))
 		case "completed":
-			vals = append(vals, f.formatCompleted(todo.Completed))
+			if todo.IsActive() {
+				vals = append(vals, f.color("started")("[>]")) //started with the start command
+			} else {
+				vals = append(vals, f.formatCompleted(todo.Completed))
+			}
 		case "age":
 			vals = append(vals, f.formatAge(todo.CreatedDate))
 		case "idle":
 			vals = append(vals, f.formatIdle(todo.ModifiedDate))
 		case "due":
 			vals = append(vals, f.formatDue(todo.Due))
+		case "scheduled":
+			vals = append(vals, f.formatModifiedDate(todo.Scheduled))
 		case "done":
 			vals = append(vals, f.formatModifiedDate(todo.CompletedDate))
 		case "modified":
@@ -256,6 +306,12 @@ func (f *ScreenPrinter) printCustomTodo(todo *Todo, cols []string) {
 			vals = append(vals, f.formatEffort(todo.EffortDays))
 		case "exec_order":
 			vals = append(vals, f.formatExecOrder(todo))
+		case "urgency":
+			vals = append(vals, f.formatUrgency(todo))
+		case "spent":
+			vals = append(vals, f.formatSpent(todo))
+		case "pomodoros":
+			vals = append(vals, f.formatPomodoros(todo))
 		case "ord:all":
 			vals = append(vals, f.formatOrdinal(0, todo)) //0 = all
 		case "ord:pro":
@@ -263,54 +319,61 @@ func (f *ScreenPrinter) printCustomTodo(todo *Todo, cols []string) {
 		case "ord:ctx":
 			vals = append(vals, f.formatOrdinal(2, todo)) //2 = ctx
 		case "notes":
-			vals = append(vals, f.fgYellow(strconv.Itoa(len(todo.Notes))))
+			vals = append(vals, f.color("number")(strconv.Itoa(len(todo.Notes))))
 		case "context":
 			vals = append(vals, f.formatContexts(todo.Contexts))
 		case "project":
 			vals = append(vals, f.formatProjects(todo.Projects))
+		case "tags":
+			vals = append(vals, f.formatTags(todo.Tags))
+		case "vtags":
+			vals = append(vals, f.formatTags(todo.GetVirtualTags(todos, f.Clock.Now())))
 		case "subject":
 			vals = append(vals, f.formatSubject(todo.Subject))
 		}
 	}
-	f.PrintRow(vals)
+	if rowColor := f.rowColor(todo); rowColor != nil {
+		for i, val := range vals {
+			vals[i] = rowColor(ansiRegex.ReplaceAllString(val, ""))
+		}
+	}
+	return vals
 }
 
 func (f *ScreenPrinter) formatDue(due string) string {
 
 	if due == "" {
-		return f.fgBlue(" ")
+		return f.color("due")(" ")
 	}
 	dueTime, err := time.Parse(time.RFC3339, due)
 
+	//Shown as is, rather than failing the report, if the .todos.json file is corrupt
 	if err != nil {
-		fmt.Println(err)
-		fmt.Println("This may due to the corruption of .todos.json file.")
-		os.Exit(-1)
+		return f.color("late")(due)
 	}
 
-	if isToday(dueTime) {
-		return f.fgBlue("today     ")
-	} else if isTomorrow(dueTime) {
-		return f.fgBlue("tomorrow  ")
-	} else if isPastDue(dueTime) {
-		return f.fgRed(dueTime.Format("Mon Jan 02"))
+	now := f.Clock.Now()
+	if isToday(dueTime, now) {
+		return f.color("due")("today     ")
+	} else if isTomorrow(dueTime, now) {
+		return f.color("due")("tomorrow  ")
+	} else if isPastDue(dueTime, now) {
+		return f.color("late")(dueTime.Format("Mon Jan 02"))
 	} else {
-		return f.fgBlue(dueTime.Format("Mon Jan 02"))
+		return f.color("due")(dueTime.Format("Mon Jan 02"))
 	}
 }
 
 func (f *ScreenPrinter) formatModifiedDate(date string) string {
 	if date == "" {
-		return f.fgBlue(" ")
+		return f.color("date")(" ")
 	}
 	dateTime, err := time.Parse(time.RFC3339, date)
 
 	if err != nil {
-		fmt.Println(err)
-		fmt.Println("This may due to the corruption of .todos.json file.")
-		os.Exit(-1)
+		return f.color("date")(date)
 	}
-	return f.fgYellow(dateTime.Format("Mon Jan 02"))
+	return f.color("date")(dateTime.Format("Mon Jan 02"))
 }
 
 func (f *ScreenPrinter) formatProjects(projects []string) string {
@@ -320,7 +383,7 @@ func (f *ScreenPrinter) formatProjects(projects []string) string {
 	for _, word := range projects {
 		words = append(words, word)
 	}
-	coloredWords := f.fgMagenta(strings.Join(words, ", "))
+	coloredWords := f.color("project")(strings.Join(words, ", "))
 	return coloredWords
 }
 
@@ -329,16 +392,20 @@ func (f *ScreenPrinter) formatContexts(contexts []string) string {
 	for _, word := range contexts {
 		words = append(words, word)
 	}
-	coloredWords := f.fgRed(strings.Join(words, ", "))
+	coloredWords := f.color("context")(strings.Join(words, ", "))
 	return coloredWords
 }
 
+func (f *ScreenPrinter) formatTags(tags []string) string {
+	return f.color("tag")(strings.Join(tags, ", "))
+}
+
 func (f *ScreenPrinter) formatSubject(subject string) string {
-	return f.fgWhite(subject)
+	return f.color("subject")(subject)
 }
 
 func (f *ScreenPrinter) formatPriority(p string) string {
-	return f.fgRed(p)
+	return f.color("priority")(p)
 }
 
 func (f *ScreenPrinter) formatEffort(cnt float64) string {
@@ -348,66 +415,76 @@ func (f *ScreenPrinter) formatEffort(cnt float64) string {
 	} else {
 		val = fmt.Sprintf("%d", int(cnt))
 	}
-	coloredWords := f.fgYellow(val, "d")
+	coloredWords := f.color("number")(val, "d")
 	return coloredWords
 }
 
 func (f *ScreenPrinter) formatOrdinal(ordType int, todo *Todo) string {
 	switch ordType {
 	case 0:
-		return f.fgYellow(todo.Ordinals["all"])
+		return f.color("number")(todo.Ordinals["all"])
 	case 1:
 		if len(todo.Projects) > 0 {
-			return f.fgYellow(todo.Ordinals["+"+todo.Projects[0]])
+			return f.color("number")(todo.Ordinals["+"+todo.Projects[0]])
 		}
 	case 2:
 		if len(todo.Contexts) > 0 {
-			return f.fgYellow(todo.Ordinals["@"+todo.Contexts[0]])
+			return f.color("number")(todo.Ordinals["@"+todo.Contexts[0]])
 		}
 	}
-	return f.fgYellow(" ")
+	return f.color("number")(" ")
 }
 
 func (f *ScreenPrinter) formatAge(createdDate string) string {
-	days := 0
-	if len(createdDate) > 0 {
-		tmpTime, err := time.Parse(time.RFC3339, createdDate)
-		if err == nil {
-			createTime := tmpTime.Unix()
-			now := Now.Unix()
-			diff := now - createTime
-			days = (int)(diff / (60 * 60 * 24))
-		}
-	}
-	coloredWords := f.fgYellow(days, "d")
+	coloredWords := f.color("number")(daysSince(createdDate, f.Clock.Now()), "d")
 	return coloredWords
 }
 
 func (f *ScreenPrinter) formatExecOrder(t *Todo) string {
-	coloredWords := f.fgYellow(fmt.Sprintf("%.3f", t.ExecOrder))
+	coloredWords := f.color("number")(fmt.Sprintf("%.3f", t.ExecOrder))
 	return coloredWords
 }
 
-func (f *ScreenPrinter) formatIdle(modifiedDate string) string {
-	days := 0
-	if len(modifiedDate) > 0 {
-		tmpTime, err := time.Parse(time.RFC3339, modifiedDate)
-		if err == nil {
-			modifiedTime := tmpTime.Unix()
-			now := Now.Unix()
-			diff := now - modifiedTime
-			days = (int)(diff / (60 * 60 * 24))
-		}
+func (f *ScreenPrinter) formatSpent(t *Todo) string {
+	if t.IsActive() {
+		return f.color("started")(durationToString(t.TimeSpent(f.Clock.Now())))
+	}
+	return f.color("spent")(durationToString(t.TimeSpent(f.Clock.Now())))
+}
+
+func (f *ScreenPrinter) formatPomodoros(t *Todo) string {
+	if t.Pomodoros() == 0 {
+		return ""
 	}
-	coloredWords := f.fgYellow(days, "d")
+	return f.color("pomodoro")(strconv.Itoa(t.Pomodoros()))
+}
+
+func (f *ScreenPrinter) formatUrgency(t *Todo) string {
+	return f.color("number")(fmt.Sprintf("%.2f", t.Urgency))
+}
+
+//Lines listing the terms making up the urgency score for a todo, as ranked by the sorter. Helps tune the urgency coefficients.
+func urgencyTermLines(todo *Todo, todos []*Todo, now time.Time, sorter *TodoSorter) []string {
+	lines := []string{"Urgency:"}
+	total := 0.0
+	for _, term := range urgencyTerms(todo, todos, now, sorter.priority(), sorter.coefficients()) {
+		total += term.Score()
+		lines = append(lines, fmt.Sprintf("  %-16s %6.3f * %6.2f = %6.2f", term.Name, term.Value, term.Coefficient, term.Score()))
+	}
+	lines = append(lines, fmt.Sprintf("  %-16s %24.2f", "total", total))
+	return lines
+}
+
+func (f *ScreenPrinter) formatIdle(modifiedDate string) string {
+	coloredWords := f.color("number")(daysSince(modifiedDate, f.Clock.Now()), "d")
 	return coloredWords
 }
 
 func (f *ScreenPrinter) formatCompleted(completed bool) string {
 	if completed {
-		return f.fgWhite("[x]")
+		return f.color("status")("[x]")
 	} else {
-		return f.fgWhite("[ ]")
+		return f.color("status")("[ ]")
 	}
 }
 
@@ -421,20 +498,9 @@ func (f *ScreenPrinter) formatCompleted(completed bool) string {
 	If sum and by:pro/ctx then group stats and row per day/week/month
 	If no sum (or sum:all) then no grouping and row per pro/ctx
 */
-func (f *ScreenPrinter) PrintStats(filtered []*Todo, groupBy string, sumBy string, cols []string, chart bool, rangeTimes []time.Time) {
-	var sum int
-	var sumString string
-	if strings.HasPrefix(strings.ToLower(sumBy), "w") {
-		sum = 1
-		sumString = "Week"
-	} else if strings.HasPrefix(strings.ToLower(sumBy), "m") {
-		sum = 2
-		sumString = "Month"
-	} else {
-		sum = 0
-		sumString = "Day"
-	}
-	statsData := &StatsData{Groups: map[string]*StatsGroup{}}
+func (f *ScreenPrinter) PrintStats(filtered []*Todo, groupBy string, sumBy string, cols []string, chart bool, rangeTimes []time.Time) error {
+	sum, sumString := parseSumBy(sumBy)
+	statsData := NewStatsData(f.Clock)
 	statsData.CalcStats(filtered, groupBy, sum, rangeTimes)
 
 	consoleHeight := goterm.Height()
@@ -443,7 +509,9 @@ func (f *ScreenPrinter) PrintStats(filtered []*Todo, groupBy string, sumBy strin
 	groupedStats := statsData.GetSortedGroups()
 
 	if chart {
-		f.printStatChart(groupedStats, sumString)
+		if err := f.printStatChart(groupedStats, sumString); err != nil {
+			return err
+		}
 	}
 
 	for _, sg := range groupedStats {
@@ -465,6 +533,175 @@ func (f *ScreenPrinter) PrintStats(filtered []*Todo, groupBy string, sumBy strin
 		}
 	}
 	f.Writer.Flush()
+	return nil
+}
+
+//Print time spent as a matrix of groups (rows) by periods (columns) with totals
+func (f *ScreenPrinter) PrintTimesheet(ts *Timesheet, groupBy string) {
+	if len(ts.Groups) == 0 {
+		fmt.Println("No time recorded for todos matching filter criteria. Use start and stop to record time.")
+		return
+	}
+	groupHeader := "Project"
+	if strings.HasPrefix(strings.ToLower(groupBy), "c") {
+		groupHeader = "Context"
+	} else if strings.HasPrefix(strings.ToLower(groupBy), "a") {
+		groupHeader = "Group"
+	}
+	vals := []string{f.fgGreen(groupHeader)}
+	for _, p := range ts.Periods {
+		vals = append(vals, f.fgGreen(ts.PeriodLabel(p)))
+	}
+	vals = append(vals, f.fgGreen("Total"))
+	f.PrintRow(vals)
+
+	for _, group := range ts.Groups {
+		label := group
+		if label == "" {
+			label = "(none)"
+		}
+		vals = []string{f.fgYellow(label)}
+		for _, p := range ts.Periods {
+			vals = append(vals, f.fgWhite(durationToString(ts.Spent[group][p])))
+		}
+		vals = append(vals, f.fgCyan(durationToString(ts.GroupTotal(group))))
+		f.PrintRow(vals)
+	}
+
+	vals = []string{f.fgGreen("Total")}
+	for _, p := range ts.Periods {
+		vals = append(vals, f.fgCyan(durationToString(ts.PeriodTotal(p))))
+	}
+	vals = append(vals, f.fgCyan(durationToString(ts.Total())))
+	f.PrintRow(vals)
+	f.Writer.Flush()
+}
+
+//Print each day of the agenda with its todos, after the overdue todos carried forward
+func (f *ScreenPrinter) PrintAgenda(agenda *Agenda) {
+	layout := NewTableLayout(goterm.Width(), nil, 3, false)
+	if len(agenda.Overdue) > 0 {
+		layout.AddText(f.color("late")("Overdue"))
+		for _, todo := range agenda.Overdue {
+			layout.AddRow(" "+f.color("id")(strconv.Itoa(todo.Id)), f.formatCompleted(todo.Completed),
+				f.color("late")("due "+stringToTime(todo.Due).Format("Mon Jan 02")), f.formatSubject(todo.Subject))
+		}
+		layout.AddText("")
+	}
+	for i, day := range agenda.Days() {
+		if i > 0 {
+			layout.AddText("")
+		}
+		heading := day.Format("Mon Jan 02")
+		if isToday(day, agenda.Now) {
+			heading += " (today)"
+		}
+		layout.AddText(f.color("label")(heading))
+		for _, item := range agenda.ItemsOn(day) {
+			layout.AddRow(" "+f.color("id")(strconv.Itoa(item.Todo.Id)), f.formatCompleted(item.Todo.Completed),
+				f.agendaEventColor(item.Event)(item.Event), f.formatSubject(item.Todo.Subject))
+		}
+	}
+	layout.Write(f.Writer)
+	f.Writer.Flush()
+}
+
+func (f *ScreenPrinter) agendaEventColor(event string) func(a ...interface{}) string {
+	switch event {
+	case AgendaDue:
+		return f.color("due")
+	case AgendaExpires:
+		return f.color("late")
+	case AgendaDone:
+		return f.color("status")
+	}
+	return f.color("date")
+}
+
+//Width of a month on the calendar: 7 days of 2 digits and a space between
+const calendarMonthWidth = 20
+
+//Print months side by side, as many as fit (up to 3), with days colored by the todos due. With agenda, list the
+//todos due each day beneath.
+func (f *ScreenPrinter) PrintCalendar(cal *Calendar, agenda bool) {
+	perRow := 3
+	if w := goterm.Width(); w > 0 && w < 3*calendarMonthWidth+4 {
+		perRow = (w + 2) / (calendarMonthWidth + 2)
+		if perRow < 1 {
+			perRow = 1
+		}
+	}
+	for i := 0; i < cal.Months; i += perRow {
+		if i > 0 {
+			fmt.Fprintln(f.Writer, "")
+		}
+		months := [][]string{}
+		for j := i; j < i+perRow && j < cal.Months; j++ {
+			months = append(months, f.calendarMonthLines(cal, cal.Begin.AddDate(0, j, 0)))
+		}
+		for row := range months[0] {
+			line := ""
+			for j, month := range months {
+				if j > 0 {
+					line += strings.Repeat(" ", calendarMonthWidth-VisibleWidth(months[j-1][row])+2)
+				}
+				line += month[row]
+			}
+			fmt.Fprintln(f.Writer, strings.TrimRight(line, " "))
+		}
+	}
+	fmt.Fprintln(f.Writer, "")
+	fmt.Fprintln(f.Writer, f.color("calendar.overdue")("overdue")+"  "+f.color("calendar.urgent")("urgent")+"  "+
+		f.color("calendar.due")("due")+"  "+f.color("calendar.today")("today"))
+
+	if agenda {
+		layout := NewTableLayout(goterm.Width(), nil, 2, false)
+		for _, day := range cal.DaysDue() {
+			layout.AddText("")
+			layout.AddText(f.calendarStateColor(cal.DayState(day))(day.Format("Mon Jan 02")))
+			for _, todo := range cal.TodosOn(day) {
+				layout.AddRow(" "+f.color("id")(strconv.Itoa(todo.Id)), f.formatCompleted(todo.Completed), f.formatSubject(todo.Subject))
+			}
+		}
+		layout.Write(f.Writer)
+	}
+	f.Writer.Flush()
+}
+
+//Title, day names and 6 weeks of a month. Always 6 weeks, so months side by side line up.
+func (f *ScreenPrinter) calendarMonthLines(cal *Calendar, month time.Time) []string {
+	title := month.Format("January 2006")
+	pad := (calendarMonthWidth - len(title)) / 2
+	lines := []string{strings.Repeat(" ", pad) + f.color("label")(title), f.color("header")("Su Mo Tu We Th Fr Sa")}
+	day := bow(month)
+	for week := 0; week < 6; week++ {
+		cells := []string{}
+		for i := 0; i < 7; i++ {
+			cell := "  "
+			if day.Month() == month.Month() {
+				cell = f.calendarStateColor(cal.DayState(day))(fmt.Sprintf("%2d", day.Day()))
+				if isToday(day, cal.Now) {
+					cell = f.color("calendar.today")(cell)
+				}
+			}
+			cells = append(cells, cell)
+			day = day.AddDate(0, 0, 1)
+		}
+		lines = append(lines, strings.Join(cells, " "))
+	}
+	return lines
+}
+
+func (f *ScreenPrinter) calendarStateColor(state int) func(a ...interface{}) string {
+	switch state {
+	case DayOverdue:
+		return f.color("calendar.overdue")
+	case DayUrgent:
+		return f.color("calendar.urgent")
+	case DayDue:
+		return f.color("calendar.due")
+	}
+	return fmt.Sprint
 }
 
 //Print todo with specific columns, order of columns, column headings, sort order
@@ -499,6 +736,10 @@ func (f *ScreenPrinter) printTodoStat(stat *TodoStat, cols []string, sum string)
 			vals = append(vals, f.fgBlue(strconv.Itoa(stat.Completed)))
 		case "ar":
 			vals = append(vals, f.fgMagenta(strconv.Itoa(stat.Archived)))
+		case "ts":
+			vals = append(vals, f.fgGreen(durationToString(stat.Spent)))
+		case "po":
+			vals = append(vals, f.fgRed(strconv.Itoa(stat.Pomodoros)))
 		}
 	}
 	f.PrintRow(vals)
@@ -521,15 +762,19 @@ func (f *ScreenPrinter) printStatsColumnHeaders(cols []string) {
 			vals = append(vals, f.fgGreen("Completed"))
 		case "ar":
 			vals = append(vals, f.fgGreen("Archived"))
+		case "ts":
+			vals = append(vals, f.fgGreen("Spent"))
+		case "po":
+			vals = append(vals, f.fgGreen("Pomodoros"))
 		}
 	}
 	f.PrintRow(vals)
 }
 
-func (s *ScreenPrinter) printStatChart(groupedStats []*StatsGroup, sumString string) {
+func (s *ScreenPrinter) printStatChart(groupedStats []*StatsGroup, sumString string) error {
 	err := ui.Init()
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("Error starting chart: %w", err)
 	}
 
 	var numPending []int
@@ -634,9 +879,11 @@ func (s *ScreenPrinter) printStatChart(groupedStats []*StatsGroup, sumString str
 		ui.Clear()
 	}
 	termbox.Close() //WithoutClear()
+	return nil
 }
 
-func (f *ScreenPrinter) PrintOverallHelp() {
+//Also lists the plugins installed, by name with their paths
+func (f *ScreenPrinter) PrintOverallHelp(plugins map[string]string) {
 
 	tmp := []string{f.fgCyan("Todolist is a simple, command line based, GTD-style todo manager")}
 	f.PrintRow(tmp)
@@ -651,15 +898,27 @@ func (f *ScreenPrinter) PrintOverallHelp() {
 	colors = []func(a ...interface{}) string{f.fgGreen, f.fgGreen}
 	f.printCols(colors, "  Command", "Description")
 	colors = []func(a ...interface{}) string{f.fgCyan, f.fgYellow}
-	f.printCols(colors, "  help", "Print this message. Pass a command, 'dates', 'filters', 'modifiers', 'args' or 'config' for more detail.")
+	f.printCols(colors, "  help", "Print this message. Pass a command, 'dates', 'filters', 'modifiers', 'args', 'aliases', 'config', 'repos', 'hooks' or 'plugins' for more detail.")
 	f.printCols(colors, "  init", "Initialize a new repository in local directory.")
 	f.printCols(colors, "  add | a", "Add a new todo.")
 	f.printCols(colors, "  done", "Add an already completed todo (for recording purposes)")
 	f.printCols(colors, "  list | l", "List todos. Listed todos can be constrained by filters (see help filters).")
+	f.printCols(colors, "  next", "List the most actionable todos, top 3 per project (see help next).")
 	f.printCols(colors, "  projects", "List all projects and count of todos for each.")
 	f.printCols(colors, "  contexts", "List all contexts and count of todos for each.")
+	f.printCols(colors, "  tags", "List all tags and count of todos for each.")
 	f.printCols(colors, "  print", "Print all todo details. Select todos by filter (see help filters).")
 	f.printCols(colors, "  edit | e", "Edit one or more todos. Todos edited are determined by filters (see help filters)")
+	f.printCols(colors, "  start", "Start tracking time on a todo. Stops the todo currently started (see help start).")
+	f.printCols(colors, "  stop", "Stop tracking time on the started todo, or the todos matching filters.")
+	f.printCols(colors, "  tui", "Browse and edit todos in a full screen list (see help tui).")
+	f.printCols(colors, "  pomo", "Work on a todo with a pomodoro timer (see help pomo).")
+	f.printCols(colors, "  timesheet | ts", "Report time spent by project and day, with totals. Export to CSV (see help timesheet).")
+	f.printCols(colors, "  edit-full | e!", "Edit todos, including notes, as a document in $EDITOR (see help e!).")
+	f.printCols(colors, "  batch", "Run commands from a file or stdin, one per line, saving once at the end (see help batch).")
+	f.printCols(colors, "  calendar | cal", "Show due dates on a month or year calendar, with an optional agenda of each day (see help calendar).")
+	f.printCols(colors, "  agenda", "List the todos due, scheduled, waiting until, expiring and done each day, today or this week, after overdue todos (see help agenda).")
+	f.printCols(colors, "  remind", "Run in the foreground, notifying of todos due, scheduled or waiting until soon, at configured lead times (see help remind).")
 	f.printCols(colors, "  touch | t", "Touch (ie. set modified date to now) one or more todos. Todos touched are determined by filters (see help filters)")
 	f.printCols(colors, "  delete | d", "Delete todos. Deleted todos can be constrained by filters (see help filters).")
 	f.printCols(colors, "  order | ord | reorder", "Order todos in a set (all|+project|@context) relative to each other using ids.")
@@ -681,9 +940,27 @@ func (f *ScreenPrinter) PrintOverallHelp() {
 	f.printCols(colors, "  en", "Edit a note for one or more todos. Select todos by filter (see help filters).")
 	f.printCols(colors, "  dn", "Delete a note for one or more todos. Select todos by filter (see help filters).")
 	f.printCols(colors, "  view", "Set a view (ie. a default set of filters). A view is typically based on a context filter.")
+	f.printCols(colors, "  config", "Get, set, unset, list and check .todorc settings (see help config).")
+	f.printCols(colors, "  repos", "List the repos named in .todorc and the one in use (see help repos).")
 	f.printCols(colors, "  gc", "Garbage collect (permanently delete) all archived todos.")
 	f.Writer.Flush()
 
+	if len(plugins) > 0 {
+		f.println(f.fgGreen, "")
+		colors = []func(a ...interface{}) string{f.fgGreen, f.fgGreen}
+		f.printCols(colors, "  Plugin", "Path")
+		colors = []func(a ...interface{}) string{f.fgCyan, f.fgYellow}
+		names := []string{}
+		for name := range plugins {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			f.printCols(colors, "  "+name, plugins[name])
+		}
+		f.Writer.Flush()
+	}
+
 	f.println(f.fgGreen, "")
 	f.println(f.fgGreen, "  For full documentation, please visit http://github.com/fkmiec/todolist")
 	f.Writer.Flush()
@@ -709,12 +986,15 @@ func (f *ScreenPrinter) PrintDatesHelp() {
 	colors := []func(a ...interface{}) string{f.fgCyan, f.fgYellow}
 	f.println(f.fgGreen, "")
 	f.println(f.fgGreen, "  Date specifiers used in filters and modifiers:")
-	f.printCols(colors, "    tod(ay)|tom(orrow)|yes(terday)|this_week|next_week|last_week", "Relative date.")
+	f.printCols(colors, "    tod(ay)|tom(orrow)|yes(terday)|this_week|next_week|last_week|this_month|next_month|last_month|this_year|next_year|last_year", "Relative date.")
 	f.printCols(colors, "    mon|tue|wed|thu|fri|sat|sun", "Day of the week.")
 	f.printCols(colors, "    1d|1w|1m|1y", "Date calculated using relative duration from today.")
 	f.printCols(colors, "    any", "Any date specified (e.g. filter for todos with any due date (ie. not blank)).")
 	f.printCols(colors, "    none", "No date specified (e.g. filter for todos with no due date).")
 	f.printCols(colors, "    overdue", "Past due todos.")
+	f.println(f.fgGreen, "")
+	f.println(f.fgGreen, "  Relative dates are from now, or from the time in the TODO_NOW environment variable if set:")
+	f.printCols(colors, "    TODO_NOW=2020-03-01", "See the todos as of a date, e.g. TODO_NOW=2020-03-01T09:30 todo agenda. Also 2020-03-01T09:30:00-05:00.")
 	f.Writer.Flush()
 }
 
@@ -731,17 +1011,23 @@ func (f *ScreenPrinter) PrintFiltersHelp() {
 	f.printCols(colors, "    -[project name]", "Filter for todos WITHOUT the specified project.")
 	f.printCols(colors, "    @[context name]", "Filter for todos with the specified context.")
 	f.printCols(colors, "    -@[context name]", "Filter for todos WITHOUT the specified context.")
+	f.printCols(colors, "    #[tag name]", "Filter for todos with the specified tag. Quote or escape (\\#) so the shell does not treat it as a comment.")
+	f.printCols(colors, "    -#[tag name]", "Filter for todos WITHOUT the specified tag.")
+	f.printCols(colors, "    #[OVERDUE|TODAY|WAITING|BLOCKED|ANNOTATED|RECURRING|ACTIVE|READY]", "Filter on virtual tags computed from the state of the todo. Prefix with '-' to exclude. READY is scheduled, reached and not waiting. ACTIVE is started (see help start).")
 	f.printCols(colors, "    due:[date][:end date]", "Filter for todos with due dates equal to date or within date range.")
+	f.printCols(colors, "    sched:[date][:end date]", "Filter for todos scheduled on date or within date range. sched:any and sched:none also work.")
 	f.printCols(colors, "    mod:[date][:end date]", "Filter for todos with modified dates equal to date or within date range.")
 	f.printCols(colors, "    pri:[priorities (comma-separated)]", "Filter for indicated priorities.")
 	f.printCols(colors, "    age:[number or range of days]", "Filter for todos by age (e.g. age:1 or age:1-5).")
 	f.printCols(colors, "    effort:[number or range of days]", "Filter for todos by effort (e.g. effort:1 or effort:1-5).")
 	f.printCols(colors, "    top:[pro|ctx]:[number]", "Filter for top N todos by project or context or overall, based on sort chosen.")
+	f.printCols(colors, "    top:[pro|ctx]:[number]:none", "Also keep the top N todos with no project or context, as a group of their own.")
 	f.printCols(colors, "    waiting", "Filter for todos that are waiting.")
 	f.printCols(colors, "    until", "Filter for todos that will expire.")
 	f.printCols(colors, "    completed", "Filter for todos that are completed.")
 	f.printCols(colors, "    archived", "Filter for todos that are archived.")
 	f.printCols(colors, "    notes:[true or false]", "Filter for todos with notes (or without notes if false).")
+	f.printCols(colors, "    repo:[all or names (comma-separated)]", "List or run a report in each of the named repos, or all of them (see help repos).")
 	f.printCols(colors, "    [search words]", "Filter for todos with search words in the subject. Must not match other filters above.")
 	f.Writer.Flush()
 }
@@ -758,10 +1044,15 @@ func (f *ScreenPrinter) PrintModifiersHelp() {
 	f.printCols(colors, "    -[project name]", "Remove a project.")
 	f.printCols(colors, "    @[context name]", "Add a context.")
 	f.printCols(colors, "    -@[context name]", "Remove a context.")
-	f.printCols(colors, "    due:[date]", "Add or change the due date.")
+	f.printCols(colors, "    #[tag name]", "Add a tag.")
+	f.printCols(colors, "    -#[tag name]", "Remove a tag.")
+	f.printCols(colors, "    recur:[1d|2w|1m|1y|daily|weekly|monthly|yearly]", "Set or clear (recur:none) how often the todo recurs. Todo is RECURRING while set.")
+	f.printCols(colors, "    depends:[ids (comma-separated)]", "Set or clear (depends:) todos that must be completed first. Todo is BLOCKED until then.")
+	f.printCols(colors, "    due:[date]", "Add or change the due date. Blank (due:) removes it.")
 	f.printCols(colors, "    effort:[count][h,d,w,m,y]", "Add or change the days of effort. Value may be specified as decimal hours, days, weeks, months or years. Display will always be in shown as days of effort.")
-	f.printCols(colors, "    wait:[date specifier]", "Add or change the wait date.")
-	f.printCols(colors, "    until:[date specifier]", "Add or change the until (expiry) date.")
+	f.printCols(colors, "    scheduled:[date specifier]", "Add or change (also sched:) the date you intend to start. Unlike wait, the todo stays visible; unlike due, it is not a deadline. Blank removes it.")
+	f.printCols(colors, "    wait:[date specifier]", "Add or change the wait date. Blank removes it.")
+	f.printCols(colors, "    until:[date specifier]", "Add or change the until (expiry) date. Blank removes it.")
 	f.printCols(colors, "    pri:[priority specifier]", "Add or change the priority. Configurable. Default values are H,M,L.")
 	f.Writer.Flush()
 }
@@ -774,10 +1065,16 @@ func (f *ScreenPrinter) PrintArgsHelp() {
 	colors := []func(a ...interface{}) string{f.fgCyan, f.fgYellow}
 	f.println(f.fgGreen, "")
 	f.println(f.fgGreen, "  Arguments (Generally only for list, report or stats commands):")
-	f.printCols(colors, "    sort:[+|-][id|project|context|ord:[all|pro|ctx]|due|created|modified|age|idle|priority]", "Override sort for the todo list.")
+	f.printCols(colors, "    sort:[+|-][id|project|context|ord:[all|pro|ctx]|due|scheduled|created|modified|age|idle|priority|urgency|next]", "Override sort for the todo list.")
 	f.printCols(colors, "    filter:[+|-][see filters above]", "Override filters for todo list.")
 	f.printCols(colors, "    group:[project | context]", "Group todos by project or context. Override group config for todo list.")
 	f.printCols(colors, "    notes:[true or false]", "List of todos will include the notes for todos that have them.")
+	f.printCols(colors, "    explain", "List of todos will include the breakdown of the urgency score for each todo.")
+	f.printCols(colors, "    wrap:[true or false]", "Wrap the subject (and columns wider than their report widths) onto more lines rather than truncate it to fit the terminal.")
+	f.printCols(colors, "    output:[json|csv|tsv]", "Print plain data for other programs (also projects, contexts, tags, print and stats). Dates are RFC3339, spent is in hours.")
+	f.printCols(colors, "    output:[md|html]", "Print a Markdown or HTML document with a summary and a table per group, e.g. for a status email.")
+	f.printCols(colors, "    checklist", "(md, html) List todos as checklists rather than tables.")
+	f.printCols(colors, "    file:<name>", "Write the output to a file rather than the console. Requires an output: arg.")
 	f.printCols(colors, "    by:[a|p|c]", "(stats) Group stats by all, project or context.")
 	f.printCols(colors, "    sum:[a|d|w|m]", "(stats) Sum stats per all, per day, per week or per month.")
 	f.printCols(colors, "    cols:[p,a,m,c,ar]", "(stats) Display columns. Default is pending, added, modified, completed, archived.")
@@ -855,6 +1152,12 @@ func (f *ScreenPrinter) PrintListHelp() {
 	f.printCols(colors2, "  Example:  ", "todo completed")
 	f.printCols(colors1, "List archived todos.")
 	f.printCols(colors2, "  Example:  ", "todo archived")
+	f.printCols(colors1, "List todos as JSON for jq, or as CSV or TSV for a spreadsheet.")
+	f.printCols(colors2, "  Example:  ", "todo +BigProject l output:json | jq '.[].Subject'")
+	f.printCols(colors2, "  Example:  ", "todo next output:csv > next.csv")
+	f.printCols(colors1, "Write a weekly status update grouped by project, with notes, as Markdown checklists or an HTML page.")
+	f.printCols(colors2, "  Example:  ", "todo l group:project notes:true checklist output:md")
+	f.printCols(colors2, "  Example:  ", "todo l group:project notes:true output:html file:status.html")
 	f.Writer.Flush()
 }
 
@@ -970,7 +1273,7 @@ func (f *ScreenPrinter) PrintProjectsHelp() {
 
 	f.println(f.fgGreen, "")
 	colors1 = []func(a ...interface{}) string{f.fgCyan, f.fgYellow}
-	f.printCols(colors1, "  Syntax: ", "todo projects")
+	f.printCols(colors1, "  Syntax: ", "todo projects [output:json|csv|tsv|md|html] [file:<name>]")
 	f.Writer.Flush()
 
 	f.println(f.fgGreen, "")
@@ -978,6 +1281,8 @@ func (f *ScreenPrinter) PrintProjectsHelp() {
 	colors2 := []func(a ...interface{}) string{f.fgMagenta, f.fgYellow}
 	f.printCols(colors1, "Print list of projects")
 	f.printCols(colors2, "  Example:  ", "todo projects")
+	f.printCols(colors1, "Print list of projects as JSON")
+	f.printCols(colors2, "  Example:  ", "todo projects output:json")
 	f.Writer.Flush()
 }
 
@@ -988,7 +1293,7 @@ func (f *ScreenPrinter) PrintContextsHelp() {
 
 	f.println(f.fgGreen, "")
 	colors1 = []func(a ...interface{}) string{f.fgCyan, f.fgYellow}
-	f.printCols(colors1, "  Syntax: ", "todo contexts")
+	f.printCols(colors1, "  Syntax: ", "todo contexts [output:json|csv|tsv|md|html] [file:<name>]")
 	f.Writer.Flush()
 
 	f.println(f.fgGreen, "")
@@ -999,6 +1304,79 @@ func (f *ScreenPrinter) PrintContextsHelp() {
 	f.Writer.Flush()
 }
 
+func (f *ScreenPrinter) PrintNextHelp() {
+	colors1 := []func(a ...interface{}) string{f.fgYellow}
+	f.printCols(colors1, "List the most actionable todos. Excludes waiting, blocked and completed todos, sorts by urgency combined with the manual order (ord:all) and shows the top 3 per project, and of those with no project.")
+	f.Writer.Flush()
+
+	f.println(f.fgGreen, "")
+	colors1 = []func(a ...interface{}) string{f.fgCyan, f.fgYellow}
+	f.printCols(colors1, "  Syntax: ", "todo [filters] next [arguments]")
+	f.Writer.Flush()
+
+	f.println(f.fgGreen, "")
+	colors1 = []func(a ...interface{}) string{f.fgBlue, f.fgYellow}
+	colors2 := []func(a ...interface{}) string{f.fgMagenta, f.fgYellow}
+	f.printCols(colors1, "List the next todos.")
+	f.printCols(colors2, "  Example:  ", "todo next")
+	f.printCols(colors1, "List the next todos for the Home context, with the breakdown of the urgency score.")
+	f.printCols(colors2, "  Example:  ", "todo @Home next explain")
+	f.printCols(colors1, "Change the number per project or the weight of the manual order in .todorc.")
+	f.printCols(colors2, "  Example:  ", "report.next.filter=-completed,-#BLOCKED,top:pro:2:none")
+	f.printCols(colors2, "  Example:  ", "urgency.ordinal.coefficient=5.0")
+	f.Writer.Flush()
+}
+
+func (f *ScreenPrinter) PrintStartHelp() {
+	colors1 := []func(a ...interface{}) string{f.fgYellow}
+	f.printCols(colors1, "Track time spent on todos. Start records the start time. Stop records the interval from start to now.")
+	f.Writer.Flush()
+
+	f.println(f.fgGreen, "")
+	colors1 = []func(a ...interface{}) string{f.fgCyan, f.fgYellow}
+	f.printCols(colors1, "  Syntax: ", "todo <filters> start")
+	f.printCols(colors1, "  Syntax: ", "todo [filters] stop")
+	f.Writer.Flush()
+
+	f.println(f.fgGreen, "")
+	colors1 = []func(a ...interface{}) string{f.fgBlue, f.fgYellow}
+	colors2 := []func(a ...interface{}) string{f.fgMagenta, f.fgYellow}
+	f.printCols(colors1, "Start todo 3. Only one todo is started at a time, so any other started todo is stopped.")
+	f.printCols(colors2, "  Example:  ", "todo 3 start")
+	f.printCols(colors1, "Stop the started todo.")
+	f.printCols(colors2, "  Example:  ", "todo stop")
+	f.printCols(colors1, "List the started todo with the time spent.")
+	f.printCols(colors2, "  Example:  ", "todo '#ACTIVE' list")
+	f.printCols(colors1, "Print the time spent per project per week.")
+	f.printCols(colors2, "  Example:  ", "todo stats by:p sum:w cols:ts")
+	f.printCols(colors1, "Allow more than one started todo, or stop the started todo when adding a 'done' todo, in .todorc.")
+	f.printCols(colors2, "  Example:  ", "timetrack.multiple=true")
+	f.printCols(colors2, "  Example:  ", "timetrack.autostop=true")
+	f.Writer.Flush()
+}
+
+func (f *ScreenPrinter) PrintTagsHelp() {
+	colors1 := []func(a ...interface{}) string{f.fgYellow}
+	f.printCols(colors1, "Print list of tags with count of todos for each")
+	f.Writer.Flush()
+
+	f.println(f.fgGreen, "")
+	colors1 = []func(a ...interface{}) string{f.fgCyan, f.fgYellow}
+	f.printCols(colors1, "  Syntax: ", "todo tags [output:json|csv|tsv|md|html] [file:<name>]")
+	f.Writer.Flush()
+
+	f.println(f.fgGreen, "")
+	colors1 = []func(a ...interface{}) string{f.fgBlue, f.fgYellow}
+	colors2 := []func(a ...interface{}) string{f.fgMagenta, f.fgYellow}
+	f.printCols(colors1, "Print list of tags")
+	f.printCols(colors2, "  Example:  ", "todo tags")
+	f.printCols(colors1, "Tag a todo. Tags are labels, distinct from contexts (where) and projects.")
+	f.printCols(colors2, "  Example:  ", "todo 3 e '#review'")
+	f.printCols(colors1, "List overdue todos that are not blocked by other todos, using virtual tags.")
+	f.printCols(colors2, "  Example:  ", "todo '#OVERDUE' '-#BLOCKED'")
+	f.Writer.Flush()
+}
+
 func (f *ScreenPrinter) PrintPrintTodoDetailHelp() {
 	colors1 := []func(a ...interface{}) string{f.fgYellow}
 	f.printCols(colors1, "Print details of todos")
@@ -1006,7 +1384,7 @@ func (f *ScreenPrinter) PrintPrintTodoDetailHelp() {
 
 	f.println(f.fgGreen, "")
 	colors1 = []func(a ...interface{}) string{f.fgCyan, f.fgYellow}
-	f.printCols(colors1, "  Syntax: ", "todo [filters] print")
+	f.printCols(colors1, "  Syntax: ", "todo [filters] print [output:json|csv|tsv|md|html] [file:<name>]")
 	f.Writer.Flush()
 
 	f.println(f.fgGreen, "")
@@ -1016,6 +1394,8 @@ func (f *ScreenPrinter) PrintPrintTodoDetailHelp() {
 	f.printCols(colors2, "  Example:  ", "todo 3 print")
 	f.printCols(colors1, "Print details of todos for project BigProject")
 	f.printCols(colors2, "  Example:  ", "todo +BigProject print")
+	f.printCols(colors1, "Export details of todos for project BigProject to CSV")
+	f.printCols(colors2, "  Example:  ", "todo +BigProject print output:csv > bigproject.csv")
 	f.Writer.Flush()
 }
 
@@ -1381,7 +1761,7 @@ func (f *ScreenPrinter) PrintStatsHelp() {
 
 	f.println(f.fgGreen, "")
 	colors1 = []func(a ...interface{}) string{f.fgCyan, f.fgYellow}
-	f.printCols(colors1, "  Syntax: ", "todo [filters] stats [by:a|p|c] [sum:d|w|m] [cols:p,a,m,c,ar] [range:start date[:end date]] [chart:true|false]")
+	f.printCols(colors1, "  Syntax: ", "todo [filters] stats [by:a|p|c] [sum:d|w|m] [cols:p,a,m,c,ar,ts,po] [range:start date[:end date]] [chart:true|false] [output:json|csv|tsv|md|html] [file:<name>]")
 	f.Writer.Flush()
 
 	f.println(f.fgGreen, "")
@@ -1398,8 +1778,329 @@ func (f *ScreenPrinter) PrintStatsHelp() {
 	f.printCols(colors2, "  Example:  ", "todo due:last_week stats by:a sum:d chart:true")
 	f.printCols(colors1, "Print stats for past 30 days, group by all, sum by day, show chart.")
 	f.printCols(colors2, "  Example:  ", "todo stats by:a sum:d range:-30d chart:true")
+	f.printCols(colors1, "Print time spent per project per day for the past week. Time is recorded with the start and stop commands.")
+	f.printCols(colors2, "  Example:  ", "todo stats by:p sum:d cols:c,ts range:-7d")
 	f.printCols(colors1, "Print stats for December, group by all, sum by day, show chart.")
 	f.printCols(colors2, "  Example:  ", "todo stats by:a sum:d range:2018-12-01:2018-12-31 chart:true")
+	f.printCols(colors1, "Export stats per project per week as CSV. Charts are not shown with output.")
+	f.printCols(colors2, "  Example:  ", "todo stats by:p sum:w output:csv > stats.csv")
+	f.Writer.Flush()
+}
+
+func (f *ScreenPrinter) PrintEditFullHelp() {
+	colors1 := []func(a ...interface{}) string{f.fgYellow}
+	f.printCols(colors1, "Edit todos as a document in $EDITOR. One block per todo with subject, projects, contexts, tags, dates, priority, effort and numbered notes. Changes are applied when the editor exits.")
+	f.Writer.Flush()
+
+	f.println(f.fgGreen, "")
+	colors1 = []func(a ...interface{}) string{f.fgCyan, f.fgYellow}
+	f.printCols(colors1, "  Syntax: ", "todo <filters> edit-full | e!")
+	f.Writer.Flush()
+
+	f.println(f.fgGreen, "")
+	colors1 = []func(a ...interface{}) string{f.fgBlue, f.fgYellow}
+	colors2 := []func(a ...interface{}) string{f.fgMagenta, f.fgYellow}
+	f.printCols(colors1, "Edit todo 3, including all of its notes.")
+	f.printCols(colors2, "  Example:  ", "todo 3 e!")
+	f.printCols(colors1, "Edit all todos for project BigProject.")
+	f.printCols(colors2, "  Example:  ", "todo +BigProject edit-full")
+	f.printCols(colors1, "Use a different editor. $VISUAL is used first, then $EDITOR, then vi.")
+	f.printCols(colors2, "  Example:  ", "EDITOR=nano todo 3 e!")
+	f.Writer.Flush()
+}
+
+func (f *ScreenPrinter) PrintTuiHelp() {
+	colors1 := []func(a ...interface{}) string{f.fgYellow}
+	f.printCols(colors1, "Browse and edit todos in a full screen list formatted with a report. Changes are saved as they are made.")
+	f.Writer.Flush()
+
+	f.println(f.fgGreen, "")
+	colors1 = []func(a ...interface{}) string{f.fgCyan, f.fgYellow}
+	f.printCols(colors1, "  Syntax: ", "todo [filters] tui [report] [sort:<sorts>]")
+	f.Writer.Flush()
+
+	f.println(f.fgGreen, "")
+	f.println(f.fgGreen, "Keys:")
+	colors2 := []func(a ...interface{}) string{f.fgMagenta, f.fgYellow}
+	f.printCols(colors2, "  j k, up down, pgup pgdn  ", "Select a todo")
+	f.printCols(colors2, "  J K  ", "Move the selected todo down or up. Rewrites the ord:all ordinals and lists in that order from then on.")
+	f.printCols(colors2, "  c  ", "Complete")
+	f.printCols(colors2, "  a  ", "Archive")
+	f.printCols(colors2, "  e  ", "Edit the subject. Modifiers (e.g. due:tom +Project) are also accepted.")
+	f.printCols(colors2, "  d  ", "Edit the due date")
+	f.printCols(colors2, "  p  ", "Edit the priority")
+	f.printCols(colors2, "  n  ", "Add a note")
+	f.printCols(colors2, "  v  ", "Cycle through the views configured in .todorc")
+	f.printCols(colors2, "  q  ", "Quit")
+	f.Writer.Flush()
+
+	f.println(f.fgGreen, "")
+	colors1 = []func(a ...interface{}) string{f.fgBlue, f.fgYellow}
+	f.printCols(colors1, "Browse project BigProject in manual order.")
+	f.printCols(colors2, "  Example:  ", "todo +BigProject tui sort:ord:all")
+	f.printCols(colors1, "Browse the next report.")
+	f.printCols(colors2, "  Example:  ", "todo tui next")
+	f.Writer.Flush()
+}
+
+func (f *ScreenPrinter) PrintPomodoroHelp() {
+	colors1 := []func(a ...interface{}) string{f.fgYellow}
+	f.printCols(colors1, "Work on a todo with a full screen pomodoro timer. Each completed pomodoro is recorded as time spent and a note on the todo.")
+	f.Writer.Flush()
+
+	f.println(f.fgGreen, "")
+	colors1 = []func(a ...interface{}) string{f.fgCyan, f.fgYellow}
+	f.printCols(colors1, "  Syntax: ", "todo pomo <id>")
+	f.Writer.Flush()
+
+	f.println(f.fgGreen, "")
+	colors1 = []func(a ...interface{}) string{f.fgBlue, f.fgYellow}
+	colors2 := []func(a ...interface{}) string{f.fgMagenta, f.fgYellow}
+	f.printCols(colors1, "Start pomodoros for todo 3. Press q to quit.")
+	f.printCols(colors2, "  Example:  ", "todo pomo 3")
+	f.printCols(colors1, "List todos with the count of pomodoros.")
+	f.printCols(colors2, "  Example:  ", "report.pomo.columns=id,pomodoros,spent,subject")
+	f.printCols(colors1, "Print pomodoros per day for the past week.")
+	f.printCols(colors2, "  Example:  ", "todo stats sum:d cols:po,ts range:-7d")
+	f.printCols(colors1, "Change the work and break lengths (minutes) in .todorc.")
+	f.printCols(colors2, "  Example:  ", "pomo.work=50")
+	f.printCols(colors2, "  Example:  ", "pomo.break=10")
+	f.Writer.Flush()
+}
+
+func (f *ScreenPrinter) PrintTimesheetHelp() {
+	colors1 := []func(a ...interface{}) string{f.fgYellow}
+	f.printCols(colors1, "Report time spent on todos (recorded with start and stop) by project or context and period, with totals")
+	f.Writer.Flush()
+
+	f.println(f.fgGreen, "")
+	colors1 = []func(a ...interface{}) string{f.fgCyan, f.fgYellow}
+	f.printCols(colors1, "  Syntax: ", "todo [filters] timesheet | ts [by:p|c|a] [sum:d|w|m] [range:start date[:end date]] [output:csv] [file:<name>.csv]")
+	f.Writer.Flush()
+
+	f.println(f.fgGreen, "")
+	colors1 = []func(a ...interface{}) string{f.fgBlue, f.fgYellow}
+	colors2 := []func(a ...interface{}) string{f.fgMagenta, f.fgYellow}
+	f.printCols(colors1, "Print time spent per project per day, last week and this week.")
+	f.printCols(colors2, "  Example:  ", "todo timesheet range:last_week:this_week by:pro sum:daily")
+	f.printCols(colors1, "Print time spent per context per week for the past 30 days.")
+	f.printCols(colors2, "  Example:  ", "todo ts by:ctx sum:w range:-30d")
+	f.printCols(colors1, "Export last week's hours for project BigProject to CSV for billing. Hours are decimal (e.g. 1.50).")
+	f.printCols(colors2, "  Example:  ", "todo +BigProject timesheet range:last_week file:bigproject.csv")
+	f.printCols(colors1, "Print this month's hours per week as CSV, for another program. The end date of a range is included.")
+	f.printCols(colors2, "  Example:  ", "todo timesheet sum:w range:this_month output:csv")
+	f.Writer.Flush()
+}
+
+func (f *ScreenPrinter) PrintBatchHelp() {
+	colors1 := []func(a ...interface{}) string{f.fgYellow}
+	f.printCols(colors1, "Run commands from a file, or stdin if no file or - is given. One command line per line, as typed after 'todo'.")
+	f.printCols(colors1, "Todos are loaded once and saved once at the end. If any command fails, the batch is aborted and nothing is saved.")
+	f.printCols(colors1, "Blank lines and lines starting with # are ignored. Interactive commands (tui, pomo, e!, web, open), sync and init are not allowed.")
+	f.Writer.Flush()
+
+	f.println(f.fgGreen, "")
+	colors1 = []func(a ...interface{}) string{f.fgCyan, f.fgYellow}
+	f.printCols(colors1, "  Syntax: ", "todo batch [file|-]")
+	f.Writer.Flush()
+
+	f.println(f.fgGreen, "")
+	colors1 = []func(a ...interface{}) string{f.fgBlue, f.fgYellow}
+	colors2 := []func(a ...interface{}) string{f.fgMagenta, f.fgYellow}
+	f.printCols(colors1, "Run the commands in a file.")
+	f.printCols(colors2, "  Example:  ", "todo batch weekly-review.txt")
+	f.printCols(colors1, "Add todos generated by another program.")
+	f.printCols(colors2, "  Example:  ", "printf 'add Call Bob +Sales\\nadd Email Sue +Sales\\n' | todo batch -")
+	f.Writer.Flush()
+}
+
+func (f *ScreenPrinter) PrintCalendarHelp() {
+	colors1 := []func(a ...interface{}) string{f.fgYellow}
+	f.printCols(colors1, "Show a month (or year) calendar with the days todos are due highlighted. Days are colored by the")
+	f.printCols(colors1, "worst of their pending todos: overdue, urgent (urgency 8 or more) or due. Today is shown reversed.")
+	f.printCols(colors1, "Filters narrow the todos shown. Colors can be changed with color.calendar.* (see help config).")
+	f.Writer.Flush()
+
+	f.println(f.fgGreen, "")
+	colors1 = []func(a ...interface{}) string{f.fgCyan, f.fgYellow}
+	f.printCols(colors1, "  Syntax: ", "todo calendar | cal [month|year|this_month|next_month|last_month|this_year|next_year|last_year|month:<date>|year:<yyyy>] [agenda] [filters]")
+	f.Writer.Flush()
+
+	f.println(f.fgGreen, "")
+	colors1 = []func(a ...interface{}) string{f.fgBlue, f.fgYellow}
+	colors2 := []func(a ...interface{}) string{f.fgMagenta, f.fgYellow}
+	f.printCols(colors1, "Plan next month around its deadlines, listing the todos due each day.")
+	f.printCols(colors2, "  Example:  ", "todo cal next_month agenda")
+	f.printCols(colors1, "Show the deadlines of a project for the whole year.")
+	f.printCols(colors2, "  Example:  ", "todo cal year +Acme")
+	f.printCols(colors1, "Show the month two months from now.")
+	f.printCols(colors2, "  Example:  ", "todo cal month:2m")
+	f.Writer.Flush()
+}
+
+func (f *ScreenPrinter) PrintAgendaHelp() {
+	colors1 := []func(a ...interface{}) string{f.fgYellow}
+	f.printCols(colors1, "List, day by day, the todos that are due, scheduled, whose wait ends (they become visible), whose until date expires")
+	f.printCols(colors1, "and that were completed. Pending todos due before the first day are carried forward to the top. Defaults to today.")
+	f.printCols(colors1, "Filters narrow the todos listed. Waiting todos are included.")
+	f.Writer.Flush()
+
+	f.println(f.fgGreen, "")
+	colors1 = []func(a ...interface{}) string{f.fgCyan, f.fgYellow}
+	f.printCols(colors1, "  Syntax: ", "todo agenda [today|tomorrow|week|this_week|next_week|last_week|this_month|next_month|last_month|range:start date[:end date]] [filters]")
+	f.Writer.Flush()
+
+	f.println(f.fgGreen, "")
+	colors1 = []func(a ...interface{}) string{f.fgBlue, f.fgYellow}
+	colors2 := []func(a ...interface{}) string{f.fgMagenta, f.fgYellow}
+	f.printCols(colors1, "Start the day with what is overdue and what is on today.")
+	f.printCols(colors2, "  Example:  ", "todo agenda")
+	f.printCols(colors1, "Plan the week for a project.")
+	f.printCols(colors2, "  Example:  ", "todo agenda week +Acme")
+	f.printCols(colors1, "The next three days.")
+	f.printCols(colors2, "  Example:  ", "todo agenda range:today:2d")
+	f.Writer.Flush()
+}
+
+func (f *ScreenPrinter) PrintRemindHelp() {
+	colors1 := []func(a ...interface{}) string{f.fgYellow}
+	f.printCols(colors1, "Run in the foreground, reminding of todos that are due, scheduled or stop waiting soon. Reminders fire at each lead time")
+	f.printCols(colors1, "before the event (remind.lead, default 1h), by running remind.cmd with the message as its last argument, or by printing it.")
+	f.printCols(colors1, "The command also gets TODO_ID, TODO_UUID, TODO_SUBJECT, TODO_EVENT and TODO_AT in its environment.")
+	f.printCols(colors1, "Fired reminders are remembered in .todos_reminders.json, so they are not repeated after a restart.")
+	f.printCols(colors1, "Filters narrow the todos reminded of. 'once' checks once and exits, e.g. to run from cron.")
+	f.Writer.Flush()
+
+	f.println(f.fgGreen, "")
+	colors1 = []func(a ...interface{}) string{f.fgCyan, f.fgYellow}
+	f.printCols(colors1, "  Syntax: ", "todo [filters] remind [once]")
+	f.Writer.Flush()
+
+	f.println(f.fgGreen, "")
+	colors1 = []func(a ...interface{}) string{f.fgBlue, f.fgYellow}
+	colors2 := []func(a ...interface{}) string{f.fgMagenta, f.fgYellow}
+	f.printCols(colors1, "Remind of all todos until stopped.")
+	f.printCols(colors2, "  Example:  ", "todo remind")
+	f.printCols(colors1, "Remind of work todos only.")
+	f.printCols(colors2, "  Example:  ", "todo @Work remind")
+	f.printCols(colors1, "Desktop notifications a day and an hour before, checking every 5 minutes from cron (in .todorc).")
+	f.printCols(colors2, "  Example:  ", "remind.lead=1d,1h  remind.cmd=notify-send Todo  */5 * * * * todo remind once")
+	f.Writer.Flush()
+}
+
+func (f *ScreenPrinter) PrintHooksHelp() {
+	colors1 := []func(a ...interface{}) string{f.fgYellow}
+	f.printCols(colors1, "Hooks are executable scripts in .todo_hooks (in the repo, or else the home directory, or hooks.dir in .todorc).")
+	f.printCols(colors1, "Scripts named on-add*, on-modify*, on-complete* and on-delete* run, in name order, before the change is saved.")
+	f.printCols(colors1, "They get the todo as JSON on stdin (the old, then the new todo, on separate lines for on-modify). To change the")
+	f.printCols(colors1, "todo, print it, or just the fields to change, as JSON on the first line of stdout. Other output is printed.")
+	f.printCols(colors1, "on-modify* runs for every other change: edits, start, stop, uncomplete, archive, unarchive, touch and order.")
+	f.printCols(colors1, "A non-zero exit rejects the change, with the script's output as the message. Nothing is saved.")
+	f.printCols(colors1, "Scripts named on-exit* run after the command, with the todos it changed, one per line. TODO_HOOK is set to the event.")
+	f.Writer.Flush()
+
+	f.println(f.fgGreen, "")
+	colors1 = []func(a ...interface{}) string{f.fgBlue, f.fgYellow}
+	colors2 := []func(a ...interface{}) string{f.fgMagenta, f.fgYellow}
+	f.printCols(colors1, "Tag todos that mention a ticket (.todo_hooks/on-add-ticket).")
+	f.printCols(colors2, "  Example:  ", `read t; case "$t" in *JIRA-*) echo '{"tags":["ticket"]}';; esac`)
+	f.printCols(colors1, "Require a due date on +Work todos (.todo_hooks/on-modify-work).")
+	f.printCols(colors2, "  Example:  ", `read old; read new; case "$new" in *'"Work"'*'"due":""'*) echo "+Work todos need a due date"; exit 1;; esac`)
+	f.printCols(colors1, "Post completed todos to chat (.todo_hooks/on-complete-chat).")
+	f.printCols(colors2, "  Example:  ", `curl -s -d @- https://chat.example.com/hooks/todo > /dev/null`)
+	f.Writer.Flush()
+}
+
+//Rows of alias name, definition and expansion
+func (f *ScreenPrinter) PrintAliasesHelp(aliases [][]string) {
+	colors1 := []func(a ...interface{}) string{f.fgYellow}
+	f.printCols(colors1, "Aliases (alias.<name>=<command line> in .todorc) run the command line, with the filters and words given added to it.")
+	f.printCols(colors1, "The words after the alias fill in $1..$n, or all of them $@. ${n:-default} is used when word n is missing.")
+	f.printCols(colors1, "Words not used are added to the command. An alias can use other aliases, but not itself.")
+	f.Writer.Flush()
+
+	f.println(f.fgGreen, "")
+	colors1 = []func(a ...interface{}) string{f.fgBlue, f.fgYellow}
+	colors2 := []func(a ...interface{}) string{f.fgMagenta, f.fgYellow}
+	f.printCols(colors1, "Todos assigned to me in a project, due by a date or else Friday.")
+	f.printCols(colors2, "  Example:  ", "alias.mine=+$1 @me due:${2:-fri} list  todo mine Work tom")
+	f.printCols(colors1, "Work todos of mine, using the mine alias.")
+	f.printCols(colors2, "  Example:  ", "alias.wk=mine Work")
+	f.Writer.Flush()
+
+	if len(aliases) > 0 {
+		f.println(f.fgGreen, "")
+		colors := []func(a ...interface{}) string{f.fgGreen, f.fgGreen, f.fgGreen}
+		f.printCols(colors, "  Alias", "Definition", "Expansion")
+		colors = []func(a ...interface{}) string{f.fgCyan, f.fgYellow, f.fgYellow}
+		for _, alias := range aliases {
+			f.printCols(colors, "  "+alias[0], alias[1], alias[2])
+		}
+		f.Writer.Flush()
+	}
+}
+
+//Rows of key, value, where it was set and the default it overrides
+func (f *ScreenPrinter) PrintConfigList(rows [][]string) {
+	colors := []func(a ...interface{}) string{f.fgGreen, f.fgGreen, f.fgGreen, f.fgGreen}
+	f.printCols(colors, "Key", "Value", "Set in", "Default")
+	colors = []func(a ...interface{}) string{f.fgCyan, f.fgYellow, f.fgBlue, f.fgWhite}
+	for _, row := range rows {
+		f.printCols(colors, row...)
+	}
+	f.Writer.Flush()
+}
+
+func (f *ScreenPrinter) PrintRepos(rows [][]string) {
+	colors := []func(a ...interface{}) string{f.fgGreen, f.fgGreen, f.fgGreen}
+	f.printCols(colors, "", "Name", "Path")
+	colors = []func(a ...interface{}) string{f.fgYellow, f.fgCyan, f.fgWhite}
+	for _, row := range rows {
+		f.printCols(colors, row...)
+	}
+	f.Writer.Flush()
+}
+
+func (f *ScreenPrinter) PrintReposHelp() {
+	colors1 := []func(a ...interface{}) string{f.fgYellow}
+	f.printCols(colors1, "A repo is a directory with a .todos.json (see init). todo uses the nearest one up from the current directory, as git")
+	f.printCols(colors1, "finds .git, else the one in the home directory. The repo's .todorc, .todo_hooks and .todo_plugins are used with it.")
+	f.printCols(colors1, "--repo=<name or dir>, or else TODO_REPO, uses another repo. Name repos with repo.<name>.path=<dir> in ~/.todorc")
+	f.printCols(colors1, "(or $XDG_CONFIG_HOME/todo/config), as the repo's own .todorc is read once the repo is found.")
+	f.Writer.Flush()
+
+	f.println(f.fgGreen, "")
+	colors1 = []func(a ...interface{}) string{f.fgBlue, f.fgYellow}
+	colors2 := []func(a ...interface{}) string{f.fgMagenta, f.fgYellow}
+	f.printCols(colors1, "List the named repos and the one in use, marked with *.")
+	f.printCols(colors2, "  Example:  ", "todo repos")
+	f.printCols(colors1, "Add to the work repo from anywhere.")
+	f.printCols(colors2, "  Example:  ", "todo --repo=work a Send the report due:fri")
+	f.printCols(colors1, "List the todos due today in every repo, each under its name and path, with that repo's hooks. repo: only works")
+	f.printCols(colors1, "with list and other reports. Use --repo= for other commands.")
+	f.printCols(colors2, "  Example:  ", "todo repo:all due:tod list")
+	f.Writer.Flush()
+}
+
+func (f *ScreenPrinter) PrintPluginsHelp() {
+	colors1 := []func(a ...interface{}) string{f.fgYellow}
+	f.printCols(colors1, "Plugins are executables named todo-<name>, in .todo_plugins (in the repo, or else the home directory, or plugins.dir")
+	f.printCols(colors1, "in .todorc) or on the PATH. 'todo [filters] <name> [args]' runs one if no command or alias is named <name>.")
+	f.printCols(colors1, "The plugin gets the args. With filters, it gets the todos matching them as a JSON array on stdin.")
+	f.printCols(colors1, "The environment has TODO_PENDING_FILE, TODO_ARCHIVE_FILE, TODO_BACKLOG_FILE and TODO_CONFIG_FILE (the repo paths),")
+	f.printCols(colors1, "TODO_BIN (this todo executable), TODO_PLUGIN, TODO_FILTERS, TODO_REPO (so todo run by the plugin uses the same repo),")
+	f.printCols(colors1, "and each .todorc value as TODO_CFG_<KEY> with dots as underscores (e.g. TODO_CFG_SYNC_FILEPATH). The plugin's exit code")
+	f.printCols(colors1, "is todo's exit code. 'help' lists the plugins installed.")
+	f.printCols(colors1, "Put settings for a plugin under plugin.<name>. in .todorc (e.g. plugin.count.by=context as TODO_CFG_PLUGIN_COUNT_BY).")
+	f.Writer.Flush()
+
+	f.println(f.fgGreen, "")
+	colors1 = []func(a ...interface{}) string{f.fgBlue, f.fgYellow}
+	colors2 := []func(a ...interface{}) string{f.fgMagenta, f.fgYellow}
+	f.printCols(colors1, "Count the +Work todos by context (.todo_plugins/todo-count, run as 'todo +Work count').")
+	f.printCols(colors2, "  Example:  ", `jq -r '.[].contexts[]' | sort | uniq -c`)
+	f.printCols(colors1, "Commit the repo (todo-commit on the PATH, run as 'todo commit Weekly review').")
+	f.printCols(colors2, "  Example:  ", `cd "$(dirname "$TODO_PENDING_FILE")" && git commit -qam "$*"`)
 	f.Writer.Flush()
 }
 
@@ -1411,27 +2112,73 @@ func (f *ScreenPrinter) PrintConfigHelp() {
 	f.println(f.fgGreen, "")
 	colors1 = []func(a ...interface{}) string{f.fgCyan, f.fgYellow}
 	f.printCols(colors1, "  Filename: ", ".todorc")
+	f.printCols(colors1, "  Layers: ", "Each overrides those before: builtin defaults, $XDG_CONFIG_HOME/todo/config (default ~/.config), ~/.todorc,")
+	f.printCols(colors1, "", "the repo's .todorc, TODO_<KEY> environment variables, then rc.<key>=<value> args. config list shows where each was set.")
+	f.printCols(colors1, "  Environment: ", "TODO_<KEY> sets the key with dots as underscores, e.g. TODO_SYNC_FILEPATH. Use rc.<key> for keys with capitals.")
+	f.printCols(colors1, "  Include: ", "include=<path> reads another file at that point, relative to the file including it. E.g. a checked-in team file.")
+	f.printCols(colors1, "  Example: ", "todo rc.color=off rc.report.default.group=context list")
 	f.Writer.Flush()
 
 	f.println(f.fgGreen, "")
-	f.println(f.fgGreen, "Configuration Attributes (key=value format):")
+	f.println(f.fgGreen, "Commands:")
 	colors1 = []func(a ...interface{}) string{f.fgBlue, f.fgYellow}
 	colors2 := []func(a ...interface{}) string{f.fgMagenta, f.fgYellow}
+	f.printCols(colors2, "  config get <key>  ", "Print the value of a setting, or its default.")
+	f.printCols(colors2, "  config set <key> <value>  ", "Check the value and set it in .todorc, replacing the line that sets it or adding one.")
+	f.printCols(colors2, "  config unset <key>  ", "Remove the setting from .todorc, so the default applies.")
+	f.printCols(colors2, "  config list [prefix]  ", "List the settings, starting with the prefix if given, with the file and line that set them or 'default'.")
+	f.printCols(colors2, "  config check  ", "Check .todorc for unknown settings, columns and sort keys, header counts, bad regexes, priorities and aliases.")
+	f.printCols(colors1, "Print problems with their line numbers. Exits with code 4 if there are any.")
+	f.printCols(colors2, "  Example:  ", "todo config check")
+	f.Writer.Flush()
+
+	f.println(f.fgGreen, "")
+	f.println(f.fgGreen, "Configuration Attributes (key=value format):")
+	colors1 = []func(a ...interface{}) string{f.fgBlue, f.fgYellow}
+	colors2 = []func(a ...interface{}) string{f.fgMagenta, f.fgYellow}
 	f.printCols(colors1, "Configure a report (format for listing todos). Report name is an alias for 'list'. Report 'default' will be applied if no other report name matched.")
 	f.printCols(colors2, "  report.<name>.description  ", "A description for this report.")
-	f.printCols(colors2, "  report.<name>.columns  ", "Columns to display (comma-sep). [id|completed|age|due|context|project|ord:all|ord:pro|ord:ctx]")
+	f.printCols(colors2, "  report.<name>.columns  ", "Columns to display (comma-sep). [id|completed|age|due|scheduled|context|project|tags|vtags|urgency|spent|pomodoros|ord:all|ord:pro|ord:ctx]")
 	f.printCols(colors2, "  report.<name>.headers  ", "Display headers for columns (comma-sep). e.g. 'Id' for id, 'Age' for age.")
-	f.printCols(colors2, "  report.<name>.sort  ", "Multi-sorting instructions (comma-sep). [+/-][id|age|idle|due|created|modified|context|project|ord:all|ord:pro|ord:ctx]")
+	f.printCols(colors2, "  report.<name>.sort  ", "Multi-sorting instructions (comma-sep). [+/-][id|age|idle|due|scheduled|created|modified|context|project|urgency|next|ord:all|ord:pro|ord:ctx]")
 	f.printCols(colors2, "  report.<name>.filter  ", "Filters (comma-sep). See main 'help' for details on filters.")
 	f.printCols(colors2, "  report.<name>.group  ", "[project | context]")
 	f.printCols(colors2, "  report.<name>.notes  ", "[true|false]")
+	f.printCols(colors2, "  report.<name>.widths  ", "Max width of each column (comma-sep). 0 for no limit. e.g. 0,0,0,0,12,12,0")
+	f.printCols(colors2, "  report.<name>.wrap  ", "[true|false] Wrap columns too wide for the terminal or their width rather than truncate them.")
 	f.printCols(colors1, "Configure priority values. Default is H,M,L.")
 	f.printCols(colors2, "  priority  ", "[comma-separated values] Order highest to lowest (e.g. H,M,L).")
+	f.printCols(colors1, "Configure the urgency score (sort:urgency, column urgency). Use the 'explain' arg on a report to see the terms for each todo.")
+	f.printCols(colors2, "  urgency.<term>.coefficient  ", "[number] Terms: due(12) blocking(8) priority(6) active(4) age(2) annotations(1) tags(1) project(1) waiting(-3) blocked(-5)")
+	f.printCols(colors2, "  urgency.age.max  ", "[days] Age at which the age term is at its maximum. Default 365.")
+	f.printCols(colors2, "  urgency.ordinal.coefficient  ", "[number] Weight of the manual order (ord:all) in sort:next and the 'next' report. Default 3.")
+	f.printCols(colors2, "  urgency.project.<name>.coefficient  ", "[number] Added to todos with the project. Also urgency.context.<name>, urgency.tag.<name>, urgency.priority.<value>")
+	f.printCols(colors1, "Configure colors. Specs are like 'red', 'bold bright cyan', 'black on yellow' or 'none'.")
+	f.printCols(colors2, "  color  ", "[on|off] Turn colors off, e.g. when piping. Also off when the NO_COLOR environment variable is set.")
+	f.printCols(colors2, "  color.<element>  ", "[spec] Elements: header label id status started subject due late date project context tag priority number spent pomodoro")
+	f.printCols(colors2, "  color.active  ", "[spec] Row color for started todos. Also color.overdue, color.due.today")
+	f.printCols(colors2, "  color.priority.<value>  ", "[spec] Row color for todos with the priority. Also color.project.<name>, color.tag.<name>")
+	f.printCols(colors2, "  color.calendar.<state>  ", "[spec] Calendar days. States: today due urgent overdue")
+	f.printCols(colors2, "  color.precedence  ", "[rules (comma-sep)] Order rules are tried in. First match wins. Default active,overdue,due.today,priority,tag,project")
+	f.printCols(colors1, "Configure time tracking with the start and stop commands.")
+	f.printCols(colors2, "  timetrack.multiple  ", "[true|false] Allow more than one started todo. Default false.")
+	f.printCols(colors2, "  timetrack.autostop  ", "[true|false] Stop the started todo when adding a 'done' todo. Default false.")
+	f.printCols(colors1, "Configure the pomodoro timer (pomo command).")
+	f.printCols(colors2, "  pomo.work  ", "[minutes] Length of a pomodoro. Default 25.")
+	f.printCols(colors2, "  pomo.break  ", "[minutes] Length of the break after a pomodoro. Default 5.")
+	f.printCols(colors1, "Configure reminders (remind command).")
+	f.printCols(colors2, "  remind.lead  ", "[lead times (comma-sep)] How long before the event to remind, in m, h, d or w. 0 reminds at the event. Default 1h.")
+	f.printCols(colors2, "  remind.cmd  ", "[command] Notification command. The message is added as the last argument. Default prints the reminder.")
+	f.printCols(colors2, "  remind.events  ", "[due|scheduled|wait (comma-sep)] Events to remind of. Default due,scheduled,wait.")
+	f.printCols(colors2, "  remind.interval  ", "[minutes] How often to check the todos. Default 1.")
+	f.printCols(colors1, "Configure hooks and plugins (see help hooks, help plugins).")
+	f.printCols(colors2, "  hooks.dir  ", "[path to hooks directory] Default .todo_hooks in the repo, or else the home directory.")
+	f.printCols(colors2, "  plugins.dir  ", "[path to plugins directory] Default .todo_plugins in the repo, or else the home directory. The PATH is searched after it.")
 	f.printCols(colors1, "Configure synchronization of todos to another file location.")
 	f.printCols(colors2, "  sync.filepath  ", "[Path to file including filename. Directory must exist.]")
 	f.printCols(colors2, "  sync.encrypt.passphrase  ", "[passphrase | * (prompt) | <blank> (don't encrypt)]")
 	f.printCols(colors1, "Define aliases to save typing on common commands.")
-	f.printCols(colors2, "  alias.<name>  ", "[command line to alias (after todo executable). E.g. list group:project]")
+	f.printCols(colors2, "  alias.<name>  ", "[command line to alias (after todo executable). E.g. list group:project] $1..$n, ${n:-default} and $@ take the words after the alias (see help aliases).")
 	f.printCols(colors1, "Define named view filters that can be applied by default and referenced by name.")
 	f.printCols(colors2, "  view.<name>.filter  ", "[comma-separated filters. E.g. @home,due:any]")
 	f.printCols(colors1, "Set the currently applied view filter from list of views defined. Change with 'view' command.")
@@ -74,6 +74,8 @@ type TodoStat struct {
 	Modified        int
 	Completed       int
 	Archived        int
+	Spent           time.Duration
+	Pomodoros       int
 }
 
 type StatsGroup struct {
@@ -84,6 +86,11 @@ type StatsGroup struct {
 
 type StatsData struct {
 	Groups map[string]*StatsGroup
+	Clock  Clock //Ranges without an end, and running time intervals, end now on this clock
+}
+
+func NewStatsData(clock Clock) *StatsData {
+	return &StatsData{Groups: map[string]*StatsGroup{}, Clock: clock}
 }
 
 func (s *StatsData) GetSortedGroups() []*StatsGroup {
@@ -138,7 +145,7 @@ func (s *StatsData) CalcStats(todos []*Todo, groupBy string, sum int, rangeTimes
 		if len(rangeTimes) > 1 {
 			endDate = rangeTimes[1]
 		} else {
-			endDate = Now
+			endDate = s.Clock.Now()
 		}
 
 		var rangeStats []*TodoStat
@@ -168,6 +175,14 @@ func (s *StatsData) CalcStatsForTodoAndGroup(todo *Todo, group string, sumBy int
 	}
 	var stat *TodoStat
 	var pending = true
+	//Time spent is counted in the period in which each interval started
+	for _, interval := range todo.TimeIntervals() {
+		stat = sg.getStatsForDate(periodStart(stringToTime(interval.Start), sumBy))
+		stat.Spent += interval.Duration(s.Clock.Now())
+		if interval.Pomodoro {
+			stat.Pomodoros++
+		}
+	}
 	if sumBy == 1 { //weekly
 		startDateFunc := bow
 		stat = sg.getStatsForDate(startDateFunc(addDate))
@@ -234,6 +249,48 @@ func (s *StatsData) CalcStatsForTodoAndGroup(todo *Todo, group string, sumBy int
 	}
 }
 
+//Header for a stats column (cols: modifier). Returns false if the column is unknown.
+func statsColumnHeader(col string) (string, bool) {
+	switch col {
+	case "p":
+		return "Pending", true
+	case "a":
+		return "Added", true
+	case "m":
+		return "Modified", true
+	case "c":
+		return "Completed", true
+	case "ar":
+		return "Archived", true
+	case "ts":
+		return "Spent", true
+	case "po":
+		return "Pomodoros", true
+	}
+	return "", false
+}
+
+//Parse the sum: modifier (d|w|m) to the sum value (0 daily, 1 weekly, 2 monthly) and its display name
+func parseSumBy(sumBy string) (int, string) {
+	if strings.HasPrefix(strings.ToLower(sumBy), "w") {
+		return 1, "Week"
+	} else if strings.HasPrefix(strings.ToLower(sumBy), "m") {
+		return 2, "Month"
+	}
+	return 0, "Day"
+}
+
+//Start of the day, week or month containing t
+func periodStart(t time.Time, sumBy int) time.Time {
+	switch sumBy {
+	case 1:
+		return bow(t)
+	case 2:
+		return bom(t)
+	}
+	return bod(t)
+}
+
 func (sg *StatsGroup) getStatsForDate(date time.Time) *TodoStat {
 	var stat *TodoStat
 	for _, stat = range sg.Stats {
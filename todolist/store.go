@@ -1,14 +1,14 @@
 package todolist
 
 type Store interface {
-	Initialize()
+	Initialize() error
 	LoadPending() ([]*Todo, error)
 	LoadArchived() ([]*Todo, error)
 	LoadBacklog(filepath string) ([]*Todo, error)
 	GetBacklogFilepath() string
-	AppendBacklog(filepath string, todos []*Todo)
-	DeleteBacklog(filepath string)
-	Save(todos []*Todo)
+	AppendBacklog(filepath string, todos []*Todo) error
+	DeleteBacklog(filepath string) error
+	Save(todos []*Todo) error
 	Import(filepath string) ([]*Todo, error)
-	Export(filepath string, todos []*Todo)
+	Export(filepath string, todos []*Todo) error
 }
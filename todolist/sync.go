@@ -6,11 +6,9 @@ import (
 	"crypto/md5"
 	"crypto/rand"
 	"encoding/hex"
-	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"log"
 	"os"
 	"os/exec"
 	"strings"
@@ -29,15 +27,16 @@ type TodoSync struct {
 	Backlog       *TodoList
 	Remote        *TodoList
 	Local         *TodoList
+	Clock         Clock
 }
 
-func NewTodoSync(cfg *Config, s Store) *TodoSync {
-	return &TodoSync{config: cfg, store: s, Backlog: &TodoList{}, Remote: &TodoList{}, Local: &TodoList{}}
+func NewTodoSync(cfg *Config, s Store, clock Clock) *TodoSync {
+	return &TodoSync{config: cfg, store: s, Backlog: NewTodoList(clock), Remote: NewTodoList(clock), Local: NewTodoList(clock), Clock: clock}
 }
 
 func (s *TodoSync) Sync(verbose bool) error {
 	if s.config.SyncFilepath == "" {
-		return errors.New("No sync.filepath defined in .todorc config file")
+		return newError(ErrBadConfig, "No sync.filepath defined in .todorc config file")
 	}
 	syncFilepath := s.config.SyncFilepath
 	origSyncFilepath := ""
@@ -55,7 +54,7 @@ func (s *TodoSync) Sync(verbose bool) error {
 
 		tmpfile, err := ioutil.TempFile("", "temp_sync_backlog.json")
 		if err != nil {
-			log.Fatal(err)
+			return err
 		}
 		defer os.Remove(tmpfile.Name()) // clean up
 		origSyncFilepath = syncFilepath
@@ -63,7 +62,9 @@ func (s *TodoSync) Sync(verbose bool) error {
 		//println("origSyncFilepath: ", origSyncFilepath)
 		//println("syncFilepath: ", syncFilepath)
 		//decrypt remote file, then write plain text to temp file location
-		decryptFile(origSyncFilepath, syncFilepath, encryptionPassphrase)
+		if err := decryptFile(origSyncFilepath, syncFilepath, encryptionPassphrase); err != nil {
+			return err
+		}
 	}
 
 	store := s.store
@@ -71,20 +72,17 @@ func (s *TodoSync) Sync(verbose bool) error {
 	var todos []*Todo
 	todos, err = store.LoadPending()
 	if err != nil {
-		println("Error reading pending todos for sync job")
-		return err
+		return fmt.Errorf("Error reading pending todos for sync job: %w", err)
 	}
 	s.Local.Load(todos)
 	todos, err = store.LoadArchived()
 	if err != nil {
-		println("Error reading archived todos for sync job")
-		return err
+		return fmt.Errorf("Error reading archived todos for sync job: %w", err)
 	}
 	s.Local.Load(todos)
 	todos, err = store.LoadBacklog(store.GetBacklogFilepath())
 	if err != nil {
-		println("Error reading local backlog todos for sync job")
-		return err
+		return fmt.Errorf("Error reading local backlog todos for sync job: %w", err)
 	}
 	backlogCount := 0
 	s.Backlog.Load(todos)
@@ -93,15 +91,15 @@ func (s *TodoSync) Sync(verbose bool) error {
 			s.Checkpoint = s.Backlog.Data[0]
 			backlogCount = len(todos) - 1
 		} else {
-			s.Checkpoint = NewTodo()
-			s.Checkpoint.Status = "Checkpoint"
-			s.Checkpoint.IsModified = true
+			if s.Checkpoint, err = newCheckpoint(); err != nil {
+				return err
+			}
 			backlogCount = len(todos)
 		}
 	} else {
-		s.Checkpoint = NewTodo()
-		s.Checkpoint.Status = "Checkpoint"
-		s.Checkpoint.IsModified = true
+		if s.Checkpoint, err = newCheckpoint(); err != nil {
+			return err
+		}
 	}
 	todos, err = store.LoadBacklog(syncFilepath)
 	if err != nil {
@@ -114,28 +112,37 @@ func (s *TodoSync) Sync(verbose bool) error {
 	s.syncRemoteChanges()
 
 	//Set new checkpoint to be used in backlog and remote backlog
-	newCheckpoint := NewTodo()
-	newCheckpoint.Status = "Checkpoint"
-	newCheckpoint.ModifiedDate = timeToString(Now)
-	newCheckpoint.IsModified = true
+	checkpoint, err := newCheckpoint()
+	if err != nil {
+		return err
+	}
+	checkpoint.ModifiedDate = timeToString(s.Clock.Now())
 
 	if len(s.Backlog.Data) > 0 && s.Backlog.Data[0].Status == "Checkpoint" {
 		s.Backlog.Data = s.Backlog.Data[1:] //remove starting checkpoint before updating remote file
 	}
-	s.Backlog.Data = append(s.Backlog.Data, newCheckpoint)
-	store.AppendBacklog(syncFilepath, s.Backlog.Data)
+	s.Backlog.Data = append(s.Backlog.Data, checkpoint)
+	if err := store.AppendBacklog(syncFilepath, s.Backlog.Data); err != nil {
+		return err
+	}
 
 	//Re-load Remote Backlog and remove prior checkpoint
-	s.RemovePriorCheckpointFromSyncFile(syncFilepath)
+	if err := s.RemovePriorCheckpointFromSyncFile(syncFilepath); err != nil {
+		return err
+	}
 
 	//If encrypting, read temp file, re-encrypt and write to orig sync file location
 	if encryptionPassphrase != "" {
-		encryptFile(syncFilepath, origSyncFilepath, encryptionPassphrase)
+		if err := encryptFile(syncFilepath, origSyncFilepath, encryptionPassphrase); err != nil {
+			return err
+		}
 		store.DeleteBacklog(syncFilepath) //delete temporary file
 	}
 
 	//Delete existing local backlog file
-	store.DeleteBacklog(store.GetBacklogFilepath())
+	if err := store.DeleteBacklog(store.GetBacklogFilepath()); err != nil {
+		return err
+	}
 
 	//Ensure none of the todos has IsModified == true so none end up in the backlog
 	for _, todo := range s.Local.Data {
@@ -144,10 +151,12 @@ func (s *TodoSync) Sync(verbose bool) error {
 	//Re-assign all the Ids based on Uuid sort order so that two repos
 	//that are synced to the same point will have the same ids for same task
 	s.Local.ReassignAllIds()
-	//Add newCheckpoint so it is the only entry in the new backlog file
-	s.Local.Data = append(s.Local.Data, newCheckpoint)
+	//Add the new checkpoint so it is the only entry in the new backlog file
+	s.Local.Data = append(s.Local.Data, checkpoint)
 	//Save will write todos into pending, archived and backlog files
-	store.Save(s.Local.Data)
+	if err := store.Save(s.Local.Data); err != nil {
+		return err
+	}
 
 	//Print stats about the sync
 	//No. of Todos added, modified, deleted
@@ -159,29 +168,46 @@ func (s *TodoSync) Sync(verbose bool) error {
 
 	//If verbose flag is set, also print the actual todos added, modified and deleted
 	if verbose {
-		printer := NewScreenPrinter()
+		printer := NewScreenPrinter(s.Clock, s.config.Colors)
 		report, ok := s.config.GetReport("default")
 		if !ok {
-			fmt.Println("ERROR getting default report from config. Can't print Todos")
-			os.Exit(1)
+			return newError(ErrBadConfig, "ERROR getting default report from config. Can't print Todos")
 		}
+		report.Sorter = report.Sorter.ranked(s.config.Priority, s.config.UrgencyCoefficients)
 		if len(s.addedTodos) > 0 {
 			fmt.Println("Added:")
-			printer.PrintReport(report, s.addedTodos)
+			if err := printer.PrintReport(report, s.addedTodos); err != nil {
+				return err
+			}
 		}
 		if len(s.modifiedTodos) > 0 {
 			fmt.Println("Modified:")
-			printer.PrintReport(report, s.modifiedTodos)
+			if err := printer.PrintReport(report, s.modifiedTodos); err != nil {
+				return err
+			}
 		}
 		if len(s.deletedTodos) > 0 {
 			fmt.Println("Deleted:")
-			printer.PrintReport(report, s.deletedTodos)
+			if err := printer.PrintReport(report, s.deletedTodos); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
 }
 
-func (s *TodoSync) RemovePriorCheckpointFromSyncFile(syncFilepath string) {
+//A new checkpoint todo, marking the point in the backlog up to which todos have been synced
+func newCheckpoint() (*Todo, error) {
+	checkpoint, err := NewTodo()
+	if err != nil {
+		return nil, err
+	}
+	checkpoint.Status = "Checkpoint"
+	checkpoint.IsModified = true
+	return checkpoint, nil
+}
+
+func (s *TodoSync) RemovePriorCheckpointFromSyncFile(syncFilepath string) error {
 	todos, err := s.store.LoadBacklog(syncFilepath)
 	if err != nil {
 		todos = []*Todo{}
@@ -202,9 +228,11 @@ func (s *TodoSync) RemovePriorCheckpointFromSyncFile(syncFilepath string) {
 		}
 	}
 	//Delete existing local backlog file
-	s.store.DeleteBacklog(syncFilepath)
+	if err := s.store.DeleteBacklog(syncFilepath); err != nil {
+		return err
+	}
 	//Write new backlog file
-	s.store.AppendBacklog(syncFilepath, todos)
+	return s.store.AppendBacklog(syncFilepath, todos)
 }
 
 func (s *TodoSync) newSinceLastSync(todos []*Todo, checkpoint *Todo) []*Todo {
@@ -282,8 +310,9 @@ func (s *TodoSync) syncRemoteChanges() {
 
 func (s *TodoSync) diffTodos(local *Todo, remote *Todo) *Todo {
 
-	localTime := getModifiedTime(local)
-	remoteTime := getModifiedTime(remote)
+	now := s.Clock.Now()
+	localTime := getModifiedTime(local, now)
+	remoteTime := getModifiedTime(remote, now)
 
 	if remoteTime.After(localTime) {
 		local.Subject = remote.Subject
@@ -291,11 +320,17 @@ func (s *TodoSync) diffTodos(local *Todo, remote *Todo) *Todo {
 		local.ModifiedDate = remote.ModifiedDate
 		local.Wait = remote.Wait
 		local.Until = remote.Until
+		local.Scheduled = remote.Scheduled
 		local.Due = remote.Due
 		local.Completed = remote.Completed
 		local.CompletedDate = remote.CompletedDate
 		local.Status = remote.Status
 		local.Notes = remote.Notes
+		local.Tags = remote.Tags
+		local.Start = remote.Start
+		local.Recur = remote.Recur
+		local.Depends = remote.Depends
+		local.Intervals = remote.Intervals
 		//Determine if adding or removing projects from local
 		//and invoke todolist.AddProject or todolist.RemoveProject, which
 		//will ensure the ordinals are updated.
@@ -349,55 +384,69 @@ func createHash(key string) string {
 	return hex.EncodeToString(hasher.Sum(nil))
 }
 
-func encrypt(data []byte, passphrase string) []byte {
-	block, _ := aes.NewCipher([]byte(createHash(passphrase)))
+func encrypt(data []byte, passphrase string) ([]byte, error) {
+	block, err := aes.NewCipher([]byte(createHash(passphrase)))
+	if err != nil {
+		return nil, err
+	}
 	gcm, err := cipher.NewGCM(block)
 	if err != nil {
-		panic(err.Error())
+		return nil, err
 	}
 	nonce := make([]byte, gcm.NonceSize())
 	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
-		panic(err.Error())
+		return nil, err
 	}
 	ciphertext := gcm.Seal(nonce, nonce, data, nil)
-	return ciphertext
+	return ciphertext, nil
 }
 
-func decrypt(data []byte, passphrase string) []byte {
+//Decrypt data encrypted with the passphrase. Data that does not open with it gives ErrWrongPassphrase.
+func decrypt(data []byte, passphrase string) ([]byte, error) {
 	key := []byte(createHash(passphrase))
 	block, err := aes.NewCipher(key)
 	if err != nil {
-		panic(err.Error())
+		return nil, err
 	}
 	gcm, err := cipher.NewGCM(block)
 	if err != nil {
-		panic(err.Error())
+		return nil, err
 	}
 	nonceSize := gcm.NonceSize()
+	if len(data) < nonceSize {
+		return nil, newError(ErrWrongPassphrase, "Unable to decrypt sync file. Wrong passphrase or file is not encrypted.")
+	}
 	nonce, ciphertext := data[:nonceSize], data[nonceSize:]
 	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
 	if err != nil {
-		panic(err.Error())
+		return nil, newError(ErrWrongPassphrase, "Unable to decrypt sync file. Wrong passphrase or file is not encrypted.")
 	}
-	return plaintext
+	return plaintext, nil
 }
 
-func encryptFile(srcFilename string, dstFilename string, passphrase string) bool {
-	data, _ := ioutil.ReadFile(srcFilename)
-	f, _ := os.Create(dstFilename)
-	defer f.Close()
-	_, err := f.Write(encrypt(data, passphrase))
-	return err != nil
+func encryptFile(srcFilename string, dstFilename string, passphrase string) error {
+	data, err := ioutil.ReadFile(srcFilename)
+	if err != nil {
+		return err
+	}
+	encrypted, err := encrypt(data, passphrase)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(dstFilename, encrypted, 0644)
 }
 
-func decryptFile(srcFilename string, dstFilename string, passphrase string) bool {
+//Decrypt the source file to the destination. A missing or empty source (nothing synced yet) is not an error.
+func decryptFile(srcFilename string, dstFilename string, passphrase string) error {
 	data, _ := ioutil.ReadFile(srcFilename)
 	if len(data) == 0 {
-		return true
+		return nil
+	}
+	decrypted, err := decrypt(data, passphrase)
+	if err != nil {
+		return err
 	}
-	decrypted := decrypt(data, passphrase)
-	err := ioutil.WriteFile(dstFilename, decrypted, os.FileMode(os.O_RDWR))
-	return err != nil
+	return ioutil.WriteFile(dstFilename, decrypted, os.FileMode(os.O_RDWR))
 }
 
 func passphraseInput() string {
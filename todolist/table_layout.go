@@ -0,0 +1,240 @@
+package todolist
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+
+	runewidth "github.com/mattn/go-runewidth"
+)
+
+//Narrowest the flex column is shrunk to, so it stays readable on a narrow terminal
+const minFlexWidth = 10
+
+var ansiRegex = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+//Lays out rows of (colored) cells in columns that fit the terminal. Unlike tabwriter, widths are measured as
+//displayed: color codes take no space and wide runes (e.g. CJK) take two. If the rows are wider than Width, the
+//flex column (usually the subject) is narrowed, and cells wider than their column are truncated or wrapped.
+type TableLayout struct {
+	Width     int   //Width available. 0 or less for no limit (e.g. output piped to a file).
+	MaxWidths []int //Max width per column. 0 for no limit.
+	Flex      int   //Column narrowed to fit Width. -1 for the last column.
+	Wrap      bool  //Wrap cells wider than their column onto more lines rather than truncate them
+	lines     []layoutLine
+}
+
+type layoutLine struct {
+	cells []string //Nil for a line of text
+	text  string
+	span  bool //Text is indented to the flex column and wrapped within it
+}
+
+func NewTableLayout(width int, maxWidths []int, flex int, wrap bool) *TableLayout {
+	return &TableLayout{Width: width, MaxWidths: maxWidths, Flex: flex, Wrap: wrap}
+}
+
+//Add a row of cells, aligned in columns
+func (l *TableLayout) AddRow(cells ...string) {
+	l.lines = append(l.lines, layoutLine{cells: cells})
+}
+
+//Add a line of text printed as is, e.g. a group heading
+func (l *TableLayout) AddText(text string) {
+	l.lines = append(l.lines, layoutLine{text: text})
+}
+
+//Add a line of text under the flex column, e.g. a note under the subject. Wrapped to fit.
+func (l *TableLayout) AddSpan(text string) {
+	l.lines = append(l.lines, layoutLine{text: text, span: true})
+}
+
+func (l *TableLayout) Write(w io.Writer) error {
+	_, err := io.WriteString(w, l.String())
+	return err
+}
+
+func (l *TableLayout) String() string {
+	widths := l.ColumnWidths()
+	flex := l.flexColumn(len(widths))
+	var buf bytes.Buffer
+	for _, line := range l.lines {
+		if line.cells == nil && !line.span {
+			buf.WriteString(line.text)
+			buf.WriteString("\n")
+			continue
+		}
+		if line.span {
+			indent := 0
+			for i := 0; i < flex && i < len(widths); i++ {
+				indent += widths[i] + 1
+			}
+			width := 0
+			if l.Width > 0 {
+				width = l.Width - indent
+				if width < minFlexWidth {
+					width = minFlexWidth
+				}
+			}
+			for _, text := range fitCell(line.text, width, true) {
+				buf.WriteString(strings.Repeat(" ", indent))
+				buf.WriteString(text)
+				buf.WriteString("\n")
+			}
+			continue
+		}
+		l.writeRow(&buf, line.cells, widths)
+	}
+	return buf.String()
+}
+
+//A row takes more than one line if a cell is wrapped
+func (l *TableLayout) writeRow(buf *bytes.Buffer, cells []string, widths []int) {
+	fitted := [][]string{}
+	height := 1
+	for i, cell := range cells {
+		cellLines := fitCell(cell, widths[i], l.Wrap)
+		if len(cellLines) > height {
+			height = len(cellLines)
+		}
+		fitted = append(fitted, cellLines)
+	}
+	for j := 0; j < height; j++ {
+		var line bytes.Buffer
+		for i, cellLines := range fitted {
+			piece := ""
+			if j < len(cellLines) {
+				piece = cellLines[j]
+			}
+			line.WriteString(piece)
+			if i < len(fitted)-1 {
+				line.WriteString(strings.Repeat(" ", widths[i]-VisibleWidth(piece)+1))
+			}
+		}
+		buf.WriteString(strings.TrimRight(line.String(), " "))
+		buf.WriteString("\n")
+	}
+}
+
+//Width of each column: the widest cell, limited by MaxWidths, with the flex column narrowed to fit Width
+func (l *TableLayout) ColumnWidths() []int {
+	widths := []int{}
+	for _, line := range l.lines {
+		for i, cell := range line.cells {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if w := VisibleWidth(cell); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+	total := 0
+	for i := range widths {
+		if i < len(l.MaxWidths) && l.MaxWidths[i] > 0 && widths[i] > l.MaxWidths[i] {
+			widths[i] = l.MaxWidths[i]
+		}
+		total += widths[i] + 1
+	}
+	total-- //no space after the last column
+	if l.Width > 0 && total > l.Width && len(widths) > 0 {
+		flex := l.flexColumn(len(widths))
+		available := l.Width - (total - widths[flex])
+		if available < minFlexWidth {
+			available = minFlexWidth
+		}
+		if available < widths[flex] {
+			widths[flex] = available
+		}
+	}
+	return widths
+}
+
+func (l *TableLayout) flexColumn(cols int) int {
+	if l.Flex < 0 || l.Flex >= cols {
+		return cols - 1
+	}
+	return l.Flex
+}
+
+//Width of the text as displayed, ignoring color codes
+func VisibleWidth(s string) int {
+	return runewidth.StringWidth(ansiRegex.ReplaceAllString(s, ""))
+}
+
+//Split a cell into lines no wider than width, keeping its color. Cells are colored as a whole, so the color codes
+//at the start and end are kept and any in between are dropped.
+func fitCell(cell string, width int, wrap bool) []string {
+	if width <= 0 || VisibleWidth(cell) <= width {
+		return []string{cell}
+	}
+	prefix := ""
+	if loc := ansiRegex.FindStringIndex(cell); loc != nil && loc[0] == 0 {
+		for loc != nil && loc[0] == 0 {
+			prefix += cell[:loc[1]]
+			cell = cell[loc[1]:]
+			loc = ansiRegex.FindStringIndex(cell)
+		}
+	}
+	plain := ansiRegex.ReplaceAllString(cell, "")
+	suffix := ""
+	if prefix != "" {
+		suffix = "\x1b[0m"
+	}
+	if !wrap {
+		return []string{prefix + runewidth.Truncate(plain, width, "…") + suffix}
+	}
+	lines := []string{}
+	for _, line := range wrapText(plain, width) {
+		lines = append(lines, prefix+line+suffix)
+	}
+	return lines
+}
+
+//Word wrap to lines no wider than width. Words longer than width are split.
+func wrapText(text string, width int) []string {
+	lines := []string{}
+	line := ""
+	for _, word := range strings.Fields(text) {
+		for runewidth.StringWidth(word) > width {
+			//Fill the rest of the line, or a line of its own, with the start of the long word
+			room := width
+			if line != "" {
+				room = width - runewidth.StringWidth(line) - 1
+			}
+			head := runewidth.Truncate(word, room, "")
+			if head == "" {
+				if line == "" {
+					head = string([]rune(word)[:1]) //rune wider than the column
+				} else {
+					lines = append(lines, line)
+					line = ""
+					continue
+				}
+			}
+			if line != "" {
+				lines = append(lines, line+" "+head)
+			} else {
+				lines = append(lines, head)
+			}
+			line = ""
+			word = word[len(head):]
+		}
+		if word == "" {
+			continue
+		}
+		if line == "" {
+			line = word
+		} else if runewidth.StringWidth(line)+1+runewidth.StringWidth(word) <= width {
+			line += " " + word
+		} else {
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" || len(lines) == 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,38 @@
+package todolist
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableLayout(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(5, VisibleWidth("\x1b[32mhello\x1b[0m"))
+	assert.Equal(4, VisibleWidth("日本"))
+
+	//Colored cells align as plain ones and the subject is truncated to fit
+	layout := NewTableLayout(20, nil, 1, false)
+	layout.AddRow("Id", "Subject")
+	layout.AddRow("\x1b[32m1\x1b[0m", "Write the quarterly report")
+	layout.AddRow("12", "日本語のタスク")
+	assert.Equal("Id Subject\n"+
+		"\x1b[32m1\x1b[0m  Write the quarte…\n"+
+		"12 日本語のタスク\n", layout.String())
+
+	//Wrapped, with a note under the subject
+	layout = NewTableLayout(20, nil, 1, true)
+	layout.AddRow("1", "Write the quarterly report", "x")
+	layout.AddSpan("- ask Bob")
+	assert.Equal("1 Write the        x\n"+
+		"  quarterly report\n"+
+		"  - ask Bob\n", layout.String())
+
+	//Max widths apply without a terminal
+	layout = NewTableLayout(0, []int{0, 4}, -1, false)
+	layout.AddRow("Due", "Project", "Subject")
+	layout.AddRow("today", "Acme", "Write a much longer subject than fits")
+	assert.Equal([]int{5, 4, 37}, layout.ColumnWidths())
+	assert.Contains(layout.String(), "today Acme Write a much longer subject than fits\n")
+	assert.Contains(layout.String(), "Due   Pro… Subject\n")
+}
@@ -0,0 +1,185 @@
+package todolist
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+//Report elements colored on their own, with the ScreenPrinter color used when not configured
+var ColorElements = []string{"header", "label", "id", "status", "started", "subject", "due", "late", "date", "project", "context", "tag", "priority", "number", "spent", "pomodoro",
+	"calendar.today", "calendar.due", "calendar.urgent", "calendar.overdue"}
+
+//Rules color a whole row. The first rule (in precedence order) that matches the todo and has a color wins.
+var DefaultColorPrecedence = []string{"active", "overdue", "due.today", "priority", "tag", "project"}
+
+var colorNames = map[string]color.Attribute{
+	"black":   color.FgBlack,
+	"red":     color.FgRed,
+	"green":   color.FgGreen,
+	"yellow":  color.FgYellow,
+	"blue":    color.FgBlue,
+	"magenta": color.FgMagenta,
+	"cyan":    color.FgCyan,
+	"white":   color.FgWhite,
+}
+
+//Parse a color spec like 'bold red', 'bright cyan', 'black on yellow', 'reverse' or 'none' into a color function
+func ParseColorSpec(spec string) (func(a ...interface{}) string, error) {
+	words := strings.Fields(strings.ToLower(spec))
+	if len(words) == 0 || (len(words) == 1 && words[0] == "none") {
+		return fmt.Sprint, nil
+	}
+	attrs := []color.Attribute{}
+	bright, background := false, false
+	for _, word := range words {
+		switch word {
+		case "bold":
+			attrs = append(attrs, color.Bold)
+		case "italic":
+			attrs = append(attrs, color.Italic)
+		case "underline":
+			attrs = append(attrs, color.Underline)
+		case "reverse":
+			attrs = append(attrs, color.ReverseVideo)
+		case "bright":
+			bright = true
+		case "on":
+			background = true
+		default:
+			attr, ok := colorNames[word]
+			if !ok {
+				return nil, errors.New("Unknown color: " + word)
+			}
+			if bright {
+				attr += color.FgHiBlack - color.FgBlack
+			}
+			if background {
+				attr += color.BgBlack - color.FgBlack
+			}
+			attrs = append(attrs, attr)
+			bright = false
+		}
+	}
+	return color.New(attrs...).SprintFunc(), nil
+}
+
+//A config key (less 'color.') is valid if it names an element or a rule
+func isColorKey(key string) bool {
+	for _, elem := range ColorElements {
+		if key == elem {
+			return true
+		}
+	}
+	return key == "active" || key == "overdue" || key == "due.today" ||
+		strings.HasPrefix(key, "priority.") || strings.HasPrefix(key, "tag.") || strings.HasPrefix(key, "project.")
+}
+
+//Turn colors off (color=off or NO_COLOR, e.g. when piping) or force them on (color=on, e.g. for less -R)
+func SetColorOutput(value string) error {
+	off, err := parseColorOutput(value)
+	if err != nil {
+		return err
+	}
+	color.NoColor = off
+	return nil
+}
+
+//Whether the color setting turns colors off
+func parseColorOutput(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "off", "false", "no", "0":
+		return true, nil
+	case "on", "true", "yes", "1":
+		return false, nil
+	}
+	return false, errors.New("Expected on or off")
+}
+
+//Apply the configured colors to a printer
+func (f *ScreenPrinter) applyColors(colors map[string]string) {
+	f.colors = map[string]func(a ...interface{}) string{}
+	f.precedence = DefaultColorPrecedence
+	for key, spec := range colors {
+		if key == "precedence" {
+			f.precedence = strings.Split(strings.Replace(spec, " ", "", -1), ",")
+			continue
+		}
+		if c, err := ParseColorSpec(spec); err == nil {
+			f.colors[key] = c
+		}
+	}
+}
+
+//Color of a report element, configured or the default
+func (f *ScreenPrinter) color(element string) func(a ...interface{}) string {
+	if c, ok := f.colors[element]; ok {
+		return c
+	}
+	switch element {
+	case "header", "started":
+		return f.fgGreen
+	case "label", "id", "date", "number":
+		return f.fgYellow
+	case "status", "subject":
+		return f.fgWhite
+	case "due":
+		return f.fgBlue
+	case "late", "context", "priority", "pomodoro":
+		return f.fgRed
+	case "project":
+		return f.fgMagenta
+	case "tag", "spent":
+		return f.fgCyan
+	case "calendar.today":
+		return color.New(color.ReverseVideo).SprintFunc()
+	case "calendar.due":
+		return f.color("due")
+	case "calendar.urgent":
+		return f.fgYellow
+	case "calendar.overdue":
+		return f.color("late")
+	}
+	return fmt.Sprint
+}
+
+//Color of the whole row for a todo, from the first matching rule. Nil if none match.
+func (f *ScreenPrinter) rowColor(todo *Todo) func(a ...interface{}) string {
+	for _, rule := range f.precedence {
+		keys := []string{}
+		switch rule {
+		case "active":
+			if todo.IsActive() {
+				keys = append(keys, rule)
+			}
+		case "overdue":
+			if todo.IsOverdue(f.Clock.Now()) {
+				keys = append(keys, rule)
+			}
+		case "due.today":
+			if todo.IsDueToday(f.Clock.Now()) && !todo.Completed {
+				keys = append(keys, rule)
+			}
+		case "priority":
+			if todo.Priority != "" {
+				keys = append(keys, "priority."+todo.Priority)
+			}
+		case "tag":
+			for _, tag := range todo.Tags {
+				keys = append(keys, "tag."+tag)
+			}
+		case "project":
+			for _, project := range todo.Projects {
+				keys = append(keys, "project."+project)
+			}
+		}
+		for _, key := range keys {
+			if c, ok := f.colors[key]; ok {
+				return c
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package todolist
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColorTheme(t *testing.T) {
+	assert := assert.New(t)
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+	clock := FixedClock{Time: time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)}
+
+	c, err := ParseColorSpec("bold bright cyan on blue")
+	assert.Nil(err)
+	assert.Equal("\x1b[1;96;44mx\x1b[0m", c("x"))
+	c, _ = ParseColorSpec("none")
+	assert.Equal("x", c("x"))
+	_, err = ParseColorSpec("purple")
+	assert.NotNil(err)
+
+	p := NewScreenPrinter(clock, map[string]string{"id": "green", "overdue": "red", "project.Acme": "blue", "priority.H": "magenta"})
+	cols := []string{"id", "subject"}
+	assert.Equal([]string{"\x1b[32m1\x1b[0m", "\x1b[37;1mplain\x1b[0m"}, p.customTodoCells(&Todo{Id: 1, Subject: "plain"}, cols, nil))
+
+	//Overdue takes precedence over priority, which takes precedence over project
+	late := &Todo{Id: 2, Subject: "late", Due: "2026-10-12T00:00:00Z", Priority: "H", Projects: []string{"Acme"}}
+	assert.Equal([]string{"\x1b[31m2\x1b[0m", "\x1b[31mlate\x1b[0m"}, p.customTodoCells(late, cols, nil))
+	late.Due = ""
+	assert.Equal("\x1b[35mlate\x1b[0m", p.customTodoCells(late, cols, nil)[1])
+	late.Priority = ""
+	assert.Equal("\x1b[34mlate\x1b[0m", p.customTodoCells(late, cols, nil)[1])
+
+	assert.Nil(SetColorOutput("off"))
+	assert.Equal([]string{"2", "late"}, p.customTodoCells(late, cols, nil))
+	assert.NotNil(SetColorOutput("maybe"))
+}
@@ -0,0 +1,182 @@
+package todolist
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+//Time spent on todos (recorded with start and stop), summed by group (project or context) and period (day, week or month)
+type Timesheet struct {
+	Sum     int         //0 daily, 1 weekly, 2 monthly. Same as stats.
+	Groups  []string    //Sorted row labels
+	Periods []time.Time //Sorted, contiguous period start dates
+	Spent   map[string]map[time.Time]time.Duration
+}
+
+func NewTimesheet(todos []*Todo, groupBy string, sum int, rangeTimes []time.Time, clock Clock) *Timesheet {
+	ts := &Timesheet{Sum: sum, Spent: map[string]map[time.Time]time.Duration{}}
+	now := clock.Now()
+	var startDate, endDate time.Time
+	if len(rangeTimes) > 0 {
+		startDate = rangeTimes[0]
+		endDate = now
+		if len(rangeTimes) > 1 {
+			//e.g. range:last_week:this_week translates to the begin and end of each week
+			endDate = rangeTimes[len(rangeTimes)-1]
+		}
+	}
+	for _, todo := range todos {
+		group := timesheetGroup(todo, groupBy)
+		for _, interval := range todo.TimeIntervals() {
+			start := stringToTime(interval.Start)
+			if len(rangeTimes) > 0 && (start.Before(startDate) || !start.Before(endDate)) {
+				continue
+			}
+			periods, ok := ts.Spent[group]
+			if !ok {
+				periods = map[time.Time]time.Duration{}
+				ts.Spent[group] = periods
+			}
+			periods[periodStart(start, sum)] += interval.Duration(now)
+		}
+	}
+
+	for group := range ts.Spent {
+		ts.Groups = append(ts.Groups, group)
+	}
+	sort.Strings(ts.Groups)
+
+	//Include every period in the range (or between the first and last recorded time), even if no time was spent
+	var first, last time.Time
+	if len(rangeTimes) > 0 {
+		first = periodStart(startDate, sum)
+		last = periodStart(endDate.Add(-time.Second), sum)
+	} else {
+		for _, periods := range ts.Spent {
+			for p := range periods {
+				if first.IsZero() || p.Before(first) {
+					first = p
+				}
+				if last.IsZero() || p.After(last) {
+					last = p
+				}
+			}
+		}
+	}
+	if first.IsZero() {
+		return ts
+	}
+	for p := first; !p.After(last); p = nextPeriod(p, sum) {
+		ts.Periods = append(ts.Periods, p)
+	}
+	return ts
+}
+
+//The times for range:start[:end]. The end date is included, as in agenda. A period, like this_week, already ends
+//where the next begins.
+func timesheetRange(now time.Time, vals []string) ([]time.Time, error) {
+	times, err := translateToDates(now, vals...)
+	if err != nil || len(vals) < 2 || vals[len(vals)-1] == "" {
+		return times, err
+	}
+	if end, _ := translateToDates(now, vals[len(vals)-1]); len(end) == 1 {
+		times[len(times)-1] = bod(end[0]).AddDate(0, 0, 1)
+	}
+	return times, nil
+}
+
+func timesheetGroup(todo *Todo, groupBy string) string {
+	groupBy = strings.ToLower(groupBy)
+	if strings.HasPrefix(groupBy, "a") {
+		return "all"
+	} else if strings.HasPrefix(groupBy, "c") {
+		return strings.Join(todo.Contexts, ",")
+	}
+	return strings.Join(todo.Projects, ",")
+}
+
+func nextPeriod(p time.Time, sum int) time.Time {
+	switch sum {
+	case 1:
+		return p.AddDate(0, 0, 7)
+	case 2:
+		return p.AddDate(0, 1, 0)
+	}
+	return p.AddDate(0, 0, 1)
+}
+
+func (ts *Timesheet) GroupTotal(group string) time.Duration {
+	var total time.Duration
+	for _, spent := range ts.Spent[group] {
+		total += spent
+	}
+	return total
+}
+
+func (ts *Timesheet) PeriodTotal(period time.Time) time.Duration {
+	var total time.Duration
+	for _, periods := range ts.Spent {
+		total += periods[period]
+	}
+	return total
+}
+
+func (ts *Timesheet) Total() time.Duration {
+	var total time.Duration
+	for group := range ts.Spent {
+		total += ts.GroupTotal(group)
+	}
+	return total
+}
+
+//Label for a period column, e.g. Mon 10-12, Week 42 or Oct 2026
+func (ts *Timesheet) PeriodLabel(p time.Time) string {
+	switch ts.Sum {
+	case 1:
+		_, week := p.ISOWeek()
+		return fmt.Sprintf("Week %d", week)
+	case 2:
+		return p.Format("Jan 2006")
+	}
+	return p.Format("Mon 01-02")
+}
+
+//Write the timesheet as CSV with hours in decimal (e.g. 1.50), suitable for billing
+func (ts *Timesheet) WriteCSV(w io.Writer) error {
+	hours := func(d time.Duration) string {
+		return fmt.Sprintf("%.2f", d.Hours())
+	}
+	writer := csv.NewWriter(w)
+	header := []string{"Group"}
+	for _, p := range ts.Periods {
+		header = append(header, timeToSimpleDateString(p))
+	}
+	header = append(header, "Total")
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, group := range ts.Groups {
+		row := []string{group}
+		for _, p := range ts.Periods {
+			row = append(row, hours(ts.Spent[group][p]))
+		}
+		row = append(row, hours(ts.GroupTotal(group)))
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	row := []string{"Total"}
+	for _, p := range ts.Periods {
+		row = append(row, hours(ts.PeriodTotal(p)))
+	}
+	row = append(row, hours(ts.Total()))
+	if err := writer.Write(row); err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
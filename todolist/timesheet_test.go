@@ -0,0 +1,64 @@
+package todolist
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimesheet(t *testing.T) {
+	assert := assert.New(t)
+	clock := FixedClock{Time: time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)}
+	day := func(d, h int) string {
+		return timeToString(time.Date(2026, 10, d, h, 0, 0, 0, time.UTC))
+	}
+
+	todos := []*Todo{
+		&Todo{Id: 1, Projects: []string{"Acme"}, Intervals: []*Interval{
+			&Interval{Start: day(12, 9), End: day(12, 11)},
+			&Interval{Start: day(13, 9), End: day(13, 10)},
+		}},
+		&Todo{Id: 2, Projects: []string{"Beta"}, Intervals: []*Interval{
+			&Interval{Start: day(12, 13), End: day(12, 14)},
+			&Interval{Start: day(14, 9), End: day(14, 10)},
+			&Interval{Start: day(1, 9), End: day(1, 10)}, //outside range
+		}},
+	}
+
+	//The end date is included
+	rangeTimes, err := timesheetRange(clock.Now(), []string{"2026-10-12", "2026-10-14"})
+	assert.Nil(err)
+	ts := NewTimesheet(todos, "pro", 0, rangeTimes, clock)
+	assert.Equal([]string{"Acme", "Beta"}, ts.Groups)
+	assert.Equal(3, len(ts.Periods))
+	assert.Equal(3*time.Hour, ts.GroupTotal("Acme"))
+	assert.Equal(3*time.Hour, ts.PeriodTotal(ts.Periods[0]))
+	assert.Equal(5*time.Hour, ts.Total())
+
+	var buf bytes.Buffer
+	assert.Nil(ts.WriteCSV(&buf))
+	assert.Equal("Group,2026-10-12,2026-10-13,2026-10-14,Total\nAcme,2.00,1.00,0.00,3.00\nBeta,1.00,0.00,1.00,2.00\nTotal,3.00,1.00,1.00,5.00\n", buf.String())
+
+	//A period ends where the next begins
+	rangeTimes, _ = timesheetRange(clock.Now(), []string{"last_week", "this_week"})
+	assert.Equal(bow(clock.Now()).AddDate(0, 0, 7), rangeTimes[len(rangeTimes)-1])
+}
+
+func TestPomodoros(t *testing.T) {
+	assert := assert.New(t)
+	clock := FixedClock{Time: time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)}
+	now := clock.Now()
+
+	todo := &Todo{Id: 1, Projects: []string{"Acme"}, CreatedDate: timeToString(now), ModifiedDate: timeToString(now)}
+	todo.AddPomodoro(now.Add(-25*time.Minute), now)
+	assert.Equal(1, todo.Pomodoros())
+	assert.Equal(25*time.Minute, todo.TimeSpent(now))
+	assert.Equal(1, len(todo.Notes))
+
+	statsData := &StatsData{Groups: map[string]*StatsGroup{}, Clock: clock}
+	statsData.CalcStats([]*Todo{todo}, "p", 0, nil)
+	assert.Equal(1, statsData.Groups["Acme"].Stats[0].Pomodoros)
+	assert.Equal(25*time.Minute, statsData.Groups["Acme"].Stats[0].Spent)
+}
@@ -0,0 +1,227 @@
+package todolist
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+//Human editable document of todos used by the edit-full command. One block per todo:
+//
+//	## Todo 3
+//	subject: Write the report
+//	projects: Work
+//	contexts: office
+//	tags: review
+//	due: 2018-12-31
+//	scheduled: 2018-12-28
+//	wait:
+//	until:
+//	priority: H
+//	effort: 2d
+//	notes:
+//	  1: first note
+//	  2: second note
+type TodoEditBlock struct {
+	Id        int
+	Subject   string
+	Projects  []string
+	Contexts  []string
+	Tags      []string
+	Due       string
+	Scheduled string
+	Wait      string
+	Until     string
+	Priority  string
+	Effort    string
+	Notes     []string
+}
+
+const editHeader = "# Edit the todos below and save. Lines starting with a single # are ignored.\n" +
+	"# Dates are yyyy-mm-dd or any date accepted on the command line (e.g. tom, fri, 3d). Leave blank to remove.\n" +
+	"# Projects, contexts and tags are space separated. Notes are numbered; add, remove or reword them freely.\n\n"
+
+var editBlockRegex = regexp.MustCompile(`^##\s+Todo\s+(\d+)\s*$`)
+var editNoteRegex = regexp.MustCompile(`^\s+\d+:\s?(.*)$`)
+
+func NewTodoEditBlock(todo *Todo) *TodoEditBlock {
+	b := &TodoEditBlock{
+		Id:        todo.Id,
+		Subject:   todo.Subject,
+		Projects:  todo.Projects,
+		Contexts:  todo.Contexts,
+		Tags:      todo.Tags,
+		Due:       editDate(todo.Due),
+		Scheduled: editDate(todo.Scheduled),
+		Wait:      editDate(todo.Wait),
+		Until:     editDate(todo.Until),
+		Priority:  todo.Priority,
+		Notes:     todo.Notes,
+	}
+	if todo.EffortDays > 0 {
+		b.Effort = strconv.FormatFloat(todo.EffortDays, 'f', -1, 64) + "d"
+	}
+	return b
+}
+
+func editDate(date string) string {
+	if date == "" {
+		return ""
+	}
+	return timeToSimpleDateString(stringToTime(date))
+}
+
+func FormatTodosForEdit(todos []*Todo) string {
+	var sb strings.Builder
+	sb.WriteString(editHeader)
+	for _, todo := range todos {
+		b := NewTodoEditBlock(todo)
+		fmt.Fprintf(&sb, "## Todo %d\n", b.Id)
+		fmt.Fprintf(&sb, "subject: %s\n", b.Subject)
+		fmt.Fprintf(&sb, "projects: %s\n", strings.Join(b.Projects, " "))
+		fmt.Fprintf(&sb, "contexts: %s\n", strings.Join(b.Contexts, " "))
+		fmt.Fprintf(&sb, "tags: %s\n", strings.Join(b.Tags, " "))
+		fmt.Fprintf(&sb, "due: %s\n", b.Due)
+		fmt.Fprintf(&sb, "scheduled: %s\n", b.Scheduled)
+		fmt.Fprintf(&sb, "wait: %s\n", b.Wait)
+		fmt.Fprintf(&sb, "until: %s\n", b.Until)
+		fmt.Fprintf(&sb, "priority: %s\n", b.Priority)
+		fmt.Fprintf(&sb, "effort: %s\n", b.Effort)
+		sb.WriteString("notes:\n")
+		for i, note := range b.Notes {
+			fmt.Fprintf(&sb, "  %d: %s\n", i, note)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func ParseEditedTodos(text string) ([]*TodoEditBlock, error) {
+	blocks := []*TodoEditBlock{}
+	var b *TodoEditBlock
+	inNotes := false
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		if matches := editBlockRegex.FindStringSubmatch(line); matches != nil {
+			id, _ := strconv.Atoi(matches[1])
+			b = &TodoEditBlock{Id: id}
+			blocks = append(blocks, b)
+			inNotes = false
+			continue
+		}
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if b == nil {
+			return nil, fmt.Errorf("line %d: expected '## Todo <id>' before %q", lineNum, line)
+		}
+		if inNotes {
+			if matches := editNoteRegex.FindStringSubmatch(line); matches != nil {
+				b.Notes = append(b.Notes, matches[1])
+				continue
+			}
+		}
+		colon := strings.Index(line, ":")
+		if colon < 0 {
+			return nil, fmt.Errorf("line %d: expected 'field: value' but found %q", lineNum, line)
+		}
+		value := strings.TrimSpace(line[colon+1:])
+		inNotes = false
+		switch strings.ToLower(strings.TrimSpace(line[:colon])) {
+		case "subject":
+			b.Subject = value
+		case "projects":
+			b.Projects = strings.Fields(value)
+		case "contexts":
+			b.Contexts = strings.Fields(value)
+		case "tags":
+			b.Tags = strings.Fields(value)
+		case "due":
+			b.Due = value
+		case "scheduled":
+			b.Scheduled = value
+		case "wait":
+			b.Wait = value
+		case "until":
+			b.Until = value
+		case "priority":
+			b.Priority = value
+		case "effort":
+			b.Effort = value
+		case "notes":
+			inNotes = true
+		default:
+			return nil, fmt.Errorf("line %d: unknown field %q", lineNum, line[:colon])
+		}
+	}
+	return blocks, scanner.Err()
+}
+
+// Modifications (as accepted by the edit command) that turn the original block into the edited block.
+// The subject and notes are not included, since they may contain text that reads as a modification.
+func (b *TodoEditBlock) Mods(orig *TodoEditBlock) []string {
+	mods := []string{}
+	mods = append(mods, diffSet(orig.Projects, b.Projects, "+", "-")...)
+	mods = append(mods, diffSet(orig.Contexts, b.Contexts, "@", "-@")...)
+	mods = append(mods, diffSet(orig.Tags, b.Tags, "#", "-#")...)
+	if b.Due != orig.Due {
+		mods = append(mods, "due:"+b.Due)
+	}
+	if b.Scheduled != orig.Scheduled {
+		mods = append(mods, "scheduled:"+b.Scheduled)
+	}
+	if b.Wait != orig.Wait {
+		mods = append(mods, "wait:"+b.Wait)
+	}
+	if b.Until != orig.Until {
+		mods = append(mods, "until:"+b.Until)
+	}
+	if b.Priority != orig.Priority {
+		mods = append(mods, "pri:"+b.Priority)
+	}
+	if b.Effort != orig.Effort {
+		mods = append(mods, "effort:"+b.Effort)
+	}
+	return mods
+}
+
+func diffSet(orig []string, edited []string, addPrefix string, removePrefix string) []string {
+	mods := []string{}
+	for _, val := range orig {
+		if !contains(edited, val) {
+			mods = append(mods, removePrefix+val)
+		}
+	}
+	for _, val := range edited {
+		if !contains(orig, val) {
+			mods = append(mods, addPrefix+val)
+		}
+	}
+	return mods
+}
+
+func contains(vals []string, val string) bool {
+	for _, v := range vals {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
+
+func equalNotes(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,46 @@
+package todolist
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEditFullRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Now()
+
+	todo := &Todo{Id: 3, Subject: "write report", Projects: []string{"Work"}, Contexts: []string{"office"},
+		Priority: "H", EffortDays: 2, Due: timeToString(bod(now)), Notes: []string{"first", "second"}}
+	blocks, err := ParseEditedTodos(FormatTodosForEdit([]*Todo{todo}))
+	assert.Nil(err)
+	assert.Equal(1, len(blocks))
+	assert.Equal(todo.Subject, blocks[0].Subject)
+	assert.Equal(todo.Notes, blocks[0].Notes)
+	assert.Equal("2d", blocks[0].Effort)
+	assert.Equal([]string{}, blocks[0].Mods(NewTodoEditBlock(todo)))
+}
+
+func TestEditFullMods(t *testing.T) {
+	assert := assert.New(t)
+	todo := &Todo{Id: 3, Subject: "write report", Projects: []string{"Work"}, Tags: []string{"a"}, Due: "2018-12-31T00:00:00Z", Notes: []string{"first"}, Ordinals: map[string]int{}}
+	text := "## Todo 3\nsubject: write the report\nprojects: Home\ntags: a b\ndue:\npriority: M\nnotes:\n  0: first\n  1: another: note\n"
+	blocks, err := ParseEditedTodos(text)
+	assert.Nil(err)
+	assert.Equal("write the report", blocks[0].Subject)
+	assert.Equal([]string{"first", "another: note"}, blocks[0].Notes)
+	assert.Equal([]string{"-Work", "+Home", "#b", "due:", "pri:M"}, blocks[0].Mods(NewTodoEditBlock(todo)))
+
+	list := &TodoList{}
+	list.Load([]*Todo{todo})
+	list.Edit(blocks[0].Mods(NewTodoEditBlock(todo)), todo)
+	assert.Equal([]string{"Home"}, todo.Projects)
+	assert.Equal("", todo.Due)
+	assert.Equal("M", todo.Priority)
+
+	_, err = ParseEditedTodos("subject: orphan\n")
+	assert.NotNil(err)
+	_, err = ParseEditedTodos("## Todo 1\ncolour: red\n")
+	assert.NotNil(err)
+}
@@ -9,37 +9,52 @@ import (
 
 type ToDoFilter struct {
 	Todos []*Todo
+	Clock Clock   //Date filters and virtual tags (e.g. OVERDUE) are relative to now on this clock
+	all   []*Todo //Unfiltered todos. Needed to compute virtual tags (e.g. BLOCKED)
 }
 
-func NewToDoFilter(todos []*Todo) *ToDoFilter {
-	return &ToDoFilter{Todos: todos}
+func NewToDoFilter(todos []*Todo, clock Clock) *ToDoFilter {
+	return &ToDoFilter{Todos: todos, Clock: clock, all: todos}
 }
 
-func (f *ToDoFilter) Filter(filters []string) []*Todo {
+//Returns ErrBadDate if the date of a date filter (e.g. due:) can't be parsed
+func (f *ToDoFilter) Filter(filters []string) ([]*Todo, error) {
 
+	var err error
 	numTodos := len(f.Todos)
 	//fmt.Println("filters before IDs: ", filters)
 	f.Todos, filters = f.filterIDs(filters)
 	//fmt.Println("filters after IDs: ", filters)
 	//If matched specific id numbers, ignore the waiting filter. Presumably user intended to operate on the specific todo(s)
 	if len(f.Todos) == numTodos {
-		f.Todos, filters = NewDateFilter(f.Todos).FilterWaiting(filters)
+		f.Todos, filters = NewDateFilter(f.Todos, f.Clock).FilterWaiting(filters)
 	}
 	//fmt.Println("filters after wait: ", filters)
 	f.Todos, filters = f.filterArchived(filters) //includes filter for completed OR filter for archived
 	//fmt.Println("filters after archive: ", filters)
-	f.Todos, filters = NewDateFilter(f.Todos).FilterDoneDate(filters) //filter by completed date
+	if f.Todos, filters, err = NewDateFilter(f.Todos, f.Clock).FilterDoneDate(filters); err != nil { //filter by completed date
+		return nil, err
+	}
 	//fmt.Println("filters after done/completed: ", filters)
-	f.Todos, filters = NewDateFilter(f.Todos).FilterModDate(filters) //filter by completed date
+	if f.Todos, filters, err = NewDateFilter(f.Todos, f.Clock).FilterModDate(filters); err != nil { //filter by completed date
+		return nil, err
+	}
 	//fmt.Println("filters after modified: ", filters)
-	f.Todos, filters = NewDateFilter(f.Todos).FilterAge(filters) //filter by create date
+	f.Todos, filters = NewDateFilter(f.Todos, f.Clock).FilterAge(filters) //filter by create date
 	//fmt.Println("filters after age: ", filters)
-	f.Todos, filters = NewDateFilter(f.Todos).FilterDueDate(filters) //filter by due date
+	if f.Todos, filters, err = NewDateFilter(f.Todos, f.Clock).FilterDueDate(filters); err != nil { //filter by due date
+		return nil, err
+	}
+	if f.Todos, filters, err = NewDateFilter(f.Todos, f.Clock).FilterScheduledDate(filters); err != nil { //filter by scheduled date
+		return nil, err
+	}
 	//fmt.Println("filters after due: ", filters)
 	f.Todos, filters = f.FilterEffort(filters) //filter by effort
 	//fmt.Println("filters after effort: ", filters)
 	f.Todos, filters = f.filterPrioritized(filters)
 	//fmt.Println("filters after priority: ", filters)
+	f.Todos, filters = f.filterTags(filters)
+	//fmt.Println("filters after tags: ", filters)
 	f.Todos, filters = f.filterProjects(filters)
 	//fmt.Println("filters after project: ", filters)
 	f.Todos, filters = f.filterContexts(filters)
@@ -50,7 +65,7 @@ func (f *ToDoFilter) Filter(filters []string) []*Todo {
 	//fmt.Println("filters after TopN: ", filters)
 	f.Todos = f.filterSubject(filters)
 	//fmt.Println("filters after Subject: ", filters)
-	return f.Todos
+	return f.Todos, nil
 }
 
 func (f *ToDoFilter) FilterEffort(filters []string) ([]*Todo, []string) {
@@ -333,6 +348,7 @@ func (f *ToDoFilter) filterTopN(filters []string) ([]*Todo, []string) {
 	//Filter to top N tasks (ideally sort first, then filter to top N)
 	//Top 1 per project -- "top:pro:1"
 	//Top 2 per context -- "top:ctx:2"
+	//Top 3 per project, and top 3 of those with no project -- "top:pro:3:none"
 	var filter string
 	index := -1
 	todos := []*Todo{}
@@ -342,12 +358,16 @@ func (f *ToDoFilter) filterTopN(filters []string) ([]*Todo, []string) {
 		filter = strings.ToLower(part)
 		if strings.HasPrefix(filter, "top:pro:") {
 			index = i
-			max, _ := strconv.Atoi(strings.TrimPrefix(filter, "top:pro:"))
+			max, none := parseTopN(strings.TrimPrefix(filter, "top:pro:"))
 			//loop thru todos and keep only topN for each project
 			pmap := map[string]int{}
 			count := 0
 			for _, todo := range f.Todos {
-				for _, proj := range todo.Projects {
+				projects := todo.Projects
+				if none && len(projects) == 0 {
+					projects = []string{""}
+				}
+				for _, proj := range projects {
 					count = pmap[proj] + 1
 					pmap[proj] = count
 					if count <= max {
@@ -357,12 +377,16 @@ func (f *ToDoFilter) filterTopN(filters []string) ([]*Todo, []string) {
 			}
 		} else if strings.HasPrefix(filter, "top:ctx:") {
 			index = i
-			max, _ := strconv.Atoi(strings.TrimPrefix(filter, "top:ctx:"))
+			max, none := parseTopN(strings.TrimPrefix(filter, "top:ctx:"))
 			//loop thru todos and keep only topN for each project
 			pmap := map[string]int{}
 			count := 0
 			for _, todo := range f.Todos {
-				for _, ctx := range todo.Contexts {
+				contexts := todo.Contexts
+				if none && len(contexts) == 0 {
+					contexts = []string{""}
+				}
+				for _, ctx := range contexts {
 					count = pmap[ctx] + 1
 					pmap[ctx] = count
 					if count <= max {
@@ -391,6 +415,14 @@ func (f *ToDoFilter) filterTopN(filters []string) ([]*Todo, []string) {
 	return todos, filters
 }
 
+//The N of a top:pro:N or top:ctx:N filter, and whether it ends :none, keeping the top N of the todos with no
+//project (or context) too
+func parseTopN(value string) (int, bool) {
+	none := strings.HasSuffix(value, ":none")
+	max, _ := strconv.Atoi(strings.TrimSuffix(value, ":none"))
+	return max, none
+}
+
 func (f *ToDoFilter) filterProjects(filters []string) ([]*Todo, []string) {
 
 	srcTodoList := f.Todos
@@ -528,6 +560,70 @@ func (f *ToDoFilter) filterContexts(filters []string) ([]*Todo, []string) {
 	return ret, filters
 }
 
+func (f *ToDoFilter) filterTags(filters []string) ([]*Todo, []string) {
+
+	notExcluded := f.Todos
+	var included []*Todo
+	hasInclude := false
+	indexes := []int{}
+
+	hasTag := func(todo *Todo, tag string) bool {
+		if isVirtualTag(tag) {
+			return todo.HasVirtualTag(tag, f.all, f.Clock.Now())
+		}
+		for _, todoTag := range todo.Tags {
+			if strings.ToLower(tag) == strings.ToLower(todoTag) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for i, filter := range filters {
+		if strings.HasPrefix(filter, "-#") {
+			tag := filter[2:]
+			indexes = append(indexes, i)
+			srcTodoList := notExcluded
+			notExcluded = []*Todo{}
+			for _, todo := range srcTodoList {
+				if !hasTag(todo, tag) {
+					notExcluded = append(notExcluded, todo)
+				}
+			}
+		} else if strings.HasPrefix(filter, "#") {
+			tag := filter[1:]
+			hasInclude = true
+			indexes = append(indexes, i)
+			for _, todo := range f.Todos {
+				if hasTag(todo, tag) {
+					included = AddTodoIfNotThere(included, todo)
+				}
+			}
+		}
+	}
+
+	if len(indexes) == 0 {
+		return f.Todos, filters
+	}
+	for i, index := range indexes {
+		index = index - i
+		filters = append(filters[0:index], filters[index+1:]...)
+	}
+	if !hasInclude {
+		return notExcluded, filters
+	}
+	ret := []*Todo{}
+	for _, todo := range included {
+		for _, ne := range notExcluded {
+			if todo.Id == ne.Id {
+				ret = append(ret, todo)
+				break
+			}
+		}
+	}
+	return ret, filters
+}
+
 func (f *ToDoFilter) union(included []*Todo, notExcluded []*Todo) []*Todo {
 	var ret []*Todo
 	if len(included) > 0 && len(notExcluded) > 0 {
@@ -560,8 +656,8 @@ func (f *ToDoFilter) filterSubject(filters []string) []*Todo {
 	exclude := false
 	var toFind string
 	for i, part := range filters {
-		//if !(strings.HasPrefix(part, "+") || strings.HasPrefix(part, "@") || strings.HasPrefix(part, "-") || strings.HasPrefix(part, "due:") || part == "archived" || part == "unarchived" || part == "p" || re.MatchString(part)) {
-		if !(strings.HasPrefix(part, "+") || strings.HasPrefix(part, "@") || strings.HasPrefix(part, "due:") || part == "archived" || part == "unarchived" || part == "completed" || part == "p" || idMatcher.MatchString(part)) {
+		//if !(strings.HasPrefix(part, "+") || strings.HasPrefix(part, "@") || strings.HasPrefix(part, "#") || strings.HasPrefix(part, "-") || strings.HasPrefix(part, "due:") || part == "archived" || part == "unarchived" || part == "p" || re.MatchString(part)) {
+		if !(strings.HasPrefix(part, "+") || strings.HasPrefix(part, "@") || strings.HasPrefix(part, "#") || strings.HasPrefix(part, "due:") || part == "archived" || part == "unarchived" || part == "completed" || part == "p" || idMatcher.MatchString(part)) {
 			subj = append(subj, filters[i])
 		}
 	}
@@ -0,0 +1,108 @@
+package todolist
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterTags(t *testing.T) {
+	assert := assert.New(t)
+	clock := FixedClock{Time: time.Now()}
+
+	todos := []*Todo{
+		&Todo{Id: 1, Subject: "one", Status: "Pending", Tags: []string{"review"}},
+		&Todo{Id: 2, Subject: "two", Status: "Pending", Tags: []string{"review", "docs"}},
+		&Todo{Id: 3, Subject: "three", Status: "Pending"},
+	}
+
+	filtered, _ := NewToDoFilter(todos, clock).Filter([]string{"#review"})
+	assert.Equal(2, len(filtered))
+
+	filtered, _ = NewToDoFilter(todos, clock).Filter([]string{"#review", "-#docs"})
+	assert.Equal(1, len(filtered))
+	assert.Equal(1, filtered[0].Id)
+
+	filtered, _ = NewToDoFilter(todos, clock).Filter([]string{"-#review"})
+	assert.Equal(1, len(filtered))
+	assert.Equal(3, filtered[0].Id)
+
+	filtered, _ = NewToDoFilter(todos, clock).Filter([]string{"#missing"})
+	assert.Equal(0, len(filtered))
+}
+
+func TestFilterVirtualTags(t *testing.T) {
+	assert := assert.New(t)
+	clock := FixedClock{Time: time.Now()}
+	now := clock.Now()
+
+	first := &Todo{Id: 1, Uuid: "a", Subject: "one", Status: "Pending", Due: timeToString(bod(now).AddDate(0, 0, -2))}
+	second := &Todo{Id: 2, Uuid: "b", Subject: "two", Status: "Pending", Depends: []string{"a"}, Notes: []string{"note"}}
+	third := &Todo{Id: 3, Uuid: "c", Subject: "three", Status: "Pending", Due: timeToString(bod(now)), Recur: "1w"}
+	todos := []*Todo{first, second, third}
+
+	filtered, _ := NewToDoFilter(todos, clock).Filter([]string{"#OVERDUE"})
+	assert.Equal(1, len(filtered))
+	assert.Equal(1, filtered[0].Id)
+
+	filtered, _ = NewToDoFilter(todos, clock).Filter([]string{"#BLOCKED"})
+	assert.Equal(1, len(filtered))
+	assert.Equal(2, filtered[0].Id)
+
+	filtered, _ = NewToDoFilter(todos, clock).Filter([]string{"#TODAY", "#RECURRING"})
+	assert.Equal(1, len(filtered))
+	assert.Equal(3, filtered[0].Id)
+
+	filtered, _ = NewToDoFilter(todos, clock).Filter([]string{"-#ANNOTATED"})
+	assert.Equal(2, len(filtered))
+
+	first.Complete(now)
+	assert.False(second.IsBlocked(todos))
+	assert.Equal([]string{"ANNOTATED"}, second.GetVirtualTags(todos, now))
+
+	//recur: and depends: set what RECURRING and BLOCKED read
+	list := &TodoList{Data: todos}
+	parser := &Parser{Clock: clock}
+	assert.Nil(parser.ParseInput([]string{"recur:weekly", "depends:2,9"}, third, list))
+	assert.Equal("weekly", third.Recur)
+	assert.Equal([]string{"b"}, third.Depends)
+	filtered, _ = NewToDoFilter(todos, clock).Filter([]string{"#BLOCKED", "#RECURRING"})
+	assert.Equal([]*Todo{third}, filtered)
+	assert.Nil(parser.ParseInput([]string{"recur:none", "depends:"}, third, list))
+	assert.Equal("", third.Recur)
+	assert.Empty(third.Depends)
+	assert.True(errors.Is(parser.ParseInput([]string{"recur:often"}, third, list), ErrBadInput))
+}
+
+func TestFilterActive(t *testing.T) {
+	assert := assert.New(t)
+	clock := FixedClock{Time: time.Now().Truncate(time.Second)}
+	now := clock.Now()
+
+	first := &Todo{Id: 1, Subject: "one", Status: "Pending"}
+	second := &Todo{Id: 2, Subject: "two", Status: "Pending"}
+	todos := []*Todo{first, second}
+
+	assert.True(first.StartTimer(now))
+	assert.False(first.StartTimer(now))
+	filtered, _ := NewToDoFilter(todos, clock).Filter([]string{"#ACTIVE"})
+	assert.Equal(1, len(filtered))
+	assert.Equal(1, filtered[0].Id)
+
+	now = now.Add(90 * time.Minute)
+	assert.Equal(90*time.Minute, first.TimeSpent(now))
+	assert.True(first.StopTimer(now))
+	assert.Equal(1, len(first.Intervals))
+	assert.Equal("1h30m", durationToString(first.TimeSpent(now)))
+
+	filtered, _ = NewToDoFilter(todos, clock).Filter([]string{"-#ACTIVE"})
+	assert.Equal(2, len(filtered))
+
+	//A bare word searches the subject, even one naming a state
+	second.Subject = "keep the site active"
+	assert.True(first.StartTimer(now))
+	filtered, _ = NewToDoFilter(todos, clock).Filter([]string{"active"})
+	assert.Equal([]*Todo{second}, filtered)
+}
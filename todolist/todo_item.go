@@ -2,7 +2,7 @@ package todolist
 
 import (
 	"fmt"
-	"os"
+	"time"
 )
 
 // Timestamp format to include date, time with timezone support. Easy to parse
@@ -14,6 +14,7 @@ type Todo struct {
 	Subject       string         `json:"subject"`
 	Projects      []string       `json:"projects"`
 	Contexts      []string       `json:"contexts"`
+	Tags          []string       `json:"tags"`
 	Priority      string         `json:"priority"`
 	Ordinals      map[string]int `json:"ordinals"`
 	CreatedDate   string         `json:"createdDate"`
@@ -21,33 +22,55 @@ type Todo struct {
 	IsModified    bool           `json:"-"`
 	Wait          string         `json:"wait"`
 	Until         string         `json:"until"`
+	Scheduled     string         `json:"scheduled"`
 	Due           string         `json:"due"`
+	Start         string         `json:"start"`
+	Recur         string         `json:"recur"`
+	Depends       []string       `json:"depends"`
+	Intervals     []*Interval    `json:"intervals"`
 	EffortDays    float64        `json:"effortDays"`
 	Completed     bool           `json:"completed"`
 	CompletedDate string         `json:"completedDate"`
 	Status        string         `json:"status"`
 	Notes         []string       `json:"notes"`
 	ExecOrder     float64
+	Urgency       float64 `json:"-"`
+	NextScore     float64 `json:"-"`
 }
 
-func NewTodo() *Todo {
+//A period of time worked on a todo, recorded by the start and stop commands
+type Interval struct {
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Pomodoro bool   `json:"pomodoro,omitempty"` //Recorded by the pomo command
+}
+
+//Length of the interval. A running interval (no end yet) runs until now.
+func (i *Interval) Duration(now time.Time) time.Duration {
+	if i.End == "" {
+		return now.Sub(stringToTime(i.Start))
+	}
+	return stringToTime(i.End).Sub(stringToTime(i.Start))
+}
+
+func NewTodo() (*Todo, error) {
 	uuid, err := newUUID()
 	if err != nil {
-		fmt.Println("Could not create UUID for new Todo: ", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("Could not create UUID for new Todo: %w", err)
 	}
 	ordMap := map[string]int{}
 	//fmt.Println("Creating new Todo with UUID: ", uuid)
-	return &Todo{Completed: false, Status: "Pending", Uuid: uuid, Ordinals: ordMap}
+	return &Todo{Completed: false, Status: "Pending", Uuid: uuid, Ordinals: ordMap}, nil
 }
 
 func (t Todo) Valid() bool {
 	return (t.Subject != "")
 }
 
-func (t *Todo) Complete() {
+func (t *Todo) Complete(now time.Time) {
+	t.StopTimer(now)
 	t.Completed = true
-	t.CompletedDate = timeToString(Now)
+	t.CompletedDate = timeToString(now)
 }
 
 func (t *Todo) Uncomplete() {
@@ -81,3 +104,168 @@ func (t Todo) HasContext(ctx string) bool {
 	}
 	return false
 }
+
+func (t Todo) HasTag(tag string) bool {
+	for _, tg := range t.Tags {
+		if tag == tg {
+			return true
+		}
+	}
+	return false
+}
+
+func (t Todo) IsOverdue(now time.Time) bool {
+	if t.Due == "" || t.Completed {
+		return false
+	}
+	return stringToTime(t.Due).Before(bod(now))
+}
+
+func (t Todo) IsDueToday(now time.Time) bool {
+	if t.Due == "" {
+		return false
+	}
+	return isToday(stringToTime(t.Due), now)
+}
+
+func (t Todo) IsWaiting(now time.Time) bool {
+	return stringToTime(t.Wait).After(now)
+}
+
+//Scheduled todos are ready to work on once the scheduled date is reached, unless still waiting
+func (t Todo) IsReady(now time.Time) bool {
+	if t.Scheduled == "" || t.Completed || t.IsWaiting(now) {
+		return false
+	}
+	return !stringToTime(t.Scheduled).After(now)
+}
+
+func (t Todo) IsActive() bool {
+	return t.Start != "" && !t.Completed
+}
+
+//Start tracking time on the todo. Returns false if already started.
+func (t *Todo) StartTimer(now time.Time) bool {
+	if t.IsActive() {
+		return false
+	}
+	t.Start = timeToString(now)
+	return true
+}
+
+//Stop tracking time on the todo, recording the time since start as an interval. Returns false if not started.
+func (t *Todo) StopTimer(now time.Time) bool {
+	if !t.IsActive() {
+		return false
+	}
+	t.Intervals = append(t.Intervals, &Interval{Start: t.Start, End: timeToString(now)})
+	t.Start = ""
+	return true
+}
+
+//Recorded intervals plus the running interval, if the todo is active
+func (t *Todo) TimeIntervals() []*Interval {
+	if !t.IsActive() {
+		return t.Intervals
+	}
+	return append(append([]*Interval{}, t.Intervals...), &Interval{Start: t.Start})
+}
+
+func (t *Todo) TimeSpent(now time.Time) time.Duration {
+	var spent time.Duration
+	for _, i := range t.TimeIntervals() {
+		spent += i.Duration(now)
+	}
+	return spent
+}
+
+//Record a completed pomodoro as a time interval and a note
+func (t *Todo) AddPomodoro(start time.Time, end time.Time) {
+	t.Intervals = append(t.Intervals, &Interval{Start: timeToString(start), End: timeToString(end), Pomodoro: true})
+	t.Notes = append(t.Notes, fmt.Sprintf("Pomodoro %d completed %s", t.Pomodoros(), end.Format("2006-01-02 15:04")))
+}
+
+func (t *Todo) Pomodoros() int {
+	cnt := 0
+	for _, i := range t.Intervals {
+		if i.Pomodoro {
+			cnt++
+		}
+	}
+	return cnt
+}
+
+//Blocked if any todo this one depends on is still pending (ie. not completed or archived)
+func (t Todo) IsBlocked(todos []*Todo) bool {
+	for _, uuid := range t.Depends {
+		for _, todo := range todos {
+			if todo.Uuid == uuid && !todo.Completed && todo.Status == "Pending" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//Blocking if any pending todo depends on this one
+func (t Todo) IsBlocking(todos []*Todo) bool {
+	if t.Completed || t.Status != "Pending" {
+		return false
+	}
+	for _, todo := range todos {
+		if todo.Completed || todo.Status != "Pending" {
+			continue
+		}
+		for _, uuid := range todo.Depends {
+			if uuid == t.Uuid {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//Virtual tags are computed from the state of the todo rather than assigned by the user.
+//Modeled on TaskWarrior. Names are upper case to distinguish from user defined tags.
+var VirtualTags = []string{"OVERDUE", "TODAY", "WAITING", "BLOCKED", "ANNOTATED", "RECURRING", "ACTIVE", "READY"}
+
+func (t Todo) HasVirtualTag(tag string, todos []*Todo, now time.Time) bool {
+	switch tag {
+	case "OVERDUE":
+		return t.IsOverdue(now)
+	case "TODAY":
+		return t.IsDueToday(now)
+	case "WAITING":
+		return t.IsWaiting(now)
+	case "BLOCKED":
+		return t.IsBlocked(todos)
+	case "ANNOTATED":
+		return len(t.Notes) > 0
+	case "RECURRING":
+		return t.Recur != ""
+	case "ACTIVE":
+		return t.IsActive()
+	case "READY":
+		return t.IsReady(now)
+	}
+	return false
+}
+
+func (t Todo) GetVirtualTags(todos []*Todo, now time.Time) []string {
+	tags := []string{}
+	for _, tag := range VirtualTags {
+		if t.HasVirtualTag(tag, todos, now) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func isVirtualTag(tag string) bool {
+	for _, vt := range VirtualTags {
+		if tag == vt {
+			return true
+		}
+	}
+	return false
+}
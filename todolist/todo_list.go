@@ -3,10 +3,28 @@ package todolist
 import (
 	"sort"
 	"strings"
+	"time"
 )
 
 type TodoList struct {
-	Data []*Todo
+	Data  []*Todo
+	Clock Clock  //Stamps modified, completed and started dates. The system clock if nil.
+	Hooks *Hooks //Scripts run as todos are added, edited, completed and deleted. None if nil.
+}
+
+func NewTodoList(clock Clock) *TodoList {
+	return &TodoList{Clock: clock}
+}
+
+func (t *TodoList) clock() Clock {
+	if t.Clock == nil {
+		return SystemClock{}
+	}
+	return t.Clock
+}
+
+func (t *TodoList) now() time.Time {
+	return t.clock().Now()
 }
 
 func (t *TodoList) Load(todos []*Todo) {
@@ -17,13 +35,17 @@ func (t *TodoList) Load(todos []*Todo) {
 	}
 }
 
-func (t *TodoList) Add(todo *Todo) int {
+//Add the todo and return its id. Returns ErrHookRejected, and doesn't add it, if an on-add hook rejects it.
+func (t *TodoList) Add(todo *Todo) (int, error) {
 	todo.Id = t.NextId()
-	todo.ModifiedDate = timeToString(Now)
+	todo.ModifiedDate = timeToString(t.now())
 	todo.CreatedDate = todo.ModifiedDate
+	if err := t.Hooks.OnAdd(todo); err != nil {
+		return 0, err
+	}
 	todo.IsModified = true
 	t.Data = append(t.Data, todo)
-	return todo.Id
+	return todo.Id, nil
 }
 
 func (t *TodoList) getSetSortedByOrdinal(set string) []*Todo {
@@ -126,6 +148,21 @@ func (t *TodoList) AddContext(c string, todo *Todo) {
 	t.AddOrdinal("@"+c, todo)
 }
 
+func (t *TodoList) AddTag(tag string, todo *Todo) {
+	if !todo.HasTag(tag) {
+		todo.Tags = append(todo.Tags, tag)
+	}
+}
+
+func (t *TodoList) RemoveTag(tag string, todo *Todo) {
+	for i, tg := range todo.Tags {
+		if tg == tag {
+			todo.Tags = append(todo.Tags[:i], todo.Tags[i+1:]...)
+			break
+		}
+	}
+}
+
 func (t *TodoList) RemoveProject(p string, todo *Todo) {
 	for i, project := range todo.Projects {
 		if project == p {
@@ -156,7 +193,9 @@ func (t *TodoList) RemoveOrdinal(set string, todo *Todo) {
 	delete(todo.Ordinals, set)
 }
 
-func (t *TodoList) UpdateOrdinals(set string, ids []int) {
+//Move the todos with the ids to the position of the first in the set's order (0 for the top). The todos whose ordinal
+//changes are passed to the on-modify hooks. Returns ErrHookRejected, leaving the rest unchanged, if a hook rejects one.
+func (t *TodoList) UpdateOrdinals(set string, ids []int) error {
 	//Get todos for the set ordered by current ordinal values
 	todos := t.getSetSortedByOrdinal(set)
 
@@ -214,65 +253,107 @@ func (t *TodoList) UpdateOrdinals(set string, ids []int) {
 
 	//Assign new ordinal values
 	for i, todo := range res {
-		todo.Ordinals[set] = i
-		todo.ModifiedDate = timeToString(Now)
-		todo.IsModified = true
+		ordinal := i
+		_, err := t.Modify(todo, func() (bool, error) {
+			changed := todo.Ordinals[set] != ordinal
+			todo.Ordinals[set] = ordinal
+			return changed, nil
+		})
+		if err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
-func (t *TodoList) Edit(mods []string, todos ...*Todo) bool {
-	parser := &Parser{}
+func (t *TodoList) Edit(mods []string, todos ...*Todo) (bool, error) {
+	parser := &Parser{Clock: t.clock()}
 	isEdited := false
 	for _, todo := range todos {
-		//Set mod date default to current time. Edit op can override with mod:<date>
-		//NOTE - Reversed in else clause if edit fails
-		origModDate := todo.ModifiedDate
-		todo.ModifiedDate = timeToString(Now)
-		if parser.ParseEditTodo(todo, mods, t) {
-			todo.IsModified = true
-			t.remove(todo)
-			t.Data = append(t.Data, todo)
-			isEdited = true
-		} else {
-			todo.IsModified = false
-			todo.ModifiedDate = origModDate
+		ok, err := t.Modify(todo, func() (bool, error) {
+			return parser.ParseEditTodo(todo, mods, t)
+		})
+		if err != nil {
+			return isEdited, err
 		}
+		isEdited = isEdited || ok
+	}
+	return isEdited, nil
+}
+
+//Change the todo with the change func, which returns whether it changed anything. A changed todo is passed to the
+//on-modify hooks. If a hook rejects the change, the todo is restored and ErrHookRejected returned.
+func (t *TodoList) Modify(todo *Todo, change func() (bool, error)) (bool, error) {
+	old, err := snapshotTodo(todo)
+	if err != nil {
+		return false, err
+	}
+	//Set mod date default to current time. Edit op can override with mod:<date>
+	//NOTE - Reversed if the edit fails or changes nothing
+	todo.ModifiedDate = timeToString(t.now())
+	ok, err := change()
+	if err != nil {
+		todo.ModifiedDate = old.ModifiedDate
+		return false, err
+	}
+	if !ok {
+		todo.IsModified = old.IsModified
+		todo.ModifiedDate = old.ModifiedDate
+		return false, nil
 	}
-	return isEdited
+	if err := t.Hooks.OnModify(old, todo); err != nil {
+		*todo = *old
+		return false, err
+	}
+	todo.IsModified = true
+	t.remove(todo)
+	t.Data = append(t.Data, todo)
+	return true, nil
 }
 
-func (t *TodoList) Touch(todos ...*Todo) bool {
+//Set the modified date to now. Touched todos are passed to the on-modify hooks.
+func (t *TodoList) Touch(todos ...*Todo) (bool, error) {
 	isTouched := false
 	for _, todo := range todos {
-		//Set modified date to current date by default
-		todo.ModifiedDate = timeToString(Now)
-		todo.IsModified = true
-		t.remove(todo)
-		t.Data = append(t.Data, todo)
+		if _, err := t.Modify(todo, func() (bool, error) { return true, nil }); err != nil {
+			return isTouched, err
+		}
 		isTouched = true
 	}
-	return isTouched
+	return isTouched, nil
 }
 
-func (t *TodoList) Delete(todos ...*Todo) {
+func (t *TodoList) Delete(todos ...*Todo) error {
 	for _, td := range todos {
 		for _, todo := range t.Data {
-			if todo.Id == td.Id {
-				todo.ModifiedDate = timeToString(Now)
-				todo.IsModified = true
+			//The same todo, not the same id. Archived todos loaded with the pending ones may share their ids.
+			if todo == td {
+				old, err := snapshotTodo(todo)
+				if err != nil {
+					return err
+				}
+				todo.ModifiedDate = timeToString(t.now())
 				todo.Status = "Deleted"
+				if err := t.Hooks.OnDelete(todo); err != nil {
+					*todo = *old
+					return err
+				}
+				todo.IsModified = true
 				t.remove(todo)
 				t.Data = append(t.Data, todo)
+				break
 			}
 		}
 	}
+	return nil
 }
 
 func (t *TodoList) remove(todos ...*Todo) {
 	for _, td := range todos {
 		i := -1
 		for index, todo := range t.Data {
-			if todo.Id == td.Id {
+			//By pointer, as in Delete. An archived todo may have the same id.
+			if todo == td {
 				i = index
 				break
 			}
@@ -281,55 +362,109 @@ func (t *TodoList) remove(todos ...*Todo) {
 	}
 }
 
-func (t *TodoList) Complete(todos ...*Todo) {
+func (t *TodoList) Complete(todos ...*Todo) error {
 	for _, td := range todos {
-		td.Complete()
-		td.ModifiedDate = timeToString(Now)
-		td.IsModified = true
-		t.remove(td)
-		t.Data = append(t.Data, td)
+		if err := t.complete(td, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Complete, and optionally archive, the todo. Newly completed todos are passed to the on-complete hooks.
+func (t *TodoList) complete(td *Todo, archive bool) error {
+	old, err := snapshotTodo(td)
+	if err != nil {
+		return err
+	}
+	wasCompleted := td.Completed
+	now := t.now()
+	td.Complete(now)
+	if archive {
+		td.Archive()
+	}
+	td.ModifiedDate = timeToString(now)
+	if !wasCompleted {
+		if err := t.Hooks.OnComplete(td); err != nil {
+			*td = *old
+			return err
+		}
 	}
+	td.IsModified = true
+	t.remove(td)
+	t.Data = append(t.Data, td)
+	return nil
 }
 
-func (t *TodoList) Uncomplete(todos ...*Todo) {
+//Start the timers of the todos not started. Started todos are passed to the on-modify hooks.
+func (t *TodoList) Start(todos ...*Todo) (bool, error) {
+	isStarted := false
 	for _, td := range todos {
-		td.Uncomplete()
-		td.ModifiedDate = timeToString(Now)
-		td.IsModified = true
-		t.remove(td)
-		t.Data = append(t.Data, td)
+		ok, err := t.Modify(td, func() (bool, error) { return td.StartTimer(t.now()), nil })
+		if err != nil {
+			return isStarted, err
+		}
+		isStarted = isStarted || ok
 	}
+	return isStarted, nil
 }
 
-func (t *TodoList) Archive(todos ...*Todo) {
+//Stop the timers of the started todos, recording the time spent. Stopped todos are passed to the on-modify hooks.
+func (t *TodoList) Stop(todos ...*Todo) (bool, error) {
+	isStopped := false
 	for _, td := range todos {
-		td.Archive()
-		td.ModifiedDate = timeToString(Now)
-		td.IsModified = true
-		t.remove(td)
-		t.Data = append(t.Data, td)
+		ok, err := t.Modify(td, func() (bool, error) { return td.StopTimer(t.now()), nil })
+		if err != nil {
+			return isStopped, err
+		}
+		isStopped = isStopped || ok
 	}
+	return isStopped, nil
 }
 
-func (t *TodoList) Unarchive(todos ...*Todo) {
+func (t *TodoList) Active() []*Todo {
+	active := []*Todo{}
+	for _, td := range t.Data {
+		if td.IsActive() {
+			active = append(active, td)
+		}
+	}
+	return active
+}
+
+//Uncomplete, archive or unarchive the todos, passing each to the on-modify hooks
+func (t *TodoList) Uncomplete(todos ...*Todo) error {
+	return t.modifyAll(todos, (*Todo).Uncomplete)
+}
+
+func (t *TodoList) Archive(todos ...*Todo) error {
+	return t.modifyAll(todos, (*Todo).Archive)
+}
+
+func (t *TodoList) Unarchive(todos ...*Todo) error {
+	return t.modifyAll(todos, (*Todo).Unarchive)
+}
+
+func (t *TodoList) modifyAll(todos []*Todo, change func(*Todo)) error {
 	for _, td := range todos {
-		td.Unarchive()
-		td.ModifiedDate = timeToString(Now)
-		td.IsModified = true
-		t.remove(td)
-		t.Data = append(t.Data, td)
+		todo := td
+		if _, err := t.Modify(todo, func() (bool, error) {
+			change(todo)
+			return true, nil
+		}); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
-func (t *TodoList) CompleteAndArchive(todos ...*Todo) {
+func (t *TodoList) CompleteAndArchive(todos ...*Todo) error {
 	for _, td := range todos {
-		td.Complete()
-		td.Archive()
-		td.ModifiedDate = timeToString(Now)
-		td.IsModified = true
-		t.remove(td)
-		t.Data = append(t.Data, td)
+		if err := t.complete(td, true); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 func (t *TodoList) IndexOf(todoToFind *Todo) int {
 	for i, todo := range t.Data {
@@ -408,24 +543,22 @@ func (t *TodoList) ReassignAllIds() []*Todo {
 	return t.Data
 }
 
-func (t *TodoList) ExpireTodos() bool {
-	dateFilter := NewDateFilter(t.Data)
+//Archive the todos past their until date. Returns whether any were, and ErrHookRejected if a hook rejects one.
+func (t *TodoList) ExpireTodos() (bool, error) {
+	dateFilter := NewDateFilter(t.Data, t.clock())
 	expired := dateFilter.FilterExpired()
 	if len(expired) > 0 {
-		t.Archive(expired...)
-		return true
+		return true, t.Archive(expired...)
 	}
-	return false
+	return false, nil
 }
 
-func (t *TodoList) GarbageCollect() {
+func (t *TodoList) GarbageCollect() error {
 	var toDelete []*Todo
 	for _, todo := range t.Data {
 		if todo.Status == "Archived" {
 			toDelete = append(toDelete, todo)
 		}
 	}
-	for _, todo := range toDelete {
-		t.Delete(todo)
-	}
+	return t.Delete(toDelete...)
 }
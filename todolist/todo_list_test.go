@@ -6,9 +6,17 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+//The todos the tests load: 1 archived, 2 completed
+func testTodos() []*Todo {
+	return []*Todo{
+		&Todo{Id: 1, Subject: "this is the first subject", Status: "Archived", Ordinals: map[string]int{}},
+		&Todo{Id: 2, Subject: "this is the second subject", Status: "Pending", Completed: true, Ordinals: map[string]int{}},
+	}
+}
+
 func TestNextId(t *testing.T) {
 	assert := assert.New(t)
-	todo := &Todo{Subject: "testing", Completed: false, Archived: false}
+	todo := &Todo{Subject: "testing", Completed: false, Status: "Pending"}
 	list := &TodoList{}
 	assert.Equal(1, list.NextId())
 	list.Add(todo)
@@ -17,27 +25,30 @@ func TestNextId(t *testing.T) {
 
 func TestNextIdWhenTodoDeleted(t *testing.T) {
 	assert := assert.New(t)
-	todo := &Todo{Subject: "testing", Completed: false, Archived: false}
-	todo2 := &Todo{Subject: "testing2", Completed: false, Archived: false}
-	todo3 := &Todo{Subject: "testing3", Completed: false, Archived: false}
+	todo := &Todo{Subject: "testing", Completed: false, Status: "Pending"}
+	todo2 := &Todo{Subject: "testing2", Completed: false, Status: "Pending"}
+	todo3 := &Todo{Subject: "testing3", Completed: false, Status: "Pending"}
 	list := &TodoList{}
 
 	list.Add(todo)
 	list.Add(todo2)
 	list.Add(todo3)
 
-	list.Delete(2)
+	//Deleted todos are dropped when saved, so their ids are free once loaded again
+	list.Delete(todo2)
+	assert.Equal("Deleted", todo2.Status)
+	list = &TodoList{Data: []*Todo{todo, todo3}}
 	assert.Equal(2, list.NextId())
 	list.Add(todo2)
 	assert.Equal(4, list.NextId())
-	list.Delete(1)
+	list = &TodoList{Data: []*Todo{todo2, todo3}}
 	assert.Equal(1, list.NextId())
 }
 
 func TestMaxId(t *testing.T) {
 	assert := assert.New(t)
-	todo := &Todo{Subject: "testing", Completed: false, Archived: false}
-	todo2 := &Todo{Subject: "testing 2", Completed: false, Archived: false}
+	todo := &Todo{Subject: "testing", Completed: false, Status: "Pending"}
+	todo2 := &Todo{Subject: "testing 2", Completed: false, Status: "Pending"}
 	list := &TodoList{}
 	assert.Equal(0, list.MaxId())
 	list.Add(todo)
@@ -49,10 +60,8 @@ func TestMaxId(t *testing.T) {
 func TestIndexOf(t *testing.T) {
 	assert := assert.New(t)
 	todo := &Todo{Subject: "Grant"}
-	store := &FileStore{FileLocation: "todos.json"}
 	list := &TodoList{}
-	todos, _ := store.Load()
-	list.Load(todos)
+	list.Load(testTodos())
 
 	assert.Equal(-1, list.IndexOf(todo))
 	assert.Equal(0, list.IndexOf(list.Data[0]))
@@ -60,91 +69,99 @@ func TestIndexOf(t *testing.T) {
 
 func TestDelete(t *testing.T) {
 	assert := assert.New(t)
-	store := &FileStore{FileLocation: "todos.json"}
 	list := &TodoList{}
-	todos, _ := store.Load()
-	list.Load(todos)
+	list.Load(testTodos())
 	assert.Equal(2, len(list.Data))
-	list.Delete(1)
-	assert.Equal(1, len(list.Data))
+	list.Delete(list.FindById(1))
+	assert.Equal("Deleted", list.FindById(1).Status)
+	assert.True(list.FindById(1).IsModified)
+	assert.Equal("Pending", list.FindById(2).Status)
 }
 
 func TestComplete(t *testing.T) {
 	assert := assert.New(t)
-	store := &FileStore{FileLocation: "todos.json"}
 	list := &TodoList{}
-	todos, _ := store.Load()
-	list.Load(todos)
+	list.Load(testTodos())
 	assert.Equal(false, list.FindById(1).Completed)
-	list.Complete(1)
+	list.Complete(list.FindById(1))
 	assert.Equal(true, list.FindById(1).Completed)
 }
 
 func TestArchive(t *testing.T) {
 	assert := assert.New(t)
-	store := &FileStore{FileLocation: "todos.json"}
 	list := &TodoList{}
-	todos, _ := store.Load()
-	list.Load(todos)
-	assert.Equal(false, list.FindById(2).Archived)
-	list.Archive(2)
-	assert.Equal(true, list.FindById(2).Archived)
+	list.Load(testTodos())
+	assert.Equal("Pending", list.FindById(2).Status)
+	list.Archive(list.FindById(2))
+	assert.Equal("Archived", list.FindById(2).Status)
 }
 func TestUnarchive(t *testing.T) {
 	assert := assert.New(t)
-	store := &FileStore{FileLocation: "todos.json"}
 	list := &TodoList{}
-	todos, _ := store.Load()
-	list.Load(todos)
-	assert.Equal(true, list.FindById(1).Archived)
-	list.Unarchive(1)
-	assert.Equal(false, list.FindById(1).Archived)
+	list.Load(testTodos())
+	assert.Equal("Archived", list.FindById(1).Status)
+	list.Unarchive(list.FindById(1))
+	assert.Equal("Pending", list.FindById(1).Status)
 }
 
 func TestUncomplete(t *testing.T) {
 	assert := assert.New(t)
-	store := &FileStore{FileLocation: "todos.json"}
 	list := &TodoList{}
-	todos, _ := store.Load()
-	list.Load(todos)
+	list.Load(testTodos())
 	assert.Equal(true, list.FindById(2).Completed)
-	list.Uncomplete(2)
+	list.Uncomplete(list.FindById(2))
 	assert.Equal(false, list.FindById(2).Completed)
 }
 
 func TestGarbageCollect(t *testing.T) {
 	assert := assert.New(t)
 	list := &TodoList{}
-	todo := &Todo{Subject: "testing", Completed: false, Archived: true}
-	todo2 := &Todo{Subject: "testing2", Completed: false, Archived: false}
-	todo3 := &Todo{Subject: "testing3", Completed: false, Archived: true}
+	todo := &Todo{Subject: "testing", Completed: false, Status: "Archived"}
+	todo2 := &Todo{Subject: "testing2", Completed: false, Status: "Pending"}
+	todo3 := &Todo{Subject: "testing3", Completed: false, Status: "Archived"}
 	list.Add(todo)
 	list.Add(todo2)
 	list.Add(todo3)
 
 	list.GarbageCollect()
 
-	assert.Equal(len(list.Data), 1)
-	assert.Equal(1, list.NextId())
-	assert.Equal(2, list.MaxId())
+	assert.Equal("Deleted", todo.Status)
+	assert.Equal("Pending", todo2.Status)
+	assert.Equal("Deleted", todo3.Status)
+	assert.Equal(3, list.MaxId())
 }
 
 func TestPrioritizeNotInTodosJson(t *testing.T) {
 	assert := assert.New(t)
-	store := &FileStore{FileLocation: "todos.json"}
 	list := &TodoList{}
-	todos, _ := store.Load()
-	list.Load(todos)
-	assert.Equal(false, list.FindById(2).IsPriority)
+	list.Load(testTodos())
+	assert.Equal("", list.FindById(2).Priority)
 }
 
 func TestPrioritizeTodo(t *testing.T) {
 	assert := assert.New(t)
 	list := &TodoList{}
-	todo := &Todo{Archived: false, Completed: false, Subject: "testing", IsPriority: false}
+	todo := &Todo{Status: "Pending", Completed: false, Subject: "testing"}
 	list.Add(todo)
-	list.Prioritize(1)
-	assert.Equal(true, list.FindById(1).IsPriority)
-	list.Unprioritize(1)
-	assert.Equal(false, list.FindById(1).IsPriority)
+	list.Edit([]string{"pri:H"}, list.FindById(1))
+	assert.Equal("H", list.FindById(1).Priority)
+	list.Edit([]string{"pri:"}, list.FindById(1))
+	assert.Equal("", list.FindById(1).Priority)
+}
+
+func TestModifySharedId(t *testing.T) {
+	assert := assert.New(t)
+	archived := &Todo{Id: 1, Subject: "archived", Status: "Archived", Ordinals: map[string]int{}}
+	pending := &Todo{Id: 1, Subject: "pending", Status: "Pending", Ordinals: map[string]int{}}
+	list := &TodoList{Data: []*Todo{archived, pending}}
+
+	//Only the todo edited is moved, not the archived todo with its id
+	list.Edit([]string{"pri:H"}, pending)
+	assert.Equal([]*Todo{archived, pending}, list.Data)
+	assert.Equal("H", pending.Priority)
+	assert.Equal("", archived.Priority)
+
+	list.Delete(pending)
+	assert.Equal([]*Todo{archived, pending}, list.Data)
+	assert.Equal("Archived", archived.Status)
 }
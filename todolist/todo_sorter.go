@@ -3,23 +3,48 @@ package todolist
 import (
 	"sort"
 	"strings"
+	"time"
 )
 
 type lessFunc func(p1, p2 *Todo) int
 
 // multiSorter implements the Sort interface, sorting the changes within.
 type TodoSorter struct {
-	todos       []*Todo
-	less        []lessFunc
-	SortColumns []string
+	todos        []*Todo
+	less         []lessFunc
+	SortColumns  []string
+	Priority     map[string]int     //Order of the priority values (priority= in .todorc). H, M, L if nil.
+	Coefficients map[string]float64 //Urgency coefficients (urgency.* in .todorc). The defaults if nil.
 }
 
 func NewTodoSorter(sortCols ...string) *TodoSorter {
-	sorter := &TodoSorter{}
-	sorter.SortColumns = sortCols
+	return &TodoSorter{SortColumns: sortCols}
+}
+
+//A copy of the sorter that ranks todos by the configured priorities and urgency coefficients
+func (s *TodoSorter) ranked(priority map[string]int, coefficients map[string]float64) *TodoSorter {
+	return &TodoSorter{SortColumns: s.SortColumns, Priority: priority, Coefficients: coefficients}
+}
+
+func (s *TodoSorter) priority() map[string]int {
+	if s.Priority == nil {
+		return DefaultPriority()
+	}
+	return s.Priority
+}
+
+func (s *TodoSorter) coefficients() map[string]float64 {
+	if s.Coefficients == nil {
+		return DefaultUrgencyCoefficients()
+	}
+	return s.Coefficients
+}
+
+//The less functions for the sort columns
+func (s *TodoSorter) lessFuncs() []lessFunc {
 	asc := true
 	sorters := []lessFunc{}
-	for _, col := range sortCols {
+	for _, col := range s.SortColumns {
 		col = strings.ToLower(col)
 		if strings.HasPrefix(col, "-") {
 			asc = false
@@ -37,8 +62,10 @@ func NewTodoSorter(sortCols ...string) *TodoSorter {
 			sorters = append(sorters, Context(asc))
 		case "due":
 			sorters = append(sorters, Due(asc))
+		case "scheduled":
+			sorters = append(sorters, Scheduled(asc))
 		case "priority":
-			sorters = append(sorters, PrioritySorter(asc))
+			sorters = append(sorters, PrioritySorter(asc, s.priority()))
 		case "id":
 			sorters = append(sorters, Id(asc))
 		case "notes":
@@ -51,6 +78,10 @@ func NewTodoSorter(sortCols ...string) *TodoSorter {
 			sorters = append(sorters, Effort(asc))
 		case "exec":
 			sorters = append(sorters, ExecOrder(asc))
+		case "urgency":
+			sorters = append(sorters, UrgencySorter(asc))
+		case "next":
+			sorters = append(sorters, NextSorter(asc))
 		case "ord:all":
 			sorters = append(sorters, OrdinalAll(asc))
 		case "ord:pro":
@@ -65,19 +96,36 @@ func NewTodoSorter(sortCols ...string) *TodoSorter {
 			sorters = append(sorters, Subject(asc))
 		}
 	}
-	sorter.less = sorters
-	return sorter
+	return sorters
+}
+
+//Whether NewTodoSorter knows the sort key (e.g. +due, -urgency)
+func isSortKey(key string) bool {
+	key = strings.ToLower(key)
+	if strings.HasPrefix(key, "-") || strings.HasPrefix(key, "+") {
+		key = key[1:]
+	}
+	switch key {
+	case "project", "context", "due", "scheduled", "priority", "id", "notes", "age", "idle", "effort", "exec", "urgency",
+		"next", "ord:all", "ord:pro", "ord:ctx", "created", "modified", "subject":
+		return true
+	}
+	return false
 }
 
 // Sort sorts the argument slice according to the less functions passed to OrderedBy.
-func (s *TodoSorter) Sort(todos []*Todo) {
-	calcAllExecOrder(todos)
+// Urgency and exec order are calculated as of now.
+func (s *TodoSorter) Sort(todos []*Todo, now time.Time) {
+	priority, coefficients := s.priority(), s.coefficients()
+	calcAllExecOrder(todos, now, priority)
+	calcAllUrgency(todos, now, priority, coefficients)
+	calcAllNextScore(todos, coefficients)
 	s.todos = todos
+	s.less = s.lessFuncs()
 	sort.Sort(s)
 }
 
-func PrioritySorter(asc bool) lessFunc {
-	priorityMap := Priority
+func PrioritySorter(asc bool, priorityMap map[string]int) lessFunc {
 	priority := func(t1, t2 *Todo) int {
 		ret := 0
 		var p1 int
@@ -300,6 +348,60 @@ func ExecOrder(asc bool) lessFunc {
 	return order
 }
 
+func UrgencySorter(asc bool) lessFunc {
+	order := func(t1, t2 *Todo) int {
+		ret := 0
+		if t1.Urgency < t2.Urgency {
+			ret = -1
+		} else if t1.Urgency > t2.Urgency {
+			ret = 1
+		} else {
+			ret = 0
+		}
+		if asc {
+			return ret
+		} else {
+			return -1 * ret
+		}
+	}
+	return order
+}
+
+func NextSorter(asc bool) lessFunc {
+	order := func(t1, t2 *Todo) int {
+		ret := 0
+		if t1.NextScore < t2.NextScore {
+			ret = -1
+		} else if t1.NextScore > t2.NextScore {
+			ret = 1
+		} else {
+			ret = 0
+		}
+		if asc {
+			return ret
+		} else {
+			return -1 * ret
+		}
+	}
+	return order
+}
+
+func Scheduled(asc bool) lessFunc {
+	scheduled := func(t1, t2 *Todo) int {
+		ret := 0
+		if t1.Scheduled < t2.Scheduled {
+			ret = -1
+		} else if t1.Scheduled > t2.Scheduled {
+			ret = 1
+		}
+		if asc {
+			return ret
+		}
+		return -1 * ret
+	}
+	return scheduled
+}
+
 func Due(asc bool) lessFunc {
 	due := func(t1, t2 *Todo) int {
 		ret := 0
@@ -3,9 +3,12 @@ package todolist
 import "testing"
 
 func TestNewTodo(t *testing.T) {
-	todo := NewTodo()
+	todo, err := NewTodo()
+	if err != nil {
+		t.Fatal(err)
+	}
 
-	if todo.Completed || todo.Archived || todo.CompletedDate != "" {
+	if todo.Completed || todo.Status != "Pending" || todo.CompletedDate != "" {
 		t.Error("Completed should be false for new todos")
 	}
 }
@@ -0,0 +1,351 @@
+package todolist
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	ui "github.com/gizak/termui"
+	termbox "github.com/nsf/termbox-go"
+)
+
+const tuiHelp = "j/k move  J/K reorder  c complete  a archive  e edit  d due  p priority  n note  v view  q quit"
+
+//Full screen, scrollable list of todos rendered with a report. Changes are saved through the Store as they are made.
+type TodoTui struct {
+	app      *App
+	report   *Report
+	filters  []string //Filters from the command line, excluding the view filters
+	views    []string //Names of the configured views. Blank for no view.
+	viewIdx  int
+	todos    []*Todo //Todos displayed, in display order
+	selected int
+	offset   int
+	status   string
+	prompt   string
+	input    []rune
+	onInput  func(input string)
+	events   <-chan ui.Event
+
+	archivedLoaded bool
+	reordering     bool //Sorted by ord:all since the first J/K, so moved todos move on screen
+}
+
+func NewTodoTui(app *App, report *Report, filters []string) *TodoTui {
+	t := &TodoTui{app: app, report: report, views: []string{""}}
+	for name := range app.Cfg.Views {
+		t.views = append(t.views, name)
+	}
+	sort.Strings(t.views[1:])
+	//The current view filters were added ahead of the command line filters. Keep them separate so views can be cycled.
+	t.filters = filters
+	if viewFilters, ok := app.Cfg.Views[app.Cfg.CurrentView]; ok && len(filters) >= len(viewFilters) {
+		t.filters = filters[len(viewFilters):]
+		for i, name := range t.views {
+			if name == app.Cfg.CurrentView {
+				t.viewIdx = i
+			}
+		}
+	}
+	return t
+}
+
+func (t *TodoTui) Run() error {
+	err := ui.Init()
+	if err != nil {
+		return err
+	}
+	defer ui.Close()
+	if w, h := termbox.Size(); w <= 0 || h <= 0 {
+		return errors.New("terminal size unknown")
+	}
+	//Hook feedback would print over the screen. Rejections are shown in the status line.
+	if hooks := t.app.TodoList.Hooks; hooks != nil {
+		out := hooks.Out
+		hooks.Out = ioutil.Discard
+		defer func() { hooks.Out = out }()
+	}
+	t.events = ui.PollEvents()
+	t.refresh()
+	t.render()
+	for e := range t.events {
+		if e.Type == ui.ResizeEvent {
+			t.render()
+			continue
+		}
+		if e.Type != ui.KeyboardEvent {
+			continue
+		}
+		if t.onInput != nil {
+			t.handleInput(e.ID)
+		} else if !t.handleKey(e.ID) {
+			return nil
+		}
+		t.render()
+	}
+	return nil
+}
+
+//Returns false to quit
+func (t *TodoTui) handleKey(key string) bool {
+	t.status = ""
+	switch key {
+	case "q", "<Escape>", "<C-c>":
+		return false
+	case "j", "<Down>":
+		t.moveSelection(1)
+	case "k", "<Up>":
+		t.moveSelection(-1)
+	case "<Next>":
+		t.moveSelection(t.pageSize())
+	case "<Previous>":
+		t.moveSelection(-t.pageSize())
+	case "J":
+		t.reorder(1)
+	case "K":
+		t.reorder(-1)
+	case "v":
+		t.viewIdx = (t.viewIdx + 1) % len(t.views)
+		t.selected = 0
+		t.refresh()
+	}
+	todo := t.current()
+	if todo == nil {
+		return true
+	}
+	switch key {
+	case "c":
+		if err := t.app.TodoList.Complete(todo); err != nil {
+			t.status = err.Error()
+			return true
+		}
+		t.save(fmt.Sprintf("Todo %d completed.", todo.Id))
+	case "a":
+		if !t.archivedLoaded {
+			//load the archived todos so the save will write them all to the same file
+			if err := t.app.LoadArchived(); err != nil {
+				t.status = err.Error()
+				return true
+			}
+			t.archivedLoaded = true
+		}
+		if err := t.app.TodoList.Archive(todo); err != nil {
+			t.status = err.Error()
+			return true
+		}
+		t.save(fmt.Sprintf("Todo %d archived.", todo.Id))
+	case "e":
+		t.startInput("Edit: ", todo.Subject, func(input string) {
+			t.edit(todo, strings.Split(input, " "))
+		})
+	case "d":
+		t.startInput("Due: ", "", func(input string) {
+			t.edit(todo, []string{"due:" + input})
+		})
+	case "p":
+		t.startInput("Priority: ", todo.Priority, func(input string) {
+			t.edit(todo, []string{"pri:" + input})
+		})
+	case "n":
+		t.startInput("Note: ", "", func(input string) {
+			parser := &Parser{Clock: t.app.Clock}
+			if input != "" && parser.ParseAddNote(todo, []string{input}) {
+				todo.ModifiedDate = timeToString(t.app.Clock.Now())
+				todo.IsModified = true
+				t.save(fmt.Sprintf("Note added to Todo %d.", todo.Id))
+			}
+		})
+	}
+	return true
+}
+
+func (t *TodoTui) handleInput(key string) {
+	switch key {
+	case "<Enter>":
+		onInput := t.onInput
+		t.onInput = nil
+		onInput(strings.TrimSpace(string(t.input)))
+	case "<Escape>", "<C-c>":
+		t.onInput = nil
+	case "<Backspace>", "<C-8>":
+		if len(t.input) > 0 {
+			t.input = t.input[:len(t.input)-1]
+		}
+	case "<Space>":
+		t.input = append(t.input, ' ')
+	default:
+		if len([]rune(key)) == 1 {
+			t.input = append(t.input, []rune(key)...)
+		}
+	}
+}
+
+func (t *TodoTui) startInput(prompt string, value string, onInput func(input string)) {
+	t.prompt = prompt
+	t.input = []rune(value)
+	t.onInput = onInput
+}
+
+func (t *TodoTui) edit(todo *Todo, mods []string) {
+	isEdited, err := t.app.TodoList.Edit(mods, todo)
+	if err != nil {
+		t.status = err.Error()
+	} else if isEdited {
+		t.save(fmt.Sprintf("Todo %d edited.", todo.Id))
+	}
+}
+
+//Move the selected todo before (-1) or after (1) its neighbour in the list, rewriting the ordinals for set 'all'.
+//The list is sorted by ord:all from the first move, so the todo moves with the selection.
+func (t *TodoTui) reorder(direction int) {
+	todo := t.current()
+	if todo == nil {
+		return
+	}
+	if !t.reordering {
+		t.reordering = true
+		t.report.Sorter = NewTodoSorter("ord:all").ranked(t.app.Cfg.Priority, t.app.Cfg.UrgencyCoefficients)
+		t.refresh()
+		t.selectTodo(todo)
+	}
+	next := t.selected + direction
+	if next < 0 || next >= len(t.todos) {
+		return
+	}
+	neighbour := t.todos[next]
+	ids := []int{neighbour.Id, todo.Id}
+	if direction < 0 {
+		ids = []int{todo.Id, neighbour.Id}
+	}
+	if err := t.app.TodoList.UpdateOrdinals("all", ids); err != nil {
+		t.status = err.Error()
+		return
+	}
+	t.save(fmt.Sprintf("Todo %d moved. Sorted by ord:all while reordering.", todo.Id))
+	t.selectTodo(todo)
+}
+
+//Select the todo, if it is listed
+func (t *TodoTui) selectTodo(todo *Todo) {
+	for i, listed := range t.todos {
+		if listed == todo {
+			t.selected = i
+			t.moveSelection(0)
+			return
+		}
+	}
+}
+
+func (t *TodoTui) save(status string) {
+	if err := t.app.Save(); err != nil {
+		status = err.Error()
+	}
+	t.status = status
+	t.refresh()
+}
+
+//Re-apply the report sort and filters to the pending todos
+func (t *TodoTui) refresh() {
+	pending := []*Todo{}
+	for _, todo := range t.app.TodoList.Todos() {
+		if todo.Status == "Pending" {
+			pending = append(pending, todo)
+		}
+	}
+	filters := append([]string{}, t.app.Cfg.Views[t.views[t.viewIdx]]...)
+	filters = append(filters, t.report.Filters...)
+	filters = append(filters, t.filters...)
+	t.report.Sorter.Sort(pending, t.app.Clock.Now())
+	todos, err := NewToDoFilter(pending, t.app.Clock).Filter(filters)
+	if err != nil {
+		t.status = err.Error()
+	}
+	t.todos = todos
+	t.moveSelection(0)
+}
+
+func (t *TodoTui) current() *Todo {
+	if t.selected < 0 || t.selected >= len(t.todos) {
+		return nil
+	}
+	return t.todos[t.selected]
+}
+
+func (t *TodoTui) moveSelection(delta int) {
+	t.selected += delta
+	if t.selected >= len(t.todos) {
+		t.selected = len(t.todos) - 1
+	}
+	if t.selected < 0 {
+		t.selected = 0
+	}
+	if t.selected < t.offset {
+		t.offset = t.selected
+	} else if t.selected >= t.offset+t.pageSize() {
+		t.offset = t.selected - t.pageSize() + 1
+	}
+}
+
+//Rows of todos that fit on screen, less the borders, header and status bar
+func (t *TodoTui) pageSize() int {
+	_, h := termbox.Size()
+	if h-6 < 1 {
+		return 1
+	}
+	return h - 6
+}
+
+func (t *TodoTui) render() {
+	termbox.Sync()
+	w, h := termbox.Size()
+	lines := t.reportLines()
+
+	list := ui.NewList()
+	list.Items = []string{"[" + escapeTui(lines[0]) + "](fg-green)"}
+	for i := t.offset; i < len(t.todos) && i < t.offset+t.pageSize(); i++ {
+		line := escapeTui(lines[i+1])
+		if i == t.selected {
+			line = "[" + line + "](fg-black,bg-cyan)"
+		}
+		list.Items = append(list.Items, line)
+	}
+	list.Width = w
+	list.Height = h - 3
+	list.BorderLabel = fmt.Sprintf("%s (%d todos)", t.report.Description, len(t.todos))
+	if view := t.views[t.viewIdx]; view != "" {
+		list.BorderLabel += " view:" + view
+	}
+	list.BorderLabelFg = ui.ColorYellow
+
+	status := ui.NewParagraph(tuiHelp)
+	if t.onInput != nil {
+		status = ui.NewParagraph(t.prompt + escapeTui(string(t.input)) + "_")
+	} else if t.status != "" {
+		status = ui.NewParagraph(t.status)
+	}
+	status.Width = w
+	status.Height = 3
+	status.Y = h - 3
+	ui.Clear()
+	ui.Render(list, status)
+}
+
+//Format the header and todos with the report columns, without colors, truncated to fit inside the borders
+func (t *TodoTui) reportLines() []string {
+	w, _ := termbox.Size()
+	plain := fmt.Sprint
+	p := &ScreenPrinter{fgGreen: plain, fgYellow: plain, fgRed: plain, fgWhite: plain, fgBlue: plain, fgMagenta: plain, fgCyan: plain, Clock: t.app.Clock}
+	layout := NewTableLayout(w-2, t.report.Widths, t.report.SubjectColumn(), false)
+	layout.AddRow(p.columnHeaderCells(t.report.Columns, t.report.Headers)...)
+	for _, todo := range t.todos {
+		layout.AddRow(p.customTodoCells(todo, t.report.Columns, t.app.TodoList.Data)...)
+	}
+	return strings.Split(layout.String(), "\n")
+}
+
+//Keep text from being read as termui color markup, e.g. [text](fg-red)
+func escapeTui(s string) string {
+	return strings.Replace(s, "](", "] (", -1)
+}
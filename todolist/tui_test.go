@@ -0,0 +1,223 @@
+package todolist
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//Tui over three pending todos, ordered 1, 2, 3 in set 'all' and listed by id. The terminal is never started.
+func newTestTui(filters ...string) (*TodoTui, *recordingStore) {
+	store := &recordingStore{
+		pending: []*Todo{
+			&Todo{Id: 1, Subject: "one", Status: "Pending", Ordinals: map[string]int{"all": 1}},
+			&Todo{Id: 2, Subject: "two", Status: "Pending", Ordinals: map[string]int{"all": 2}},
+			&Todo{Id: 3, Subject: "three", Status: "Pending", Ordinals: map[string]int{"all": 3}},
+		},
+		archived: []*Todo{},
+	}
+	app := newBatchApp(store, &bytes.Buffer{})
+	app.LoadPending()
+	report := &Report{Filters: []string{}, Columns: []string{"id", "subject"}, Headers: []string{"Id", "Subject"}, Sorter: NewTodoSorter("id")}
+	tui := NewTodoTui(app, report, filters)
+	tui.refresh()
+	return tui, store
+}
+
+func tuiIds(tui *TodoTui) []int {
+	ids := []int{}
+	for _, todo := range tui.todos {
+		ids = append(ids, todo.Id)
+	}
+	return ids
+}
+
+//Type the text into the prompt, then press enter
+func tuiType(tui *TodoTui, text string) {
+	for _, r := range text {
+		key := string(r)
+		if r == ' ' {
+			key = "<Space>"
+		}
+		tui.handleInput(key)
+	}
+	tui.handleInput("<Enter>")
+}
+
+func TestTuiMoveSelection(t *testing.T) {
+	assert := assert.New(t)
+	tui, _ := newTestTui()
+	assert.Equal([]int{1, 2, 3}, tuiIds(tui))
+
+	assert.True(tui.handleKey("j"))
+	assert.Equal(2, tui.current().Id)
+	tui.handleKey("<Down>")
+	tui.handleKey("j")
+	assert.Equal(3, tui.current().Id)
+	tui.handleKey("k")
+	assert.Equal(2, tui.current().Id)
+	tui.handleKey("<Up>")
+	tui.handleKey("<Up>")
+	assert.Equal(1, tui.current().Id)
+
+	assert.False(tui.handleKey("q"))
+	assert.False(tui.handleKey("<Escape>"))
+}
+
+func TestTuiComplete(t *testing.T) {
+	assert := assert.New(t)
+	tui, store := newTestTui("-completed")
+	tui.handleKey("j")
+
+	tui.handleKey("c")
+	assert.True(tui.app.TodoList.FindById(2).Completed)
+	assert.Equal(1, store.saves)
+	assert.Equal("Todo 2 completed.", tui.status)
+	//The list is filtered again, so the completed todo is gone and the next one selected
+	assert.Equal([]int{1, 3}, tuiIds(tui))
+	assert.Equal(3, tui.current().Id)
+
+	//Any other key clears the status
+	tui.handleKey("k")
+	assert.Equal("", tui.status)
+}
+
+func TestTuiArchive(t *testing.T) {
+	assert := assert.New(t)
+	tui, store := newTestTui()
+	archived := &Todo{Id: 4, Subject: "old", Status: "Archived"}
+	store.archived = []*Todo{archived}
+
+	tui.handleKey("a")
+	assert.Equal("Archived", tui.app.TodoList.FindById(1).Status)
+	assert.Equal("Todo 1 archived.", tui.status)
+	assert.Equal([]int{2, 3}, tuiIds(tui))
+	//The archived todos are loaded once, so they are saved along with the pending
+	assert.Contains(store.saved, archived)
+	tui.handleKey("a")
+	assert.Equal(4, len(tui.app.TodoList.Data))
+}
+
+func TestTuiEdit(t *testing.T) {
+	assert := assert.New(t)
+	tui, store := newTestTui()
+
+	//The prompt starts with the subject. Keys go to the prompt until enter.
+	tui.handleKey("e")
+	assert.NotNil(tui.onInput)
+	assert.Equal("one", string(tui.input))
+	tui.handleInput("<Backspace>")
+	tui.handleInput("<Backspace>")
+	tui.handleInput("<Backspace>")
+	tuiType(tui, "first +home")
+	assert.Nil(tui.onInput)
+	assert.Equal("first", tui.app.TodoList.FindById(1).Subject)
+	assert.Equal([]string{"home"}, tui.app.TodoList.FindById(1).Projects)
+	assert.Equal("Todo 1 edited.", tui.status)
+	assert.Equal(1, store.saves)
+
+	tui.handleKey("p")
+	tuiType(tui, "H")
+	assert.Equal("H", tui.app.TodoList.FindById(1).Priority)
+
+	tui.handleKey("d")
+	tuiType(tui, "2026-10-20")
+	assert.Equal("2026-10-20", tui.app.TodoList.FindById(1).Due[:10])
+
+	tui.handleKey("n")
+	tuiType(tui, "call back")
+	assert.Equal(1, len(tui.app.TodoList.FindById(1).Notes))
+	assert.Equal(4, store.saves)
+}
+
+func TestTuiCancelInput(t *testing.T) {
+	assert := assert.New(t)
+	tui, store := newTestTui()
+
+	tui.handleKey("p")
+	tui.handleInput("H")
+	tui.handleInput("<Escape>")
+	assert.Nil(tui.onInput)
+	assert.Equal("", tui.app.TodoList.FindById(1).Priority)
+	assert.Equal(0, store.saves)
+
+	//A bad date is shown in the status line and nothing is saved
+	tui.handleKey("d")
+	tuiType(tui, "someday")
+	assert.NotEqual("", tui.status)
+	assert.Equal("", tui.app.TodoList.FindById(1).Due)
+	assert.Equal(0, store.saves)
+}
+
+func TestTuiReorder(t *testing.T) {
+	assert := assert.New(t)
+	tui, store := newTestTui()
+	tui.report.Sorter = NewTodoSorter("-id")
+	tui.refresh()
+	assert.Equal([]int{3, 2, 1}, tuiIds(tui))
+	tui.handleKey("j")
+	tui.handleKey("j")
+
+	//The first move sorts by ord:all, keeping the todo selected, then moves it after its neighbour
+	tui.handleKey("J")
+	assert.True(tui.reordering)
+	assert.Equal([]int{2, 1, 3}, tuiIds(tui))
+	assert.Equal(1, tui.current().Id)
+	assert.Equal(1, store.saves)
+	assert.Equal("Todo 1 moved. Sorted by ord:all while reordering.", tui.status)
+
+	tui.handleKey("K")
+	assert.Equal([]int{1, 2, 3}, tuiIds(tui))
+	assert.Equal(1, tui.current().Id)
+
+	//Nothing to move before the first todo
+	tui.handleKey("K")
+	assert.Equal([]int{1, 2, 3}, tuiIds(tui))
+	assert.Equal(2, store.saves)
+}
+
+func TestTuiViews(t *testing.T) {
+	assert := assert.New(t)
+	store := &recordingStore{pending: []*Todo{
+		&Todo{Id: 1, Subject: "one +home", Projects: []string{"home"}, Status: "Pending"},
+		&Todo{Id: 2, Subject: "two +work", Projects: []string{"work"}, Status: "Pending"},
+	}}
+	app := newBatchApp(store, &bytes.Buffer{})
+	app.Cfg.Views = map[string][]string{"work": []string{"+work"}, "home": []string{"+home"}}
+	app.Cfg.CurrentView = "work"
+	app.LoadPending()
+	report := &Report{Filters: []string{}, Columns: []string{"id", "subject"}, Headers: []string{"Id", "Subject"}, Sorter: NewTodoSorter("id")}
+
+	//The view filters come ahead of the command line filters. They are kept apart so the view can change.
+	tui := NewTodoTui(app, report, []string{"+work", "-completed"})
+	assert.Equal([]string{"", "home", "work"}, tui.views)
+	assert.Equal([]string{"-completed"}, tui.filters)
+	tui.refresh()
+	assert.Equal([]int{2}, tuiIds(tui))
+
+	//v cycles through no view and the views by name
+	tui.handleKey("v")
+	assert.Equal([]int{1, 2}, tuiIds(tui))
+	tui.handleKey("v")
+	assert.Equal([]int{1}, tuiIds(tui))
+	tui.handleKey("v")
+	assert.Equal([]int{2}, tuiIds(tui))
+}
+
+func TestTuiNoTodos(t *testing.T) {
+	assert := assert.New(t)
+	store := &recordingStore{}
+	app := newBatchApp(store, &bytes.Buffer{})
+	app.LoadPending()
+	tui := NewTodoTui(app, &Report{Filters: []string{}, Sorter: NewTodoSorter("id")}, []string{})
+	tui.refresh()
+	assert.Empty(tui.todos)
+	assert.Nil(tui.current())
+
+	for _, key := range []string{"j", "k", "J", "K", "c", "a", "e", "d", "p", "n"} {
+		assert.True(tui.handleKey(key))
+	}
+	assert.Nil(tui.onInput)
+	assert.Equal(0, store.saves)
+}
@@ -0,0 +1,193 @@
+package todolist
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+//Default urgency coefficients. Modeled on TaskWarrior. Override in .todorc with
+//urgency.<term>.coefficient (e.g. urgency.due.coefficient=10). Add coefficients for specific
+//projects, contexts, tags and priorities with urgency.project.<name>.coefficient,
+//urgency.context.<name>.coefficient, urgency.tag.<name>.coefficient and urgency.priority.<value>.coefficient
+func DefaultUrgencyCoefficients() map[string]float64 {
+	return map[string]float64{
+		"due":         12.0,
+		"blocking":    8.0,
+		"priority":    6.0,
+		"active":      4.0,
+		"age":         2.0,
+		"annotations": 1.0,
+		"tags":        1.0,
+		"project":     1.0,
+		"waiting":     -3.0,
+		"blocked":     -5.0,
+		"age.max":     365, //Age in days at which the age term reaches its maximum
+		"ordinal":     3.0, //Weight of the manual order (ord:all) in the 'next' report
+	}
+}
+
+//Default order of the priority values, highest first. Override in .todorc with priority=<values> (e.g. priority=A,B,C)
+func DefaultPriority() map[string]int {
+	return map[string]int{"H": 1, "M": 2, "L": 3}
+}
+
+type UrgencyTerm struct {
+	Name        string
+	Value       float64
+	Coefficient float64
+}
+
+func (u *UrgencyTerm) Score() float64 {
+	return u.Value * u.Coefficient
+}
+
+//Calc urgency for all todos. Needs the full set of todos to determine blocked and blocking.
+func calcAllUrgency(todos []*Todo, now time.Time, priority map[string]int, coefficients map[string]float64) {
+	for _, t := range todos {
+		t.Urgency = calcUrgency(t, todos, now, priority, coefficients)
+	}
+}
+
+//Calc the score used by the 'next' report. Combines urgency with the manual order (ord:all),
+//so todos moved up the list with 'todo order' rise above todos of similar urgency.
+func calcAllNextScore(todos []*Todo, coefficients map[string]float64) {
+	ordered := []*Todo{}
+	for _, t := range todos {
+		t.NextScore = t.Urgency
+		//Ordinal 0 is the top of the manual order
+		if _, ok := t.Ordinals["all"]; ok && !t.Completed && t.Status == "Pending" {
+			ordered = append(ordered, t)
+		}
+	}
+	coefficient := coefficients["ordinal"]
+	if len(ordered) == 0 || coefficient == 0 {
+		return
+	}
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Ordinals["all"] < ordered[j].Ordinals["all"] })
+	for i, t := range ordered {
+		t.NextScore += coefficient * float64(len(ordered)-i) / float64(len(ordered))
+	}
+}
+
+func calcUrgency(t *Todo, todos []*Todo, now time.Time, priority map[string]int, coefficients map[string]float64) float64 {
+	urgency := 0.0
+	for _, term := range urgencyTerms(t, todos, now, priority, coefficients) {
+		urgency += term.Score()
+	}
+	return urgency
+}
+
+//Break the urgency of a todo into the terms that contribute to it. Terms with a zero value are omitted.
+func urgencyTerms(t *Todo, todos []*Todo, now time.Time, priority map[string]int, coefficients map[string]float64) []*UrgencyTerm {
+	terms := []*UrgencyTerm{}
+	add := func(name string, value float64) {
+		if c, ok := coefficients[name]; ok && value != 0 && c != 0 {
+			terms = append(terms, &UrgencyTerm{Name: name, Value: value, Coefficient: c})
+		}
+	}
+	if t.Completed || t.Status != "Pending" {
+		return terms
+	}
+
+	add("due", urgencyDue(t, now))
+	if _, ok := coefficients["priority."+t.Priority]; ok {
+		add("priority."+t.Priority, 1.0)
+	} else {
+		add("priority", urgencyPriority(t, priority))
+	}
+	add("age", urgencyAge(t, coefficients["age.max"], now))
+	add("annotations", urgencyCount(len(t.Notes)))
+	add("tags", urgencyCount(len(t.Tags)))
+	if len(t.Projects) > 0 {
+		add("project", 1.0)
+	}
+	if t.IsActive() {
+		add("active", 1.0)
+	}
+	if t.IsWaiting(now) {
+		add("waiting", 1.0)
+	}
+	if t.IsBlocked(todos) {
+		add("blocked", 1.0)
+	}
+	if t.IsBlocking(todos) {
+		add("blocking", 1.0)
+	}
+	for _, p := range t.Projects {
+		add("project."+p, 1.0)
+	}
+	for _, c := range t.Contexts {
+		add("context."+c, 1.0)
+	}
+	for _, tag := range t.Tags {
+		add("tag."+tag, 1.0)
+	}
+	return terms
+}
+
+//Due term ramps from 0.2 (due in two weeks or more) to 1.0 (a week or more overdue)
+func urgencyDue(t *Todo, now time.Time) float64 {
+	if t.Due == "" {
+		return 0
+	}
+	days := now.Sub(stringToTime(t.Due)).Hours() / 24
+	if days >= 7.0 {
+		return 1.0
+	} else if days >= -14.0 {
+		return ((days + 14.0) * 0.8 / 21.0) + 0.2
+	}
+	return 0.2
+}
+
+//Priority term is 1.0 for the highest configured priority, decreasing evenly for each lower priority.
+func urgencyPriority(t *Todo, priority map[string]int) float64 {
+	if _, ok := priority[t.Priority]; !ok {
+		return 0
+	}
+	values := []string{}
+	for k := range priority {
+		values = append(values, k)
+	}
+	sort.Slice(values, func(i, j int) bool { return priority[values[i]] < priority[values[j]] })
+	for i, p := range values {
+		if p == t.Priority {
+			return float64(len(values)-i) / float64(len(values))
+		}
+	}
+	return 0
+}
+
+func urgencyAge(t *Todo, max float64, now time.Time) float64 {
+	if t.CreatedDate == "" || max <= 0 {
+		return 0
+	}
+	created, err := time.Parse(time.RFC3339, t.CreatedDate)
+	if err != nil {
+		return 0
+	}
+	days := now.Sub(created).Hours() / 24
+	if days > max {
+		return 1.0
+	}
+	return days / max
+}
+
+//Count terms (notes, tags) give most of the value for the first item
+func urgencyCount(cnt int) float64 {
+	switch {
+	case cnt == 0:
+		return 0
+	case cnt == 1:
+		return 0.8
+	case cnt == 2:
+		return 0.9
+	}
+	return 1.0
+}
+
+//Parse a urgency config key (without the 'urgency.' prefix) to the name of the coefficient.
+//e.g. due.coefficient -> due, project.Work.coefficient -> project.Work, age.max -> age.max
+func urgencyConfigKey(key string) string {
+	return strings.TrimSuffix(key, ".coefficient")
+}
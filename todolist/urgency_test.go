@@ -0,0 +1,110 @@
+package todolist
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUrgency(t *testing.T) {
+	assert := assert.New(t)
+	clock := FixedClock{Time: time.Now()}
+	now := clock.Now()
+	priority, coefficients := DefaultPriority(), DefaultUrgencyCoefficients()
+
+	first := &Todo{Id: 1, Uuid: "a", Subject: "one", Status: "Pending", Due: timeToString(bod(now).AddDate(0, 0, -8))}
+	second := &Todo{Id: 2, Uuid: "b", Subject: "two", Status: "Pending", Depends: []string{"a"}}
+	todos := []*Todo{first, second}
+
+	assert.Equal(1.0, urgencyDue(first, now))
+	assert.Equal(0.0, urgencyCount(0))
+	assert.Equal(0.8, urgencyCount(1))
+
+	calcAllUrgency(todos, now, priority, coefficients)
+	assert.Equal(20.0, first.Urgency)
+	assert.Equal(-5.0, second.Urgency)
+
+	coefficients["blocked"] = 0
+	calcAllUrgency(todos, now, priority, coefficients)
+	assert.Equal(0.0, second.Urgency)
+
+	first.Priority = "B"
+	calcAllUrgency(todos, now, priority, coefficients)
+	assert.Equal(20.0, first.Urgency)
+	calcAllUrgency(todos, now, map[string]int{"A": 0, "B": 1}, coefficients)
+	assert.Equal(23.0, first.Urgency)
+
+	first.Complete(now)
+	calcAllUrgency(todos, now, priority, coefficients)
+	assert.Equal(0.0, first.Urgency)
+}
+
+func TestSorterRanking(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	low := &Todo{Id: 1, Subject: "low", Status: "Pending", Priority: "L"}
+	high := &Todo{Id: 2, Subject: "high", Status: "Pending", Priority: "H"}
+	todos := []*Todo{high, low}
+
+	//H, M, L unless the config orders the priorities otherwise
+	NewTodoSorter("priority").Sort(todos, now)
+	assert.Equal([]*Todo{high, low}, todos)
+	sorter := NewTodoSorter("priority").ranked(map[string]int{"L": 0, "H": 1}, nil)
+	sorter.Sort(todos, now)
+	assert.Equal([]*Todo{low, high}, todos)
+	assert.Equal(6.0, low.Urgency)
+
+	//The copy ranks by the config, the sorter it was made from by the defaults
+	sorter = NewTodoSorter("-urgency")
+	sorter.ranked(nil, map[string]float64{"priority": 0}).Sort(todos, now)
+	assert.Equal(0.0, high.Urgency)
+	sorter.Sort(todos, now)
+	assert.Equal(6.0, high.Urgency)
+}
+
+func TestNextReport(t *testing.T) {
+	assert := assert.New(t)
+	clock := FixedClock{Time: time.Now()}
+	now := clock.Now()
+
+	first := &Todo{Id: 1, Uuid: "a", Subject: "one", Status: "Pending", Projects: []string{"p"}, Ordinals: map[string]int{"all": 2}}
+	second := &Todo{Id: 2, Uuid: "b", Subject: "two", Status: "Pending", Projects: []string{"p"}, Ordinals: map[string]int{"all": 1}}
+	third := &Todo{Id: 3, Uuid: "c", Subject: "three", Status: "Pending", Depends: []string{"b"}, Ordinals: map[string]int{"all": 3}}
+	fourth := &Todo{Id: 4, Uuid: "d", Subject: "four", Status: "Pending", Ordinals: map[string]int{"all": 4}}
+	todos := []*Todo{first, second, third, fourth}
+
+	report := NewNextReport(120, nil)
+	report.Sorter.Sort(todos, now)
+	assert.Equal(2, todos[0].Id)
+	assert.True(todos[0].NextScore > todos[1].NextScore)
+
+	//The blocked todo is dropped. top:pro:3:none keeps the one with no project.
+	filtered, _ := NewToDoFilter(todos, clock).Filter(report.Filters)
+	assert.Equal(3, len(filtered))
+	for _, todo := range filtered {
+		assert.NotEqual(3, todo.Id)
+	}
+	//Without :none, todos with no project are dropped, as they always have been
+	filtered, _ = NewToDoFilter(todos, clock).Filter([]string{"top:pro:3"})
+	assert.Equal(2, len(filtered))
+
+	//The todo at the top of the manual order (ordinal 0) ranks highest of todos equally urgent
+	top := &Todo{Id: 5, Subject: "top", Status: "Pending", Ordinals: map[string]int{"all": 0}}
+	middle := &Todo{Id: 6, Subject: "middle", Status: "Pending", Ordinals: map[string]int{"all": 1}}
+	bottom := &Todo{Id: 7, Subject: "bottom", Status: "Pending", Ordinals: map[string]int{"all": 2}}
+	ordered := []*Todo{bottom, middle, top}
+	report.Sorter.Sort(ordered, now)
+	assert.Equal([]*Todo{top, middle, bottom}, ordered)
+	assert.True(top.NextScore > middle.NextScore)
+
+	assert.Equal([]string{"id", "project", "subject"}, NewNextReport(60, nil).Columns)
+
+	//Settings in .todorc replace the built-in ones, keeping the rest
+	report = NewNextReport(120, map[string]string{"filter": "-completed,top:pro:1"})
+	assert.Equal([]string{"-completed", "top:pro:1"}, report.Filters)
+	assert.Equal("id", report.Columns[0])
+	assert.Equal(len(report.Columns), len(report.Headers))
+	report = NewNextReport(120, map[string]string{"columns": "id,subject"})
+	assert.Equal([]string{"id", "subject"}, report.Headers)
+}
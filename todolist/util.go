@@ -53,6 +53,10 @@ func bom(t time.Time) time.Time {
 	}
 }
 
+func boy(t time.Time) time.Time {
+	return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, t.Location())
+}
+
 func bow(t time.Time) time.Time {
 	for {
 		if t.Weekday() != time.Sunday {
@@ -156,33 +160,47 @@ func thisOrLastWeek(day time.Time, pivotDay time.Time) time.Time {
 }
 
 func pluralize(count int, singular, plural string) string {
-	if count > 1 {
-		return plural
+	if count == 1 {
+		return singular
+	}
+	return plural
+}
+
+//Whole days from the date (RFC3339) to now. 0 if the date is blank or invalid.
+func daysSince(date string, now time.Time) int {
+	days := 0
+	if len(date) > 0 {
+		tmpTime, err := time.Parse(time.RFC3339, date)
+		if err == nil {
+			diff := now.Unix() - tmpTime.Unix()
+			days = (int)(diff / (60 * 60 * 24))
+		}
 	}
-	return singular
+	return days
 }
 
-func isToday(t time.Time) bool {
-	nowYear, nowMonth, nowDay := Now.Date()
+func isToday(t time.Time, now time.Time) bool {
+	nowYear, nowMonth, nowDay := now.Date()
 	timeYear, timeMonth, timeDay := t.Date()
 	return nowYear == timeYear &&
 		nowMonth == timeMonth &&
 		nowDay == timeDay
 }
 
-func isTomorrow(t time.Time) bool {
-	nowYear, nowMonth, nowDay := Now.AddDate(0, 0, 1).Date()
+func isTomorrow(t time.Time, now time.Time) bool {
+	nowYear, nowMonth, nowDay := now.AddDate(0, 0, 1).Date()
 	timeYear, timeMonth, timeDay := t.Date()
 	return nowYear == timeYear &&
 		nowMonth == timeMonth &&
 		nowDay == timeDay
 }
 
-func isPastDue(t time.Time) bool {
-	return Now.After(t)
+func isPastDue(t time.Time, now time.Time) bool {
+	return now.After(t)
 }
 
-func translateToDates(t time.Time, vals ...string) []time.Time {
+//Dates (or the beginning and end of a period, like this_week) relative to t. Returns ErrBadDate if a value is not a date.
+func translateToDates(t time.Time, vals ...string) ([]time.Time, error) {
 	times := []time.Time{}
 	p := Parser{}
 	for i, val := range vals {
@@ -216,14 +234,35 @@ func translateToDates(t time.Time, vals ...string) []time.Time {
 			end := begin.AddDate(0, 0, 7)
 			times = append(times, begin, end)
 			break
+		case strings.HasPrefix(val, "this_month"):
+			begin := bom(t)
+			times = append(times, begin, begin.AddDate(0, 1, 0))
+		case strings.HasPrefix(val, "next_month"):
+			begin := bom(t).AddDate(0, 1, 0)
+			times = append(times, begin, begin.AddDate(0, 1, 0))
+		case strings.HasPrefix(val, "last_month"):
+			begin := bom(t).AddDate(0, -1, 0)
+			times = append(times, begin, begin.AddDate(0, 1, 0))
+		case strings.HasPrefix(val, "this_year"):
+			begin := boy(t)
+			times = append(times, begin, begin.AddDate(1, 0, 0))
+		case strings.HasPrefix(val, "next_year"):
+			begin := boy(t).AddDate(1, 0, 0)
+			times = append(times, begin, begin.AddDate(1, 0, 0))
+		case strings.HasPrefix(val, "last_year"):
+			begin := boy(t).AddDate(-1, 0, 0)
+			times = append(times, begin, begin.AddDate(1, 0, 0))
 		default:
 			//If not blank or one of the range terms, parse for day of week or relative references
-			t2 := p.ParseDateTime(val, t)
+			t2, err := p.ParseDateTime(val, t)
+			if err != nil {
+				return nil, err
+			}
 			times = append(times, t2)
 		}
 
 	}
-	return times
+	return times, nil
 }
 
 func inSliceOneNotSliceTwo(s1, s2 []string) []string {
@@ -241,7 +280,7 @@ func inSliceOneNotSliceTwo(s1, s2 []string) []string {
 	return res
 }
 
-func getModifiedTime(todo *Todo) time.Time {
+func getModifiedTime(todo *Todo, now time.Time) time.Time {
 	if len(todo.ModifiedDate) > 0 {
 		modTime, rerr := time.Parse(time.RFC3339, todo.ModifiedDate)
 		if rerr != nil {
@@ -250,7 +289,7 @@ func getModifiedTime(todo *Todo) time.Time {
 		}
 		return modTime
 	}
-	return Now
+	return now
 }
 
 func stringToTime(val string) time.Time {
@@ -268,6 +307,18 @@ func timeToString(val time.Time) string {
 	return formatted
 }
 
+//Format time spent as hours and minutes, e.g. 2h05m or 45m
+func durationToString(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	minutes := int(d.Minutes())
+	if minutes < 60 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh%02dm", minutes/60, minutes%60)
+}
+
 func timeToSimpleDateString(val time.Time) string {
 	return val.Format("2006-01-02")
 }
@@ -288,9 +339,7 @@ func newUUID() (string, error) {
 
 //Calc exec order for all todos
 //Normalize values to between 0 and 1.
-func calcAllExecOrder(todos []*Todo) {
-	//Get priority values
-	priorityMap := Priority
+func calcAllExecOrder(todos []*Todo, now time.Time, priorityMap map[string]int) {
 	numPs := len(priorityMap)
 	//Enforce maximum spacing of 10 if too few priority levels.
 	//Too few levels will yield low priority values that never rise to top
@@ -305,11 +354,11 @@ func calcAllExecOrder(todos []*Todo) {
 			p = numPs //sort unknown priority values to last
 		}
 		p = 100 - (p * spacing) //assumes num of priorities is less than 10.
-		calcExecOrder(t, (float64(p) / 100.0))
+		calcExecOrder(t, (float64(p) / 100.0), now)
 	}
 }
 
-func calcExecOrder(t *Todo, p float64) {
+func calcExecOrder(t *Todo, p float64, now time.Time) {
 	//ExecOrder calculated only if needed. Needed in multiple places, so check
 	//if already calculated.
 	if t.ExecOrder == 0 {
@@ -319,8 +368,7 @@ func calcExecOrder(t *Todo, p float64) {
 			tmpTime, err := time.Parse(time.RFC3339, t.Due)
 			if err == nil {
 				dueTime := tmpTime.Unix()
-				now := Now.Unix()
-				diff := now - dueTime
+				diff := now.Unix() - dueTime
 				d = int(math.Abs(float64(diff) / (60 * 60 * 24)))
 				if d < 1 {
 					d = 1
@@ -1,7 +1,9 @@
 package todolist
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -10,4 +12,20 @@ func TestPluralize(t *testing.T) {
 	assert := assert.New(t)
 	assert.Equal("todo", pluralize(1, "todo", "todos"))
 	assert.Equal("todos", pluralize(2, "todo", "todos"))
+	assert.Equal("todos", pluralize(0, "todo", "todos"))
+}
+
+func TestTranslateToDatesBadDate(t *testing.T) {
+	assert := assert.New(t)
+	_, err := translateToDates(time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC), "someday")
+	assert.True(errors.Is(err, ErrBadDate))
+}
+
+//translateToDates, for dates known to parse
+func mustTranslateToDates(now time.Time, vals ...string) []time.Time {
+	dates, err := translateToDates(now, vals...)
+	if err != nil {
+		panic(err)
+	}
+	return dates
 }
@@ -3,7 +3,6 @@ package todolist
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 
 	"github.com/julienschmidt/httprouter"
@@ -22,8 +21,8 @@ func NewWebapp() *Webapp {
 	return &Webapp{Router: setupRoutes()}
 }
 
-func (w *Webapp) Run() {
-	log.Fatal(http.ListenAndServe(":7890", w.Router))
+func (w *Webapp) Run() error {
+	return http.ListenAndServe(":7890", w.Router)
 }
 
 func setupRoutes() *httprouter.Router {
@@ -88,8 +87,14 @@ func RedirectToIndex(w http.ResponseWriter, r *http.Request) {
 
 func GetTodos(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 	w.Header().Set("Access-Control-Allow-Origin", "*")
-	app := NewApp()
-	app.LoadPending()
+	app, err := NewApp()
+	if err == nil {
+		err = app.LoadPending()
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 	json, _ := json.Marshal(app.TodoList.Data)
 	fmt.Fprintf(w, string(json))
 }
@@ -106,9 +111,17 @@ func SaveTodos(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 	var todos []*Todo
 	err := decoder.Decode(&todos)
 	if err != nil {
-		log.Fatal("encountered an error parsing json, ", err)
+		http.Error(w, "encountered an error parsing json, "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	app, err := NewApp()
+	if err == nil {
+		_, err = app.TodoStore.LoadPending()
+	}
+	if err == nil {
+		err = app.TodoStore.Save(todos)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
-	app := NewApp()
-	app.TodoStore.LoadPending()
-	app.TodoStore.Save(todos)
 }